@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gocache/internal/core/command"
+	"gocache/internal/core/resp"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// / Validates an AOF and optionally repairs it by truncating everything from the first bad entry on.
+// / Usage: gocache-check-aof [-fix] <file>
+func main() {
+	fix := flag.Bool("fix", false, "truncate the AOF to the last valid entry")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: gocache-check-aof [-fix] <file>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	os.Exit(run(flag.Arg(0), *fix, os.Stdout))
+}
+
+func run(path string, fix bool, out io.Writer) int {
+	// checking only needs to read, so read only or foreign AOFs can be checked as well
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+
+	result := check(file, info.Size())
+	result.print(out)
+
+	if result.problem == nil {
+		return 0
+	}
+	if !fix {
+		fmt.Fprintln(out, "Run with -fix to truncate the AOF to the last valid entry")
+		return 1
+	}
+
+	writable, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return 1
+	}
+	defer writable.Close()
+
+	if err := writable.Truncate(result.validBytes); err != nil {
+		fmt.Fprintln(out, "Truncating failed: "+err.Error())
+		return 1
+	}
+	if err := writable.Sync(); err != nil {
+		fmt.Fprintln(out, "Syncing failed: "+err.Error())
+		return 1
+	}
+
+	fmt.Fprintf(out, "Truncated the AOF from %d to %d bytes, %d bytes were dropped\n", info.Size(), result.validBytes, info.Size()-result.validBytes)
+	return 0
+}
+
+type report struct {
+	// entries and bytes before the first bad entry
+	validEntries int
+	validBytes   int64
+	size         int64
+	counts       map[string]int
+
+	// nil if the whole AOF is valid
+	problem error
+}
+
+// / Reads entries until the first bad one. Entries are counted with the bytes they took in the AOF.
+// / A MULTI/EXEC block only becomes valid with its EXEC, so a bad entry inside a block makes the whole block bad
+func check(input io.Reader, size int64) report {
+	result := report{size: size, counts: map[string]int{}}
+	reader := resp.NewReader(input)
+
+	// entries of the block that is currently read, nil outside of a block
+	var block *report
+	position := int64(0)
+	fail := func(problem error) report {
+		if block != nil {
+			problem = fmt.Errorf("the transaction starting here is invalid: %w", problem)
+		}
+		result.problem = problem
+		return result
+	}
+
+	for {
+		value, err := reader.Read()
+		if err == io.EOF {
+			if block != nil {
+				return fail(errors.New("it has no EXEC, the AOF was probably cut off by a crash"))
+			}
+			return result
+		}
+
+		if err == nil && reader.Consumed() > size {
+			err = io.ErrUnexpectedEOF
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fail(errors.New("the entry is incomplete, the AOF was probably cut off by a crash"))
+		}
+		if err != nil {
+			return fail(errors.New("the entry can not be parsed: " + err.Error()))
+		}
+
+		name, err := validate(value)
+		if err == nil && name == command.MULTI && block != nil {
+			err = errors.New("MULTI is nested in another MULTI")
+		}
+		if err == nil && name == command.EXEC && block == nil {
+			err = errors.New("EXEC without MULTI")
+		}
+		if err != nil {
+			return fail(err)
+		}
+
+		if name == command.MULTI {
+			block = &report{counts: map[string]int{}}
+		}
+
+		current := &result
+		if block != nil {
+			current = block
+		}
+		current.counts[name]++
+		current.validEntries++
+		current.validBytes += reader.Consumed() - position
+		position = reader.Consumed()
+
+		if name == command.EXEC {
+			for blockName, count := range block.counts {
+				result.counts[blockName] += count
+			}
+			result.validEntries += block.validEntries
+			result.validBytes += block.validBytes
+			block = nil
+		}
+	}
+}
+
+// / Checks that the entry is a known command with a valid amount of arguments and returns its name
+func validate(value resp.Value) (string, error) {
+	if value.Typ != resp.ARRAY.Typ || len(value.Array) == 0 {
+		return "", errors.New("the entry is not a command array")
+	}
+	for _, arg := range value.Array {
+		if arg.Typ != resp.BULK.Typ {
+			return "", errors.New("the entry contains a " + arg.Typ.String() + " instead of only bulk strings")
+		}
+	}
+
+	name := strings.ToUpper(string(value.Array[0].Bulk))
+	if _, ok := command.Strategies[name]; !ok && name != command.MULTI && name != command.EXEC {
+		return "", errors.New("unknown command " + name)
+	}
+
+	if arity, ok := command.Arity(name); ok && !command.MatchesArity(arity, len(value.Array)) {
+		return "", fmt.Errorf("wrong number of arguments for %s, got %d but the arity is %d", name, len(value.Array), arity)
+	}
+
+	return name, nil
+}
+
+func (r report) print(out io.Writer) {
+	names := make([]string, 0, len(r.counts))
+	for name := range r.counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.counts[names[i]] != r.counts[names[j]] {
+			return r.counts[names[i]] > r.counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Fprintf(out, "%d valid entries in %d of %d bytes\n", r.validEntries, r.validBytes, r.size)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-16s %d\n", name, r.counts[name])
+	}
+
+	if r.problem != nil {
+		fmt.Fprintf(out, "Bad entry at command index %d, byte offset %d: %v\n", r.validEntries, r.validBytes, r.problem)
+		return
+	}
+	fmt.Fprintln(out, "AOF is valid")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const setCommand = "*3\r\n$3\r\nSET\r\n$4\r\nTira\r\n$4\r\nMisu\r\n"
+const rpushCommand = "*4\r\n$5\r\nRPUSH\r\n$4\r\nCute\r\n$4\r\nVoid\r\n$5\r\nScary\r\n"
+
+func Test_check_validAof(t *testing.T) {
+	// given
+	aof := setCommand + rpushCommand + setCommand
+
+	// when
+	result := check(strings.NewReader(aof), int64(len(aof)))
+
+	// then
+	assert.NoError(t, result.problem)
+	assert.Equal(t, 3, result.validEntries)
+	assert.Equal(t, int64(len(aof)), result.validBytes)
+	assert.Equal(t, map[string]int{"SET": 2, "RPUSH": 1}, result.counts)
+}
+
+func Test_check_findsFirstBadEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		bad     string
+		problem string
+	}{
+		{name: "truncated", bad: "*3\r\n$3\r\nSET\r\n$4\r\nTi", problem: "incomplete"},
+		{name: "unknown command", bad: "*2\r\n$4\r\nMISU\r\n$4\r\nTira\r\n", problem: "unknown command MISU"},
+		{name: "wrong arity", bad: "*2\r\n$3\r\nSET\r\n$4\r\nTira\r\n", problem: "wrong number of arguments for SET"},
+		{name: "not parsable", bad: "garbage\r\n", problem: "can not be parsed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			aof := setCommand + rpushCommand + test.bad
+
+			// when
+			result := check(strings.NewReader(aof), int64(len(aof)))
+
+			// then
+			assert.ErrorContains(t, result.problem, test.problem)
+			assert.Equal(t, 2, result.validEntries)
+			assert.Equal(t, int64(len(setCommand+rpushCommand)), result.validBytes)
+		})
+	}
+}
+
+func Test_check_transactions(t *testing.T) {
+	multi := "*1\r\n$5\r\nMULTI\r\n"
+	exec := "*1\r\n$4\r\nEXEC\r\n"
+
+	tests := []struct {
+		name    string
+		aof     string
+		entries int
+		problem string
+	}{
+		{name: "complete", aof: setCommand + multi + setCommand + exec, entries: 4},
+		{name: "without exec", aof: setCommand + multi + setCommand, entries: 1, problem: "has no EXEC"},
+		{name: "bad entry inside", aof: setCommand + multi + setCommand + "garbage\r\n" + exec, entries: 1, problem: "transaction starting here"},
+		{name: "nested", aof: multi + multi, entries: 0, problem: "nested"},
+		{name: "exec without multi", aof: setCommand + exec, entries: 1, problem: "EXEC without MULTI"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			result := check(strings.NewReader(test.aof), int64(len(test.aof)))
+
+			// then
+			if test.problem == "" {
+				assert.NoError(t, result.problem)
+			} else {
+				assert.ErrorContains(t, result.problem, test.problem)
+			}
+			assert.Equal(t, test.entries, result.validEntries)
+		})
+	}
+}
+
+func Test_run_fixTruncatesToLastValidEntry(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "database.aof")
+	os.WriteFile(path, []byte(setCommand+"*3\r\n$3\r\nSE"), 0666)
+	out := bytes.Buffer{}
+
+	// when
+	withoutFix := run(path, false, &out)
+	withFix := run(path, true, &out)
+
+	// then
+	assert.Equal(t, 1, withoutFix)
+	assert.Equal(t, 0, withFix)
+	assert.Contains(t, out.String(), "Bad entry at command index 1, byte offset 33")
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, setCommand, string(content))
+	assert.Equal(t, 0, run(path, false, &out))
+}
+
+func Test_run_checksReadOnlyAof(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "database.aof")
+	os.WriteFile(path, []byte(setCommand), 0444)
+	out := bytes.Buffer{}
+
+	// when
+	result := run(path, false, &out)
+
+	// then
+	assert.Equal(t, 0, result)
+	assert.NotContains(t, out.String(), "permission denied")
+}
+
+func Test_run_fixKeepsEntriesWithLeadingZeroLengths(t *testing.T) {
+	// given
+	entry := "*3\r\n$03\r\nSET\r\n$004\r\nTira\r\n$4\r\nMisu\r\n"
+	path := filepath.Join(t.TempDir(), "database.aof")
+	os.WriteFile(path, []byte(entry+"*3\r\n$3\r\nSE"), 0666)
+
+	// when
+	result := run(path, true, &bytes.Buffer{})
+
+	// then
+	assert.Equal(t, 0, result)
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, entry, string(content))
+}
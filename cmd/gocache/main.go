@@ -1,12 +1,16 @@
 package main
 
 import (
+	"errors"
+	"gocache/internal/core/pubsub"
+	"gocache/internal/core/resp"
 	"gocache/internal/core/startup"
 	"gocache/internal/infrastructure"
 	"gocache/internal/persistence"
 	"log"
 	"net"
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -31,9 +35,26 @@ func main() {
 		log.Println(err)
 		os.Exit(1)
 	}
+
+	limits, err := protocolLimits()
+	if err != nil {
+		log.Println(err)
+		database.Close()
+		os.Exit(1)
+	}
+
+	broker := pubsub.NewBroker()
+	if err := enableKeyspaceNotifications(database, broker); err != nil {
+		log.Println(err)
+		database.Close()
+		os.Exit(1)
+	}
 	defer database.Close()
 
-	go infrastructure.ExpirationJob(time.Second, database)
+	// like redis, the active expiration runs 10 times per second
+	go infrastructure.ExpirationJob(100*time.Millisecond, database)
+	go infrastructure.AofRewriteJob(time.Second, database)
+	go infrastructure.SnapshotJob(time.Second, database)
 
 	if ready != nil {
 		close(ready)
@@ -49,7 +70,7 @@ func main() {
 
 		go func() {
 			defer connection.Close()
-			err := infrastructure.HandleConnection(connection, database)
+			err := infrastructure.HandleConnection(connection, database, broker, limits)
 			if err != nil {
 				log.Println(err)
 			}
@@ -63,14 +84,114 @@ func initializeDatabase() (persistence.Database, error) {
 		databasePath = "database.aof"
 	}
 
-	aof, err := persistence.NewAof(databasePath)
+	aofOptions, err := aofOptions()
 	if err != nil {
 		return nil, err
 	}
 
-	database := persistence.NewDatabase(nil)
+	aof, err := persistence.NewAof(databasePath, aofOptions)
+	if err != nil {
+		return nil, err
+	}
 
-	startup.ReplayCommands(aof, database)
+	snapshotPath, ok := os.LookupEnv("GC_SNAPSHOT_PATH")
+	if !ok {
+		snapshotPath = "database.snapshot"
+	}
+
+	saveRules := persistence.DefaultSaveRules()
+	if rawRules, ok := os.LookupEnv("GC_SAVE"); ok {
+		saveRules, err = persistence.ParseSaveRules(rawRules)
+		if err != nil {
+			aof.Close()
+			return nil, errors.New("GC_SAVE " + err.Error())
+		}
+	}
+
+	snapshot := persistence.NewSnapshot(snapshotPath, saveRules)
+	database := persistence.NewDatabase()
+
+	if err := startup.LoadPersistence(snapshot, aof, database); err != nil {
+		aof.Close()
+		return nil, err
+	}
 
 	return database, nil
 }
+
+// / Like notify-keyspace-events in redis, e.g. GC_NOTIFY_KEYSPACE_EVENTS=Ex publishes expired keys. Disabled by default
+func enableKeyspaceNotifications(database persistence.Database, broker *pubsub.Broker) error {
+	flags, ok := os.LookupEnv("GC_NOTIFY_KEYSPACE_EVENTS")
+	if !ok {
+		return nil
+	}
+
+	events, err := persistence.ParseKeyspaceEvents(flags)
+	if err != nil {
+		return errors.New("GC_NOTIFY_KEYSPACE_EVENTS: " + err.Error())
+	}
+
+	database.EnableKeyspaceNotifications(broker, events)
+	return nil
+}
+
+// / Like proto-max-bulk-len in redis, GC_PROTO_MAX_BULK_LEN limits the bytes of a bulk and GC_PROTO_MAX_MULTIBULK_LEN the elements of an array a client may send
+func protocolLimits() (resp.Limits, error) {
+	limits := resp.DefaultLimits()
+
+	if bulkLength, ok := os.LookupEnv("GC_PROTO_MAX_BULK_LEN"); ok {
+		parsed, err := strconv.Atoi(bulkLength)
+		if err != nil || parsed < 0 {
+			return limits, errors.New("GC_PROTO_MAX_BULK_LEN needs to be a number of bytes")
+		}
+		limits.MaxBulkLength = parsed
+	}
+
+	if arrayLength, ok := os.LookupEnv("GC_PROTO_MAX_MULTIBULK_LEN"); ok {
+		parsed, err := strconv.Atoi(arrayLength)
+		if err != nil || parsed < 0 {
+			return limits, errors.New("GC_PROTO_MAX_MULTIBULK_LEN needs to be a number of elements")
+		}
+		limits.MaxArrayLength = parsed
+	}
+
+	return limits, nil
+}
+
+func aofOptions() (persistence.AofOptions, error) {
+	options := persistence.DefaultAofOptions()
+
+	if percentage, ok := os.LookupEnv("GC_AUTO_AOF_REWRITE_PERCENTAGE"); ok {
+		parsed, err := strconv.Atoi(percentage)
+		if err != nil {
+			return options, errors.New("GC_AUTO_AOF_REWRITE_PERCENTAGE needs to be a number")
+		}
+		options.AutoRewritePercentage = parsed
+	}
+
+	if minSize, ok := os.LookupEnv("GC_AUTO_AOF_REWRITE_MIN_SIZE"); ok {
+		parsed, err := strconv.ParseInt(minSize, 10, 64)
+		if err != nil {
+			return options, errors.New("GC_AUTO_AOF_REWRITE_MIN_SIZE needs to be a number of bytes")
+		}
+		options.AutoRewriteMinSize = parsed
+	}
+
+	if policy, ok := os.LookupEnv("GC_APPENDFSYNC"); ok {
+		parsed, err := persistence.ParseFsyncPolicy(policy)
+		if err != nil {
+			return options, errors.New("GC_APPENDFSYNC: " + err.Error())
+		}
+		options.FsyncPolicy = parsed
+	}
+
+	if loadTruncated, ok := os.LookupEnv("GC_AOF_LOAD_TRUNCATED"); ok {
+		parsed, err := strconv.ParseBool(loadTruncated)
+		if err != nil {
+			return options, errors.New("GC_AOF_LOAD_TRUNCATED needs to be true or false")
+		}
+		options.LoadTruncated = parsed
+	}
+
+	return options, nil
+}
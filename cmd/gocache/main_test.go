@@ -140,15 +140,15 @@ func Test_getWithInitialization(t *testing.T) {
 		Array: []resp.Value{
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "SET",
+				Bulk: []byte("SET"),
 			},
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "Tira",
+				Bulk: []byte("Tira"),
 			},
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "Misu",
+				Bulk: []byte("Misu"),
 			},
 		},
 	}
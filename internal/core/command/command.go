@@ -1,33 +1,162 @@
 package command
 
 import (
+	"errors"
 	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
 )
 
 const (
-	PING    = "PING"
-	DEL     = "DEL"
-	SET     = "SET"
-	GET     = "GET"
-	INCR    = "INCR"
-	HSET    = "HSET"
-	HGET    = "HGET"
-	HDEL    = "HDEL"
-	HGETALL = "HGETALL"
-	COMMAND = "COMMAND"
+	PING          = "PING"
+	DEL           = "DEL"
+	SET           = "SET"
+	GET           = "GET"
+	INCR          = "INCR"
+	INCRBY        = "INCRBY"
+	DECR          = "DECR"
+	DECRBY        = "DECRBY"
+	INCRBYFLOAT   = "INCRBYFLOAT"
+	HSET          = "HSET"
+	HGET          = "HGET"
+	HDEL          = "HDEL"
+	HGETALL       = "HGETALL"
+	LPUSH         = "LPUSH"
+	RPUSH         = "RPUSH"
+	LPOP          = "LPOP"
+	RPOP          = "RPOP"
+	LRANGE        = "LRANGE"
+	LLEN          = "LLEN"
+	LINDEX        = "LINDEX"
+	LSET          = "LSET"
+	LREM          = "LREM"
+	LTRIM         = "LTRIM"
+	LMOVE         = "LMOVE"
+	BLPOP         = "BLPOP"
+	BRPOP         = "BRPOP"
+	BLMOVE        = "BLMOVE"
+	SADD          = "SADD"
+	SREM          = "SREM"
+	SMEMBERS      = "SMEMBERS"
+	SISMEMBER     = "SISMEMBER"
+	SMISMEMBER    = "SMISMEMBER"
+	SCARD         = "SCARD"
+	SPOP          = "SPOP"
+	SRANDMEMBER   = "SRANDMEMBER"
+	SINTER        = "SINTER"
+	SUNION        = "SUNION"
+	SDIFF         = "SDIFF"
+	SINTERSTORE   = "SINTERSTORE"
+	SUNIONSTORE   = "SUNIONSTORE"
+	SDIFFSTORE    = "SDIFFSTORE"
+	ZADD          = "ZADD"
+	ZINCRBY       = "ZINCRBY"
+	ZREM          = "ZREM"
+	ZCARD         = "ZCARD"
+	ZSCORE        = "ZSCORE"
+	ZCOUNT        = "ZCOUNT"
+	ZRANK         = "ZRANK"
+	ZREVRANK      = "ZREVRANK"
+	ZPOPMIN       = "ZPOPMIN"
+	ZPOPMAX       = "ZPOPMAX"
+	ZRANGE        = "ZRANGE"
+	ZRANGEBYSCORE = "ZRANGEBYSCORE"
+	EXISTS        = "EXISTS"
+	TYPE          = "TYPE"
+	EXPIRE        = "EXPIRE"
+	PEXPIRE       = "PEXPIRE"
+	EXPIREAT      = "EXPIREAT"
+	PEXPIREAT     = "PEXPIREAT"
+	TTL           = "TTL"
+	PTTL          = "PTTL"
+	EXPIRETIME    = "EXPIRETIME"
+	PEXPIRETIME   = "PEXPIRETIME"
+	PERSIST       = "PERSIST"
+	BGREWRITEAOF  = "BGREWRITEAOF"
+	SAVE          = "SAVE"
+	BGSAVE        = "BGSAVE"
+	LASTSAVE      = "LASTSAVE"
+	MULTI         = "MULTI"
+	EXEC          = "EXEC"
+	DISCARD       = "DISCARD"
+	WATCH         = "WATCH"
+	UNWATCH       = "UNWATCH"
+	SUBSCRIBE     = "SUBSCRIBE"
+	UNSUBSCRIBE   = "UNSUBSCRIBE"
+	PSUBSCRIBE    = "PSUBSCRIBE"
+	PUNSUBSCRIBE  = "PUNSUBSCRIBE"
+	PUBLISH       = "PUBLISH"
+	PUBSUB        = "PUBSUB"
+	HELLO         = "HELLO"
+	COMMAND       = "COMMAND"
 )
 
 var Strategies = map[string]CommandStrategy{
-	PING:    pingStrategy,
-	SET:     setStrategy,
-	GET:     getStrategy,
-	DEL:     delStrategy,
-	INCR:    incrStrategy,
-	HSET:    hsetStrategy,
-	HGET:    hgetStrategy,
-	HDEL:    hdelStrategy,
-	HGETALL: hgetAllStrategy,
-	COMMAND: commandMetadataStrategy,
+	PING:          pingStrategy,
+	SET:           setStrategy,
+	GET:           getStrategy,
+	DEL:           delStrategy,
+	INCR:          incrStrategy,
+	INCRBY:        incrbyStrategy,
+	DECR:          decrStrategy,
+	DECRBY:        decrbyStrategy,
+	INCRBYFLOAT:   incrbyfloatStrategy,
+	HSET:          hsetStrategy,
+	HGET:          hgetStrategy,
+	HDEL:          hdelStrategy,
+	HGETALL:       hgetAllStrategy,
+	LPUSH:         lpushStrategy,
+	RPUSH:         rpushStrategy,
+	LPOP:          lpopStrategy,
+	RPOP:          rpopStrategy,
+	LRANGE:        lrangeStrategy,
+	LLEN:          llenStrategy,
+	LINDEX:        lindexStrategy,
+	LSET:          lsetStrategy,
+	LREM:          lremStrategy,
+	LTRIM:         ltrimStrategy,
+	LMOVE:         lmoveStrategy,
+	SADD:          saddStrategy,
+	SREM:          sremStrategy,
+	SMEMBERS:      smembersStrategy,
+	SISMEMBER:     sismemberStrategy,
+	SMISMEMBER:    smismemberStrategy,
+	SCARD:         scardStrategy,
+	SPOP:          spopStrategy,
+	SRANDMEMBER:   srandmemberStrategy,
+	SINTER:        sinterStrategy,
+	SUNION:        sunionStrategy,
+	SDIFF:         sdiffStrategy,
+	SINTERSTORE:   sinterstoreStrategy,
+	SUNIONSTORE:   sunionstoreStrategy,
+	SDIFFSTORE:    sdiffstoreStrategy,
+	ZADD:          zaddStrategy,
+	ZINCRBY:       zincrbyStrategy,
+	ZREM:          zremStrategy,
+	ZCARD:         zcardStrategy,
+	ZSCORE:        zscoreStrategy,
+	ZCOUNT:        zcountStrategy,
+	ZRANK:         zrankStrategy,
+	ZREVRANK:      zrevrankStrategy,
+	ZPOPMIN:       zpopminStrategy,
+	ZPOPMAX:       zpopmaxStrategy,
+	ZRANGE:        zrangeStrategy,
+	ZRANGEBYSCORE: zrangebyscoreStrategy,
+	EXISTS:        existsStrategy,
+	TYPE:          typeStrategy,
+	EXPIRE:        expireStrategy,
+	PEXPIRE:       pexpireStrategy,
+	EXPIREAT:      expireatStrategy,
+	PEXPIREAT:     pexpireatStrategy,
+	TTL:           ttlStrategy,
+	PTTL:          pttlStrategy,
+	EXPIRETIME:    expiretimeStrategy,
+	PEXPIRETIME:   pexpiretimeStrategy,
+	PERSIST:       persistStrategy,
+	BGREWRITEAOF:  bgrewriteaofStrategy,
+	SAVE:          saveStrategy,
+	BGSAVE:        bgsaveStrategy,
+	LASTSAVE:      lastsaveStrategy,
+	COMMAND:       commandMetadataStrategy,
 }
 
 var commandMetadatas = []commandMetadata{
@@ -36,11 +165,126 @@ var commandMetadatas = []commandMetadata{
 	set,
 	del,
 	incr,
+	incrby,
+	decr,
+	decrby,
+	incrbyfloat,
 	hget,
 	hset,
 	hdel,
 	hgetAll,
+	lpush,
+	rpush,
+	lpop,
+	rpop,
+	lrange,
+	llen,
+	lindex,
+	lset,
+	lrem,
+	ltrim,
+	lmove,
+	blpop,
+	brpop,
+	blmove,
+	sadd,
+	srem,
+	smembers,
+	sismember,
+	smismember,
+	scard,
+	spop,
+	srandmember,
+	sinter,
+	sunion,
+	sdiff,
+	sinterstore,
+	sunionstore,
+	sdiffstore,
+	zadd,
+	zincrby,
+	zrem,
+	zcard,
+	zscore,
+	zcount,
+	zrank,
+	zrevrank,
+	zpopmin,
+	zpopmax,
+	zrange,
+	zrangebyscore,
+	exists,
+	keyType,
+	expire,
+	pexpire,
+	expireat,
+	pexpireat,
+	ttl,
+	pttl,
+	expiretime,
+	pexpiretime,
+	persist,
+	bgrewriteaof,
+	save,
+	bgsave,
+	lastsave,
+	multi,
+	exec,
+	discard,
+	watch,
+	unwatch,
+	subscribe,
+	unsubscribe,
+	psubscribe,
+	punsubscribe,
+	publish,
+	pubsub,
+	hello,
 	command,
 }
 
 var okResponse = resp.Value{Typ: resp.STRING.Typ, Str: "OK"}
+
+func notAnIntegerError() resp.Value {
+	return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is not an integer or out of range"}
+}
+
+func bulkArray(values []string) resp.Value {
+	array := make([]resp.Value, len(values))
+	for i, v := range values {
+		array[i] = resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(v)}
+	}
+
+	return resp.Value{Typ: resp.ARRAY.Typ, Array: array}
+}
+
+// / Like bulkArray, but RESP3 clients receive it as a set
+func bulkSet(values []string) resp.Value {
+	set := bulkArray(values)
+	set.Typ = resp.SET.Typ
+	return set
+}
+
+func bulkStrings(values []resp.Value) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = string(v.Bulk)
+	}
+	return result
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// / Redis answers with an error if a key holds another type, instead of acting as if the key did not exist
+func isWrongType(err error) bool {
+	return errors.Is(err, persistence.ErrWrongType)
+}
+
+func errorValue(err error) resp.Value {
+	return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+}
@@ -16,7 +16,7 @@ func request(command string, args []resp.Value) resp.Value {
 		Array: []resp.Value{
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: command,
+				Bulk: []byte(command),
 			},
 		},
 	}
@@ -32,3 +32,11 @@ func isCloseToTimestamp(t1, t2 time.Time, deviation time.Duration) bool {
 	}
 	return diff <= deviation
 }
+
+func bulks(values ...string) []resp.Value {
+	result := make([]resp.Value, len(values))
+	for i, v := range values {
+		result[i] = resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(v)}
+	}
+	return result
+}
@@ -15,10 +15,10 @@ func pingStrategy(request resp.Value, _ persistence.Database) resp.Value {
 	args := request.GetArgs()
 
 	if len(args) == 0 {
-		return resp.Value{Typ: "string", Str: "PONG"}
+		return resp.Value{Typ: resp.STRING.Typ, Str: "PONG"}
 	}
 
-	return resp.Value{Typ: resp.STRING.Typ, Str: args[0].Bulk}
+	return resp.Value{Typ: resp.STRING.Typ, Str: string(args[0].Bulk)}
 }
 
 // / Gives information about commands and about available commands
@@ -30,7 +30,7 @@ func commandMetadataStrategy(request resp.Value, _ persistence.Database) resp.Va
 
 	commandFilter := ""
 	if len(args) >= 1 {
-		commandFilter = strings.ToUpper(args[0].Bulk)
+		commandFilter = strings.ToUpper(string(args[0].Bulk))
 	}
 
 	var result []resp.Value
@@ -38,7 +38,7 @@ func commandMetadataStrategy(request resp.Value, _ persistence.Database) resp.Va
 	metadata := commandList()
 	if commandFilter == "DOCS" {
 		if len(args) >= 2 {
-			commandFilter = strings.ToUpper(args[1].Bulk)
+			commandFilter = strings.ToUpper(string(args[1].Bulk))
 		} else {
 			commandFilter = ""
 		}
@@ -3,6 +3,7 @@ package command
 
 import (
 	"gocache/internal/core/resp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,7 +11,7 @@ import (
 
 func Test_ping(t *testing.T) {
 	// given
-	expected := resp.Value{Typ: "string", Str: "PONG"}
+	expected := resp.Value{Typ: resp.STRING.Typ, Str: "PONG"}
 
 	ping, ok := Strategies[PING]
 	if !ok {
@@ -32,7 +33,7 @@ func Test_pingWithArg(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tiramisu",
+			Bulk: []byte("Tiramisu"),
 		},
 	}
 
@@ -42,7 +43,7 @@ func Test_pingWithArg(t *testing.T) {
 		return
 	}
 
-	expected := resp.Value{Typ: "string", Str: "Tiramisu"}
+	expected := resp.Value{Typ: resp.STRING.Typ, Str: "Tiramisu"}
 
 	// when
 	result := ping(request(PING, args), defaultDb())
@@ -58,15 +59,15 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "PING"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("PING")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: -1},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "readonly"},
-						{Typ: resp.BULK.Typ, Bulk: "fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("readonly")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("fast")},
 					},
 				},
 				// 4. first key
@@ -79,8 +80,8 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@connection"},
-						{Typ: resp.BULK.Typ, Bulk: "@fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@connection")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@fast")},
 					},
 				},
 			},
@@ -89,15 +90,15 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "GET"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("GET")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: 2},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "readonly"},
-						{Typ: resp.BULK.Typ, Bulk: "fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("readonly")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("fast")},
 					},
 				},
 				// 4. first key
@@ -110,9 +111,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@read"},
-						{Typ: resp.BULK.Typ, Bulk: "@fast"},
-						{Typ: resp.BULK.Typ, Bulk: "@string"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@read")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@fast")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@string")},
 					},
 				},
 			},
@@ -121,15 +122,15 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "SET"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("SET")},
 				// 2. arg count
-				{Typ: resp.INTEGER.Typ, Num: 3},
+				{Typ: resp.INTEGER.Typ, Num: -3},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "write"},
-						{Typ: resp.BULK.Typ, Bulk: "fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("write")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("fast")},
 					},
 				},
 				// 4. first key
@@ -142,9 +143,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@write"},
-						{Typ: resp.BULK.Typ, Bulk: "@slow"},
-						{Typ: resp.BULK.Typ, Bulk: "@string"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@write")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@slow")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@string")},
 					},
 				},
 			},
@@ -153,14 +154,14 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "DEL"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("DEL")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: -2},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "write"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("write")},
 					},
 				},
 				// 4. first key
@@ -173,9 +174,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@write"},
-						{Typ: resp.BULK.Typ, Bulk: "@slow"},
-						{Typ: resp.BULK.Typ, Bulk: "@keyspace"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@write")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@slow")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@keyspace")},
 					},
 				},
 			},
@@ -184,15 +185,15 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "INCR"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("INCR")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: 2},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "write"},
-						{Typ: resp.BULK.Typ, Bulk: "fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("write")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("fast")},
 					},
 				},
 				// 4. first key
@@ -205,9 +206,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@write"},
-						{Typ: resp.BULK.Typ, Bulk: "@fast"},
-						{Typ: resp.BULK.Typ, Bulk: "@string"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@write")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@fast")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@string")},
 					},
 				},
 			},
@@ -216,15 +217,15 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "HGET"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("HGET")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: 3},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "readonly"},
-						{Typ: resp.BULK.Typ, Bulk: "fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("readonly")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("fast")},
 					},
 				},
 				// 4. first key
@@ -237,9 +238,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@read"},
-						{Typ: resp.BULK.Typ, Bulk: "@hash"},
-						{Typ: resp.BULK.Typ, Bulk: "@fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@read")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@hash")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@fast")},
 					},
 				},
 			},
@@ -248,15 +249,15 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "HSET"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("HSET")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: 4},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "write"},
-						{Typ: resp.BULK.Typ, Bulk: "fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("write")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("fast")},
 					},
 				},
 				// 4. first key
@@ -269,9 +270,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@write"},
-						{Typ: resp.BULK.Typ, Bulk: "@hash"},
-						{Typ: resp.BULK.Typ, Bulk: "@fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@write")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@hash")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@fast")},
 					},
 				},
 			},
@@ -280,14 +281,14 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "HDEL"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("HDEL")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: -3},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "write"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("write")},
 					},
 				},
 				// 4. first key
@@ -300,9 +301,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@write"},
-						{Typ: resp.BULK.Typ, Bulk: "@fast"},
-						{Typ: resp.BULK.Typ, Bulk: "@hash"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@write")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@fast")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@hash")},
 					},
 				},
 			},
@@ -311,14 +312,14 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "HGETALL"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("HGETALL")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: 2},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "readonly"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("readonly")},
 					},
 				},
 				// 4. first key
@@ -331,9 +332,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@read"},
-						{Typ: resp.BULK.Typ, Bulk: "@hash"},
-						{Typ: resp.BULK.Typ, Bulk: "@slow"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@read")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@hash")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@slow")},
 					},
 				},
 			},
@@ -342,14 +343,14 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "COMMAND"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("COMMAND")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: -1},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "readonly"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("readonly")},
 					},
 				},
 				// 4. first key
@@ -362,8 +363,8 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@connection"},
-						{Typ: resp.BULK.Typ, Bulk: "@slow"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@connection")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@slow")},
 					},
 				},
 			},
@@ -372,14 +373,14 @@ func Test_command_returnsSpecs(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "COMMAND DOCS"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("COMMAND DOCS")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: -2},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "readonly"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("readonly")},
 					},
 				},
 				// 4. first key
@@ -392,8 +393,8 @@ func Test_command_returnsSpecs(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@connection"},
-						{Typ: resp.BULK.Typ, Bulk: "@slow"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@connection")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@slow")},
 					},
 				},
 			},
@@ -410,7 +411,9 @@ func Test_command_returnsSpecs(t *testing.T) {
 	result := commandSpecs(request(COMMAND, []resp.Value{}), defaultDb())
 
 	// then
-	assert.ElementsMatch(t, expected, result.Array)
+	// only the initial commands are described here, the others are covered by the count
+	assert.Subset(t, result.Array, expected)
+	assert.Len(t, result.Array, amountOfCommands())
 }
 
 func Test_command_withFilter_caseInsensitive_returnsSpecOfFilter(t *testing.T) {
@@ -418,7 +421,7 @@ func Test_command_withFilter_caseInsensitive_returnsSpecOfFilter(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "PiNg",
+			Bulk: []byte("PiNg"),
 		},
 	}
 
@@ -427,15 +430,15 @@ func Test_command_withFilter_caseInsensitive_returnsSpecOfFilter(t *testing.T) {
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
 				// 1. command
-				{Typ: resp.BULK.Typ, Bulk: "PING"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("PING")},
 				// 2. arg count
 				{Typ: resp.INTEGER.Typ, Num: -1},
 				// 3. flags
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "readonly"},
-						{Typ: resp.BULK.Typ, Bulk: "fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("readonly")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("fast")},
 					},
 				},
 				// 4. first key
@@ -448,8 +451,8 @@ func Test_command_withFilter_caseInsensitive_returnsSpecOfFilter(t *testing.T) {
 				{
 					Typ: resp.ARRAY.Typ,
 					Array: []resp.Value{
-						{Typ: resp.BULK.Typ, Bulk: "@connection"},
-						{Typ: resp.BULK.Typ, Bulk: "@fast"},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@connection")},
+						{Typ: resp.BULK.Typ, Bulk: []byte("@fast")},
 					},
 				},
 			},
@@ -474,226 +477,226 @@ func Test_commandDocs_returnsDocs(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "DOCS",
+			Bulk: []byte("DOCS"),
 		},
 	}
 
 	expected := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "PING",
+			Bulk: []byte("PING"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Returns PONG if no argument is provided, otherwise return a copy of the argument as a bulk."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Returns PONG if no argument is provided, otherwise return a copy of the argument as a bulk.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "1.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("1.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "connection"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("connection")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(1)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(1)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "GET",
+			Bulk: []byte("GET"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Get the value of key."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Get the value of key.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "1.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("1.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "string"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("string")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(1)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(1)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "SET",
+			Bulk: []byte("SET"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Set key to hold the string value."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Set key to hold the string value.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "1.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("1.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "string"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("string")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(1)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(1)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "DEL",
+			Bulk: []byte("DEL"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Removes the specified keys."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Removes the specified keys.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "1.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("1.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "keyspace"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("keyspace")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(1) - O(N)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(1) - O(N)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "INCR",
+			Bulk: []byte("INCR"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Increments the number stored at key by one."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Increments the number stored at key by one.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "1.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("1.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "string"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("string")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(1)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(1)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "HGET",
+			Bulk: []byte("HGET"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Returns the value associated with field in the hash stored at key."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Returns the value associated with field in the hash stored at key.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "2.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("2.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "hash"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("hash")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(1)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(1)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "HSET",
+			Bulk: []byte("HSET"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Sets the specified fields to their respective values in the hash stored at key."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Sets the specified fields to their respective values in the hash stored at key.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "2.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("2.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "hash"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("hash")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(1)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(1)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "HDEL",
+			Bulk: []byte("HDEL"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Removes the specified fields from the hash stored at key."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Removes the specified fields from the hash stored at key.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "2.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("2.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "keyspace"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("keyspace")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(N)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(N)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "HGETALL",
+			Bulk: []byte("HGETALL"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Returns all fields and values of the hash stored at key."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Returns all fields and values of the hash stored at key.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "2.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("2.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "hash"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("hash")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(N)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(N)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "COMMAND",
+			Bulk: []byte("COMMAND"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Return an array with details about every Redis command."},
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "2.8.13"},
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "connection"},
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(N)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Return an array with details about every Redis command.")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("2.8.13")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("connection")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(N)")},
 			},
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "COMMAND DOCS",
+			Bulk: []byte("COMMAND DOCS"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Return documentary information about commands."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Return documentary information about commands.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "7.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("7.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "connection"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("connection")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(N)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(N)")},
 			},
 		},
 	}
@@ -708,7 +711,10 @@ func Test_commandDocs_returnsDocs(t *testing.T) {
 	result := commandSpecs(request(COMMAND, args), defaultDb())
 
 	// then
-	assert.Equal(t, expected, result.Array)
+	// only the initial commands are described here, the others are covered by the count
+	assert.Subset(t, result.Array, expected)
+	// every command has its name and its docs as entries
+	assert.Len(t, result.Array, 2*amountOfCommands())
 }
 
 func Test_commandDocs_withFilter_caseInsensitive_returnsDocsOfFilter(t *testing.T) {
@@ -716,33 +722,33 @@ func Test_commandDocs_withFilter_caseInsensitive_returnsDocsOfFilter(t *testing.
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "DOCS",
+			Bulk: []byte("DOCS"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "PiNg",
+			Bulk: []byte("PiNg"),
 		},
 	}
 
 	expected := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "PING",
+			Bulk: []byte("PING"),
 		},
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: "summary"},
-				{Typ: resp.BULK.Typ, Bulk: "Returns PONG if no argument is provided, otherwise return a copy of the argument as a bulk."},
+				{Typ: resp.BULK.Typ, Bulk: []byte("summary")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Returns PONG if no argument is provided, otherwise return a copy of the argument as a bulk.")},
 
-				{Typ: resp.BULK.Typ, Bulk: "since"},
-				{Typ: resp.BULK.Typ, Bulk: "1.0.0"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("since")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("1.0.0")},
 
-				{Typ: resp.BULK.Typ, Bulk: "group"},
-				{Typ: resp.BULK.Typ, Bulk: "connection"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("group")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("connection")},
 
-				{Typ: resp.BULK.Typ, Bulk: "complexity"},
-				{Typ: resp.BULK.Typ, Bulk: "O(1)"},
+				{Typ: resp.BULK.Typ, Bulk: []byte("complexity")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("O(1)")},
 			},
 		},
 	}
@@ -759,3 +765,25 @@ func Test_commandDocs_withFilter_caseInsensitive_returnsDocsOfFilter(t *testing.
 	// then
 	assert.Equal(t, expected, result.Array)
 }
+
+func amountOfCommands() int {
+	amount := 0
+	for _, v := range commandMetadatas {
+		amount += 1 + len(v.subCommands)
+	}
+	return amount
+}
+
+func Test_commandMetadatas_namesAreUnique(t *testing.T) {
+	// given
+	seen := map[string]bool{}
+
+	for _, metadata := range commandMetadatas {
+		// when
+		name := strings.ToUpper(metadata.name)
+
+		// then
+		assert.False(t, seen[name], "%s is described more than once", name)
+		seen[name] = true
+	}
+}
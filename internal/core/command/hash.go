@@ -15,15 +15,15 @@ func hsetStrategy(request resp.Value, db persistence.Database) resp.Value {
 	args := request.GetArgs()
 
 	if len(args) != 3 {
-		return resp.Value{Typ: "error", Str: "ERR wrong number of arguments for 'hset' command"}
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'hset' command"}
 	}
 
-	hash := args[0].Bulk
-	key := args[1].Bulk
-	value := args[2].Bulk
+	hash := string(args[0].Bulk)
+	key := string(args[1].Bulk)
+	value := string(args[2].Bulk)
 
 	if err := db.SaveHash(request, hash, key, value); err != nil {
-		return resp.Value{Typ: "error", Str: err.Error()}
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
 	}
 
 	return okResponse
@@ -38,24 +38,27 @@ func hgetStrategy(request resp.Value, db persistence.Database) resp.Value {
 	args := request.GetArgs()
 
 	if len(args) != 2 {
-		return resp.Value{Typ: "error", Str: "ERR wrong number of arguments for 'hget' command"}
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'hget' command"}
 	}
 
-	hash := args[0].Bulk
-	key := args[1].Bulk
+	hash := string(args[0].Bulk)
+	key := string(args[1].Bulk)
 
 	mapValue, err := db.GetHash(hash)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
 	if err != nil {
 		log.Printf("Did not find any value with hash %s\n", hash)
-		return resp.Value{Typ: "null"}
+		return resp.Value{Typ: resp.NULL.Typ}
 	}
 	value, ok := mapValue[key]
 	if !ok {
 		log.Printf("Did not find any value with key %s\n", key)
-		return resp.Value{Typ: "null"}
+		return resp.Value{Typ: resp.NULL.Typ}
 	}
 
-	return resp.Value{Typ: "bulk", Bulk: value}
+	return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(value)}
 }
 
 // / Deletes the specified fields inside a hash
@@ -70,7 +73,7 @@ func hdelStrategy(request resp.Value, db persistence.Database) resp.Value {
 		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'hdel' command"}
 	}
 
-	hashKey := args[0].Bulk
+	hashKey := string(args[0].Bulk)
 
 	keys := []string{}
 	for _, key := range args[1:] {
@@ -78,7 +81,7 @@ func hdelStrategy(request resp.Value, db persistence.Database) resp.Value {
 			continue
 		}
 
-		keys = append(keys, key.Bulk)
+		keys = append(keys, string(key.Bulk))
 	}
 
 	amountDeleted, err := db.DeleteAllHashKeys(request, hashKey, keys)
@@ -100,23 +103,25 @@ func hgetAllStrategy(request resp.Value, db persistence.Database) resp.Value {
 	args := request.GetArgs()
 
 	if len(args) != 1 {
-		return resp.Value{Typ: "error", Str: "ERR wrong number of arguments for 'hgetall' command"}
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'hgetall' command"}
 	}
 
-	hash := args[0].Bulk
+	hash := string(args[0].Bulk)
 
 	value, err := db.GetHash(hash)
-
+	if isWrongType(err) {
+		return errorValue(err)
+	}
 	if err != nil {
 		log.Println(err.Error())
-		return resp.Value{Typ: "null"}
+		return resp.Value{Typ: resp.NULL.Typ}
 	}
 
 	values := []resp.Value{}
 	for k, v := range value {
-		values = append(values, resp.Value{Typ: resp.BULK.Typ, Bulk: k})
-		values = append(values, resp.Value{Typ: resp.BULK.Typ, Bulk: v})
+		values = append(values, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(k)})
+		values = append(values, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(v)})
 	}
 
-	return resp.Value{Typ: "array", Array: values}
+	return resp.Value{Typ: resp.MAP.Typ, Array: values}
 }
@@ -12,20 +12,20 @@ func Test_hset(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "misu",
+			Bulk: []byte("misu"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "cute",
+			Bulk: []byte("cute"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "string",
+		Typ: resp.STRING.Typ,
 		Str: "OK",
 	}
 
@@ -61,7 +61,7 @@ func Test_hsetNeedsThreeArgs(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 	}
 
@@ -75,7 +75,7 @@ func Test_hsetNeedsThreeArgs(t *testing.T) {
 	result := hset(request(HSET, args), defaultDb())
 
 	// then
-	assert.Equal(t, "error", result.Typ)
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
 }
 
 func Test_hget(t *testing.T) {
@@ -86,17 +86,17 @@ func Test_hget(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "misu",
+			Bulk: []byte("misu"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ:  "bulk",
-		Bulk: "cute",
+		Typ:  resp.BULK.Typ,
+		Bulk: []byte("cute"),
 	}
 
 	hget, ok := Strategies[HGET]
@@ -130,7 +130,7 @@ func Test_hgetCanOnlyReceiveTwoArg(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 	}
 
@@ -144,7 +144,7 @@ func Test_hgetCanOnlyReceiveTwoArg(t *testing.T) {
 	result := hget(request(HGET, args), defaultDb())
 
 	// then
-	assert.Equal(t, "error", result.Typ)
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
 }
 
 func Test_hgetNoValueAvailable(t *testing.T) {
@@ -152,16 +152,16 @@ func Test_hgetNoValueAvailable(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "misu",
+			Bulk: []byte("misu"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "null",
+		Typ: resp.NULL.Typ,
 	}
 
 	hget, ok := Strategies[HGET]
@@ -187,17 +187,17 @@ func Test_hdel(t *testing.T) {
 		// hash
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 		// field
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "misu",
+			Bulk: []byte("misu"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "integer",
+		Typ: resp.INTEGER.Typ,
 		Num: 1,
 	}
 
@@ -242,17 +242,17 @@ func Test_hdel_lastKeyDeletesHash(t *testing.T) {
 		// hash
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 		// field
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "misu",
+			Bulk: []byte("misu"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "integer",
+		Typ: resp.INTEGER.Typ,
 		Num: 1,
 	}
 
@@ -286,22 +286,22 @@ func Test_hdel_deleteMultipleFields(t *testing.T) {
 		// hash
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 		// field
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "misu",
+			Bulk: []byte("misu"),
 		},
 		// field
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "void",
+			Bulk: []byte("void"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "integer",
+		Typ: resp.INTEGER.Typ,
 		Num: 2,
 	}
 
@@ -349,7 +349,7 @@ func Test_hdel_needsAtLeastTwoArgs(t *testing.T) {
 		// hash
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "tira",
+			Bulk: []byte("tira"),
 		},
 	}
 
@@ -363,5 +363,5 @@ func Test_hdel_needsAtLeastTwoArgs(t *testing.T) {
 	result := hdel(request(HDEL, args), defaultDb())
 
 	// then
-	assert.Equal(t, "error", result.Typ)
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
 }
@@ -0,0 +1,253 @@
+package command
+
+import (
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// / Deletes the values at the specified keys, regardless of their type
+// / DEL {key1} [{key2}...]
+// / Example:
+// / Req: DEL tira
+// / Res: (integer) 1
+func delStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) == 0 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'del' command"}
+	}
+
+	keys := []string{}
+	for _, key := range args {
+		if key.Typ != resp.BULK.Typ {
+			continue
+		}
+
+		keys = append(keys, string(key.Bulk))
+	}
+
+	amountDeleted, err := db.DeleteKeys(request, keys)
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: amountDeleted}
+}
+
+// / Returns the amount of given keys that exist. A key mentioned multiple times is counted multiple times
+// / EXISTS {key1} [{key2}...]
+// / Example:
+// / Req: EXISTS tira misu
+// / Res: (integer) 1
+func existsStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) == 0 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'exists' command"}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: db.CountExistingKeys(bulkStrings(args))}
+}
+
+// / Returns the type of the value stored at key, or none if the key does not exist
+// / TYPE {key}
+// / Example:
+// / Req: TYPE tira
+// / Res: list
+func typeStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'type' command"}
+	}
+
+	return resp.Value{Typ: resp.STRING.Typ, Str: db.GetType(string(args[0].Bulk))}
+}
+
+// / Sets a timeout in seconds on key, after which the key is deleted. Works for every type
+// / EXPIRE {key} {seconds} [NX|XX|GT|LT]
+// / Example:
+// / Req: EXPIRE tira 10
+// / Res: (integer) 1
+func expireStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setExpiration(request, db, time.Second, false, "expire")
+}
+
+// / Same as EXPIRE, but the timeout is given in milliseconds
+// / PEXPIRE {key} {milliseconds} [NX|XX|GT|LT]
+// / Example:
+// / Req: PEXPIRE tira 1500
+// / Res: (integer) 1
+func pexpireStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setExpiration(request, db, time.Millisecond, false, "pexpire")
+}
+
+// / Same as EXPIRE, but takes an absolute unix timestamp in seconds. A timestamp in the past deletes the key
+// / EXPIREAT {key} {unix-time-seconds} [NX|XX|GT|LT]
+// / Example:
+// / Req: EXPIREAT tira 1893456000
+// / Res: (integer) 1
+func expireatStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setExpiration(request, db, time.Second, true, "expireat")
+}
+
+// / Same as EXPIREAT, but the timestamp is given in milliseconds
+// / PEXPIREAT {key} {unix-time-milliseconds} [NX|XX|GT|LT]
+// / Example:
+// / Req: PEXPIREAT tira 1893456000000
+// / Res: (integer) 1
+func pexpireatStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setExpiration(request, db, time.Millisecond, true, "pexpireat")
+}
+
+// / Like redis, timestamps only have to fit into unix milliseconds, while relative expirations have to fit into a time.Duration, which counts nanoseconds
+func expirationTime(amount int64, unit time.Duration, absolute bool) (time.Time, bool) {
+	if absolute && unit == time.Millisecond {
+		return time.UnixMilli(amount).UTC(), true
+	}
+	if absolute {
+		if amount > math.MaxInt64/1000 || amount < math.MinInt64/1000 {
+			return time.Time{}, false
+		}
+		return time.Unix(amount, 0).UTC(), true
+	}
+
+	if amount > math.MaxInt64/int64(unit) || amount < math.MinInt64/int64(unit) {
+		return time.Time{}, false
+	}
+	return time.Now().UTC().Add(time.Duration(amount) * unit), true
+}
+
+func setExpiration(request resp.Value, db persistence.Database, unit time.Duration, absolute bool, name string) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
+	}
+
+	amount, err := strconv.ParseInt(string(args[1].Bulk), 10, 64)
+	if err != nil {
+		return notAnIntegerError()
+	}
+
+	options := persistence.ExpirationOptions{}
+	for _, option := range args[2:] {
+		switch strings.ToUpper(string(option.Bulk)) {
+		case "NX":
+			options.OnlyWithout = true
+		case "XX":
+			options.OnlyWith = true
+		case "GT":
+			options.OnlyGreater = true
+		case "LT":
+			options.OnlyLess = true
+		default:
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR Unsupported option " + string(option.Bulk)}
+		}
+	}
+
+	if options.OnlyWithout && (options.OnlyWith || options.OnlyGreater || options.OnlyLess) {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR NX and XX, GT or LT options at the same time are not compatible"}
+	}
+	if options.OnlyGreater && options.OnlyLess {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR GT and LT options at the same time are not compatible"}
+	}
+
+	expiresAt, ok := expirationTime(amount, unit, absolute)
+	if !ok {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR invalid expire time in '" + name + "' command"}
+	}
+
+	applied, err := db.SetExpiration(request, string(args[0].Bulk), expiresAt, options)
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: boolToInt(applied)}
+}
+
+// / Returns the remaining time to live of key in seconds. Returns -1 if the key has no expiration and -2 if it does not exist
+// / TTL {key}
+// / Example:
+// / Req: TTL tira
+// / Res: (integer) 10
+func ttlStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return getExpiration(request, db, "ttl", func(expiresAt time.Time) int {
+		return int((time.Until(expiresAt) + time.Second/2) / time.Second)
+	})
+}
+
+// / Same as TTL, but returns the time to live in milliseconds
+// / PTTL {key}
+// / Example:
+// / Req: PTTL tira
+// / Res: (integer) 9998
+func pttlStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return getExpiration(request, db, "pttl", func(expiresAt time.Time) int {
+		return int(time.Until(expiresAt) / time.Millisecond)
+	})
+}
+
+// / Returns the absolute unix timestamp in seconds at which key expires. Returns -1 if the key has no expiration and -2 if it does not exist
+// / EXPIRETIME {key}
+// / Example:
+// / Req: EXPIRETIME tira
+// / Res: (integer) 1893456000
+func expiretimeStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return getExpiration(request, db, "expiretime", func(expiresAt time.Time) int {
+		return int(expiresAt.Unix())
+	})
+}
+
+// / Same as EXPIRETIME, but returns the timestamp in milliseconds
+// / PEXPIRETIME {key}
+// / Example:
+// / Req: PEXPIRETIME tira
+// / Res: (integer) 1893456000000
+func pexpiretimeStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return getExpiration(request, db, "pexpiretime", func(expiresAt time.Time) int {
+		return int(expiresAt.UnixMilli())
+	})
+}
+
+func getExpiration(request resp.Value, db persistence.Database, name string, format func(expiresAt time.Time) int) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
+	}
+
+	expiration, ok := db.GetExpiration(string(args[0].Bulk))
+	if !ok {
+		return resp.Value{Typ: resp.INTEGER.Typ, Num: -2}
+	}
+	if expiration == nil {
+		return resp.Value{Typ: resp.INTEGER.Typ, Num: -1}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: format(expiration.ExpiresAt)}
+}
+
+// / Removes the expiration of key, so it is kept forever. Returns 1 if an expiration was removed
+// / PERSIST {key}
+// / Example:
+// / Req: PERSIST tira
+// / Res: (integer) 1
+func persistStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'persist' command"}
+	}
+
+	removed, err := db.RemoveExpiration(request, string(args[0].Bulk))
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: boolToInt(removed)}
+}
@@ -0,0 +1,493 @@
+package command
+
+import (
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_del_anyType(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+	db.PushList(resp.Value{}, "misu", []string{"cute"}, false)
+	db.AddToSet(resp.Value{}, "cute", []string{"void"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 3,
+	}
+
+	del, ok := Strategies[DEL]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := del(request(DEL, bulks("tira", "misu", "cute", "void")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+	assert.Equal(t, 0, db.CountExistingKeys([]string{"tira", "misu", "cute"}))
+}
+
+func Test_exists(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+	db.AddToSet(resp.Value{}, "misu", []string{"cute"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 3,
+	}
+
+	exists, ok := Strategies[EXISTS]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := exists(request(EXISTS, bulks("tira", "misu", "tira", "void")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_exists_ignoresExpiredKeys(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 0,
+	}
+
+	exists, ok := Strategies[EXISTS]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := exists(request(EXISTS, bulks("tira")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_exists_needsAtLeastOneKey(t *testing.T) {
+	// given
+	exists, ok := Strategies[EXISTS]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := exists(request(EXISTS, []resp.Value{}), defaultDb())
+
+	// then
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
+}
+
+func Test_type(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "string", persistence.NewString("misu", 0))
+	db.SaveHash(resp.Value{}, "hash", "misu", "cute")
+	db.PushList(resp.Value{}, "list", []string{"misu"}, false)
+	db.AddToSet(resp.Value{}, "set", []string{"misu"})
+	db.AddToSortedSet(resp.Value{}, "zset", []persistence.ScoredMember{{Member: "misu", Score: 1}}, persistence.SortedSetAddOptions{})
+
+	keyType, ok := Strategies[TYPE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	for _, expected := range []string{"string", "hash", "list", "set", "zset"} {
+		// when
+		result := keyType(request(TYPE, bulks(expected)), db)
+
+		// then
+		assert.EqualValues(t, resp.Value{Typ: resp.STRING.Typ, Str: expected}, result)
+	}
+}
+
+func Test_type_missingKey(t *testing.T) {
+	// given
+	keyType, ok := Strategies[TYPE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := keyType(request(TYPE, bulks("tira")), defaultDb())
+
+	// then
+	assert.EqualValues(t, resp.Value{Typ: resp.STRING.Typ, Str: "none"}, result)
+}
+
+func Test_wrongType(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+
+	expected := resp.Value{
+		Typ: resp.ERROR.Typ,
+		Str: "WRONGTYPE Operation against a key holding the wrong kind of value",
+	}
+
+	requests := []resp.Value{
+		request(HSET, bulks("tira", "misu", "cute")),
+		request(HGET, bulks("tira", "misu")),
+		request(LPUSH, bulks("tira", "misu")),
+		request(LRANGE, bulks("tira", "0", "-1")),
+		request(SADD, bulks("tira", "misu")),
+		request(SMEMBERS, bulks("tira")),
+		request(ZADD, bulks("tira", "1", "misu")),
+		request(ZSCORE, bulks("tira", "misu")),
+	}
+
+	for _, r := range requests {
+		// when
+		result := Strategies[string(r.Array[0].Bulk)](r, db)
+
+		// then
+		assert.EqualValues(t, expected, result, string(r.Array[0].Bulk))
+	}
+
+	value, err := db.GetString("tira")
+	assert.NoError(t, err)
+	assert.Equal(t, "misu", value.Value)
+}
+
+func Test_wrongType_getOnList(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	get, ok := Strategies[GET]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := get(request(GET, bulks("tira")), db)
+
+	// then
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
+	assert.Contains(t, result.Str, "WRONGTYPE")
+}
+
+func Test_set_overwritesAnyType(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	set, ok := Strategies[SET]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := set(request(SET, bulks("tira", "cute")), db)
+
+	// then
+	assert.Equal(t, "OK", result.Str)
+	assert.Equal(t, "string", db.GetType("tira"))
+}
+
+func Test_expire(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveHash(resp.Value{}, "tira", "misu", "cute")
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 1,
+	}
+
+	expire, ok := Strategies[EXPIRE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := expire(request(EXPIRE, bulks("tira", "10")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	expiration, exists := db.GetExpiration("tira")
+	assert.True(t, exists)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), expiration.ExpiresAt, time.Second)
+}
+
+func Test_expire_missingKey(t *testing.T) {
+	// given
+	expire, ok := Strategies[EXPIRE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := expire(request(EXPIRE, bulks("tira", "10")), defaultDb())
+
+	// then
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 0}, result)
+}
+
+func Test_expire_options(t *testing.T) {
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		args     []string
+		expected int
+	}{
+		{"NX without expiration", 0, []string{"10", "NX"}, 1},
+		{"NX with expiration", time.Minute, []string{"10", "NX"}, 0},
+		{"XX without expiration", 0, []string{"10", "XX"}, 0},
+		{"XX with expiration", time.Minute, []string{"10", "XX"}, 1},
+		{"GT without expiration", 0, []string{"10", "GT"}, 0},
+		{"GT with smaller expiration", time.Second, []string{"10", "GT"}, 1},
+		{"GT with greater expiration", time.Minute, []string{"10", "GT"}, 0},
+		{"LT without expiration", 0, []string{"10", "LT"}, 1},
+		{"LT with smaller expiration", time.Second, []string{"10", "LT"}, 0},
+		{"LT with greater expiration", time.Minute, []string{"10", "LT"}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			db := defaultDb()
+			db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", test.ttl))
+
+			// when
+			result := Strategies[EXPIRE](request(EXPIRE, bulks(append([]string{"tira"}, test.args...)...)), db)
+
+			// then
+			assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: test.expected}, result)
+		})
+	}
+}
+
+func Test_expire_incompatibleOptions(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+
+	for _, options := range [][]string{{"NX", "XX"}, {"NX", "GT"}, {"GT", "LT"}, {"SOON"}} {
+		// when
+		result := Strategies[EXPIRE](request(EXPIRE, bulks(append([]string{"tira", "10"}, options...)...)), db)
+
+		// then
+		assert.Equal(t, resp.ERROR.Typ, result.Typ, options)
+	}
+}
+
+func Test_expire_notAnInteger(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+
+	// when
+	result := Strategies[EXPIRE](request(EXPIRE, bulks("tira", "soon")), db)
+
+	// then
+	assert.EqualValues(t, notAnIntegerError(), result)
+}
+
+func Test_pexpire_negativeDeletesKey(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	pexpire, ok := Strategies[PEXPIRE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := pexpire(request(PEXPIRE, bulks("tira", "-1")), db)
+
+	// then
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 1}, result)
+	assert.Equal(t, "none", db.GetType("tira"))
+}
+
+func Test_expireat(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+
+	expireat, ok := Strategies[EXPIREAT]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := expireat(request(EXPIREAT, bulks("tira", "4102444800")), db)
+
+	// then
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 1}, result)
+
+	expiretime := Strategies[EXPIRETIME](request(EXPIRETIME, bulks("tira")), db)
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 4102444800}, expiretime)
+
+	pexpiretime := Strategies[PEXPIRETIME](request(PEXPIRETIME, bulks("tira")), db)
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 4102444800000}, pexpiretime)
+}
+
+func Test_expire_limits(t *testing.T) {
+	tests := []struct {
+		command  string
+		amount   string
+		expected resp.Value
+	}{
+		{EXPIREAT, "10000000000", resp.Value{Typ: resp.INTEGER.Typ, Num: 1}},
+		{PEXPIREAT, "10000000000000", resp.Value{Typ: resp.INTEGER.Typ, Num: 1}},
+		{EXPIREAT, "9223372036854775", resp.Value{Typ: resp.INTEGER.Typ, Num: 1}},
+		{EXPIREAT, "9223372036854776", resp.Value{Typ: resp.ERROR.Typ, Str: "ERR invalid expire time in 'expireat' command"}},
+		{EXPIRE, "9223372037", resp.Value{Typ: resp.ERROR.Typ, Str: "ERR invalid expire time in 'expire' command"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.command+" "+test.amount, func(t *testing.T) {
+			// given
+			db := defaultDb()
+			db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+
+			// when
+			result := Strategies[test.command](request(test.command, bulks("tira", test.amount)), db)
+
+			// then
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func Test_expireat_afterYear2262(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+
+	// when
+	Strategies[EXPIREAT](request(EXPIREAT, bulks("tira", "10000000000")), db)
+
+	// then
+	expiretime := Strategies[EXPIRETIME](request(EXPIRETIME, bulks("tira")), db)
+	assert.Equal(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 10000000000}, expiretime)
+}
+
+func Test_pexpireat_pastDeletesKey(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+
+	pexpireat, ok := Strategies[PEXPIREAT]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := pexpireat(request(PEXPIREAT, bulks("tira", "1000")), db)
+
+	// then
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 1}, result)
+	assert.Equal(t, 0, db.CountExistingKeys([]string{"tira"}))
+}
+
+func Test_ttl(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "expiring", persistence.NewString("misu", 10*time.Second))
+	db.SaveString(resp.Value{}, "forever", persistence.NewString("misu", 0))
+
+	ttl, ok := Strategies[TTL]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	expiring := ttl(request(TTL, bulks("expiring")), db)
+	forever := ttl(request(TTL, bulks("forever")), db)
+	missing := ttl(request(TTL, bulks("missing")), db)
+
+	// then
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 10}, expiring)
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: -1}, forever)
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: -2}, missing)
+}
+
+func Test_pttl(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 10*time.Second))
+
+	pttl, ok := Strategies[PTTL]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := pttl(request(PTTL, bulks("tira")), db)
+
+	// then
+	assert.Equal(t, resp.INTEGER.Typ, result.Typ)
+	assert.InDelta(t, 10000, result.Num, 100)
+}
+
+func Test_expiretime_withoutExpiration(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", 0))
+
+	// when
+	result := Strategies[EXPIRETIME](request(EXPIRETIME, bulks("tira")), db)
+
+	// then
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: -1}, result)
+}
+
+func Test_persist(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", time.Minute))
+
+	persist, ok := Strategies[PERSIST]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	first := persist(request(PERSIST, bulks("tira")), db)
+	second := persist(request(PERSIST, bulks("tira")), db)
+
+	// then
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 1}, first)
+	assert.EqualValues(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 0}, second)
+
+	expiration, exists := db.GetExpiration("tira")
+	assert.True(t, exists)
+	assert.Nil(t, expiration)
+}
@@ -0,0 +1,314 @@
+package command
+
+import (
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// / Inserts all values at the head of the list stored at key. Creates the list if it does not exist
+// / LPUSH {key} {value1} [{value2}...]
+// / Example:
+// / Req: LPUSH tira misu
+// / Res: (integer) 1
+func lpushStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return push(request, db, true, "lpush")
+}
+
+// / Inserts all values at the tail of the list stored at key. Creates the list if it does not exist
+// / RPUSH {key} {value1} [{value2}...]
+// / Example:
+// / Req: RPUSH tira misu
+// / Res: (integer) 1
+func rpushStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return push(request, db, false, "rpush")
+}
+
+func push(request resp.Value, db persistence.Database, head bool, name string) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
+	}
+
+	key := string(args[0].Bulk)
+
+	length, err := db.PushList(request, key, bulkStrings(args[1:]), head)
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: length}
+}
+
+// / Removes and returns the first elements of the list stored at key
+// / LPOP {key} [{count}]
+// / Example:
+// / Req: LPOP tira
+// / Res: misu
+func lpopStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return pop(request, db, true, "lpop")
+}
+
+// / Removes and returns the last elements of the list stored at key
+// / RPOP {key} [{count}]
+// / Example:
+// / Req: RPOP tira
+// / Res: misu
+func rpopStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return pop(request, db, false, "rpop")
+}
+
+func pop(request resp.Value, db persistence.Database, head bool, name string) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
+	}
+
+	key := string(args[0].Bulk)
+
+	count := 1
+	if len(args) == 2 {
+		parsed, err := strconv.Atoi(string(args[1].Bulk))
+		if err != nil || parsed < 0 {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is out of range, must be positive"}
+		}
+		count = parsed
+	}
+
+	popped, err := db.PopList(request, key, count, head)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		log.Printf("Did not find any list with key %s\n", key)
+		return resp.Value{Typ: resp.NULL.Typ}
+	}
+
+	if len(args) == 1 {
+		return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(popped[0])}
+	}
+
+	return bulkArray(popped)
+}
+
+// / Returns the elements between start and stop (both inclusive). Negative indices count from the end of the list
+// / LRANGE {key} {start} {stop}
+// / Example:
+// / Req: LRANGE tira 0 -1
+// / Res:
+// / misu
+// / cute
+func lrangeStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'lrange' command"}
+	}
+
+	key := string(args[0].Bulk)
+
+	start, err := strconv.Atoi(string(args[1].Bulk))
+	if err != nil {
+		return notAnIntegerError()
+	}
+	stop, err := strconv.Atoi(string(args[2].Bulk))
+	if err != nil {
+		return notAnIntegerError()
+	}
+
+	values, err := db.GetListRange(key, start, stop)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		return bulkArray([]string{})
+	}
+
+	return bulkArray(values)
+}
+
+// / Returns the length of the list stored at key. Returns 0 if the key does not exist
+// / LLEN {key}
+// / Example:
+// / Req: LLEN tira
+// / Res: (integer) 2
+func llenStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'llen' command"}
+	}
+
+	length, err := db.GetListLength(string(args[0].Bulk))
+	if err != nil {
+		return errorValue(err)
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: length}
+}
+
+// / Returns the element at index of the list stored at key. Negative indices count from the end of the list
+// / LINDEX {key} {index}
+// / Example:
+// / Req: LINDEX tira -1
+// / Res: cute
+func lindexStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'lindex' command"}
+	}
+
+	key := string(args[0].Bulk)
+
+	index, err := strconv.Atoi(string(args[1].Bulk))
+	if err != nil {
+		return notAnIntegerError()
+	}
+
+	values, err := db.GetListRange(key, index, index)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil || len(values) == 0 {
+		return resp.Value{Typ: resp.NULL.Typ}
+	}
+
+	return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(values[0])}
+}
+
+// / Sets the list element at index to value
+// / LSET {key} {index} {value}
+// / Example:
+// / Req: LSET tira 0 misu
+// / Res: OK
+func lsetStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'lset' command"}
+	}
+
+	key := string(args[0].Bulk)
+	value := string(args[2].Bulk)
+
+	index, err := strconv.Atoi(string(args[1].Bulk))
+	if err != nil {
+		return notAnIntegerError()
+	}
+
+	if err := db.SetListElement(request, key, index, value); err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return okResponse
+}
+
+// / Removes the first count occurrences of value. A negative count removes from the tail, 0 removes every occurrence
+// / LREM {key} {count} {value}
+// / Example:
+// / Req: LREM tira 0 misu
+// / Res: (integer) 2
+func lremStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'lrem' command"}
+	}
+
+	key := string(args[0].Bulk)
+	value := string(args[2].Bulk)
+
+	count, err := strconv.Atoi(string(args[1].Bulk))
+	if err != nil {
+		return notAnIntegerError()
+	}
+
+	amountRemoved, err := db.RemoveListElements(request, key, count, value)
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: amountRemoved}
+}
+
+// / Trims the list, so that it only contains the elements between start and stop (both inclusive)
+// / LTRIM {key} {start} {stop}
+// / Example:
+// / Req: LTRIM tira 0 99
+// / Res: OK
+func ltrimStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'ltrim' command"}
+	}
+
+	key := string(args[0].Bulk)
+
+	start, err := strconv.Atoi(string(args[1].Bulk))
+	if err != nil {
+		return notAnIntegerError()
+	}
+	stop, err := strconv.Atoi(string(args[2].Bulk))
+	if err != nil {
+		return notAnIntegerError()
+	}
+
+	if err := db.TrimList(request, key, start, stop); err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return okResponse
+}
+
+// / Pops an element from source and pushes it to destination. Returns the moved element, or null if source does not exist
+// / LMOVE {source} {destination} {LEFT|RIGHT} {LEFT|RIGHT}
+// / Example:
+// / Req: LMOVE tira misu LEFT RIGHT
+// / Res: cute
+func lmoveStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 4 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'lmove' command"}
+	}
+
+	source := string(args[0].Bulk)
+	destination := string(args[1].Bulk)
+
+	fromHead, ok := ParseListSide(string(args[2].Bulk))
+	if !ok {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}
+	}
+	toHead, ok := ParseListSide(string(args[3].Bulk))
+	if !ok {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}
+	}
+
+	value, err := db.MoveList(request, source, destination, fromHead, toHead)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		log.Printf("Did not find any list with key %s\n", source)
+		return resp.Value{Typ: resp.NULL.Typ}
+	}
+
+	return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(value)}
+}
+
+// / Parses the LEFT or RIGHT argument of LMOVE and BLMOVE. Returns true for the head, LEFT
+func ParseListSide(side string) (bool, bool) {
+	switch strings.ToUpper(side) {
+	case "LEFT":
+		return true, true
+	case "RIGHT":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,542 @@
+package command
+
+import (
+	"gocache/internal/core/resp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lpush(t *testing.T) {
+	// given
+	db := defaultDb()
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	lpush, ok := Strategies[LPUSH]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lpush(request(LPUSH, bulks("tira", "misu", "cute")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	values, err := db.GetListRange("tira", 0, -1)
+	if err != nil {
+		t.Error("List Storage did not contain key 'tira'")
+		return
+	}
+	assert.Equal(t, []string{"cute", "misu"}, values)
+}
+
+func Test_rpush(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 3,
+	}
+
+	rpush, ok := Strategies[RPUSH]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := rpush(request(RPUSH, bulks("tira", "cute", "void")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	values, err := db.GetListRange("tira", 0, -1)
+	if err != nil {
+		t.Error("List Storage did not contain key 'tira'")
+		return
+	}
+	assert.Equal(t, []string{"misu", "cute", "void"}, values)
+}
+
+func Test_lpush_needsAtLeastTwoArgs(t *testing.T) {
+	// given
+	lpush, ok := Strategies[LPUSH]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lpush(request(LPUSH, bulks("tira")), defaultDb())
+
+	// then
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
+}
+
+func Test_lpop(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute"}, false)
+
+	expected := resp.Value{
+		Typ:  resp.BULK.Typ,
+		Bulk: []byte("misu"),
+	}
+
+	lpop, ok := Strategies[LPOP]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lpop(request(LPOP, bulks("tira")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+	length, _ := db.GetListLength("tira")
+	assert.Equal(t, 1, length)
+}
+
+func Test_rpop_withCount(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute", "void"}, false)
+
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("void", "cute"),
+	}
+
+	rpop, ok := Strategies[RPOP]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := rpop(request(RPOP, bulks("tira", "2")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"misu"}, values)
+}
+
+func Test_lpop_lastElementDeletesList(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	lpop, ok := Strategies[LPOP]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	lpop(request(LPOP, bulks("tira")), db)
+
+	// then
+	_, err := db.GetListRange("tira", 0, -1)
+	if err == nil {
+		t.Error("List Storage did not get key 'tira' deleted")
+	}
+}
+
+func Test_lpop_noValueAvailable(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.NULL.Typ,
+	}
+
+	lpop, ok := Strategies[LPOP]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lpop(request(LPOP, bulks("tira")), defaultDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_lrange_negativeIndices(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute", "void", "scary"}, false)
+
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("cute", "void"),
+	}
+
+	lrange, ok := Strategies[LRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lrange(request(LRANGE, bulks("tira", "1", "-2")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_lrange_outOfRange_returnsEmptyArray(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: []resp.Value{},
+	}
+
+	lrange, ok := Strategies[LRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lrange(request(LRANGE, bulks("tira", "5", "10")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_lrange_needsNumbers(t *testing.T) {
+	// given
+	lrange, ok := Strategies[LRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lrange(request(LRANGE, bulks("tira", "misu", "1")), defaultDb())
+
+	// then
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
+}
+
+func Test_llen(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute"}, false)
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	llen, ok := Strategies[LLEN]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := llen(request(LLEN, bulks("tira")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_lindex(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute"}, false)
+
+	expected := resp.Value{
+		Typ:  resp.BULK.Typ,
+		Bulk: []byte("cute"),
+	}
+
+	lindex, ok := Strategies[LINDEX]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lindex(request(LINDEX, bulks("tira", "-1")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_lindex_outOfRange(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	expected := resp.Value{
+		Typ: resp.NULL.Typ,
+	}
+
+	lindex, ok := Strategies[LINDEX]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lindex(request(LINDEX, bulks("tira", "3")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_lset(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute"}, false)
+
+	expected := resp.Value{
+		Typ: resp.STRING.Typ,
+		Str: "OK",
+	}
+
+	lset, ok := Strategies[LSET]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lset(request(LSET, bulks("tira", "-1", "scary")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"misu", "scary"}, values)
+}
+
+func Test_lset_outOfRange_err(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	expected := resp.Value{
+		Typ: resp.ERROR.Typ,
+		Str: "ERR index out of range",
+	}
+
+	lset, ok := Strategies[LSET]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lset(request(LSET, bulks("tira", "1", "scary")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_lset_noSuchKey_err(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.ERROR.Typ,
+		Str: "ERR no such key",
+	}
+
+	lset, ok := Strategies[LSET]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lset(request(LSET, bulks("tira", "0", "scary")), defaultDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_lrem_fromHead(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute", "misu", "misu"}, false)
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	lrem, ok := Strategies[LREM]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lrem(request(LREM, bulks("tira", "2", "misu")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"cute", "misu"}, values)
+}
+
+func Test_lrem_fromTail(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute", "misu", "misu"}, false)
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 1,
+	}
+
+	lrem, ok := Strategies[LREM]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lrem(request(LREM, bulks("tira", "-1", "misu")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"misu", "cute", "misu"}, values)
+}
+
+func Test_lrem_all(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute", "misu"}, false)
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	lrem, ok := Strategies[LREM]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lrem(request(LREM, bulks("tira", "0", "misu")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"cute"}, values)
+}
+
+func Test_ltrim(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute", "void", "scary"}, false)
+
+	expected := resp.Value{
+		Typ: resp.STRING.Typ,
+		Str: "OK",
+	}
+
+	ltrim, ok := Strategies[LTRIM]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := ltrim(request(LTRIM, bulks("tira", "1", "-2")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"cute", "void"}, values)
+}
+
+func Test_ltrim_emptyRangeDeletesList(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute"}, false)
+
+	ltrim, ok := Strategies[LTRIM]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	ltrim(request(LTRIM, bulks("tira", "5", "10")), db)
+
+	// then
+	_, err := db.GetListRange("tira", 0, -1)
+	if err == nil {
+		t.Error("List Storage did not get key 'tira' deleted")
+	}
+}
+
+func Test_lmove(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute"}, false)
+	db.PushList(resp.Value{}, "void", []string{"scary"}, false)
+
+	lmove, ok := Strategies[LMOVE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := lmove(request(LMOVE, bulks("tira", "void", "left", "RIGHT")), db)
+	missing := lmove(request(LMOVE, bulks("nothing", "void", "LEFT", "RIGHT")), db)
+	invalid := lmove(request(LMOVE, bulks("tira", "void", "UP", "RIGHT")), db)
+
+	// then
+	assert.Equal(t, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte("misu")}, result)
+	assert.Equal(t, resp.Value{Typ: resp.NULL.Typ}, missing)
+	assert.Equal(t, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}, invalid)
+
+	values, _ := db.GetListRange("void", 0, -1)
+	assert.Equal(t, []string{"scary", "misu"}, values)
+}
+
+func Test_lmove_lastElementDeletesSource(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	lmove, ok := Strategies[LMOVE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	lmove(request(LMOVE, bulks("tira", "void", "RIGHT", "LEFT")), db)
+
+	// then
+	assert.Equal(t, "none", db.GetType("tira"))
+	values, _ := db.GetListRange("void", 0, -1)
+	assert.Equal(t, []string{"misu"}, values)
+}
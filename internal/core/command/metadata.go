@@ -22,7 +22,7 @@ var ping commandMetadata = commandMetadata{
 var set commandMetadata = commandMetadata{
 	name: SET,
 	spec: commandSpec{
-		argCount:      3,
+		argCount:      -3,
 		flags:         []string{"write", "fast"},
 		firstKey:      1,
 		lastKey:       2,
@@ -91,6 +91,78 @@ var incr commandMetadata = commandMetadata{
 	},
 }
 
+var incrby commandMetadata = commandMetadata{
+	name: INCRBY,
+	spec: commandSpec{
+		argCount:      3,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@fast", "@string"},
+	},
+	doc: commandDoc{
+		summary:    "Increments the integer value of a key by a number. Uses 0 as initial value if the key doesn't exist.",
+		since:      "1.0.0",
+		group:      "string",
+		complexity: "O(1)",
+	},
+}
+
+var decr commandMetadata = commandMetadata{
+	name: DECR,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@fast", "@string"},
+	},
+	doc: commandDoc{
+		summary:    "Decrements the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.",
+		since:      "1.0.0",
+		group:      "string",
+		complexity: "O(1)",
+	},
+}
+
+var decrby commandMetadata = commandMetadata{
+	name: DECRBY,
+	spec: commandSpec{
+		argCount:      3,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@fast", "@string"},
+	},
+	doc: commandDoc{
+		summary:    "Decrements a number from the integer value of a key. Uses 0 as initial value if the key doesn't exist.",
+		since:      "1.0.0",
+		group:      "string",
+		complexity: "O(1)",
+	},
+}
+
+var incrbyfloat commandMetadata = commandMetadata{
+	name: INCRBYFLOAT,
+	spec: commandSpec{
+		argCount:      3,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@fast", "@string"},
+	},
+	doc: commandDoc{
+		summary:    "Increment the floating point value of a key by a number. Uses 0 as initial value if the key doesn't exist.",
+		since:      "2.6.0",
+		group:      "string",
+		complexity: "O(1)",
+	},
+}
+
 var hset commandMetadata = commandMetadata{
 	name: HSET,
 	spec: commandSpec{
@@ -163,6 +235,1266 @@ var hgetAll commandMetadata = commandMetadata{
 	},
 }
 
+var lpush commandMetadata = commandMetadata{
+	name: LPUSH,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Prepends one or more elements to a list. Creates the key if it doesn't exist.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(1) for each element added, so O(N) to add N elements.",
+	},
+}
+
+var rpush commandMetadata = commandMetadata{
+	name: RPUSH,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Appends one or more elements to a list. Creates the key if it doesn't exist.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(1) for each element added, so O(N) to add N elements.",
+	},
+}
+
+var lpop commandMetadata = commandMetadata{
+	name: LPOP,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the first elements in a list after removing it. Deletes the list if the last element was popped.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(N) where N is the number of elements returned",
+	},
+}
+
+var rpop commandMetadata = commandMetadata{
+	name: RPOP,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns and removes the last elements of a list. Deletes the list if the last element was popped.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(N) where N is the number of elements returned",
+	},
+}
+
+var lrange commandMetadata = commandMetadata{
+	name: LRANGE,
+	spec: commandSpec{
+		argCount:      4,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@list", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns a range of elements from a list.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(S+N) where S is the distance of start offset from HEAD and N is the number of elements in the specified range.",
+	},
+}
+
+var llen commandMetadata = commandMetadata{
+	name: LLEN,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@list", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the length of a list.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(1)",
+	},
+}
+
+var lindex commandMetadata = commandMetadata{
+	name: LINDEX,
+	spec: commandSpec{
+		argCount:      3,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@list", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns an element from a list by its index.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(1)",
+	},
+}
+
+var lset commandMetadata = commandMetadata{
+	name: LSET,
+	spec: commandSpec{
+		argCount:      4,
+		flags:         []string{"write", "denyoom"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Sets the value of an element in a list by its index.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(1)",
+	},
+}
+
+var lrem commandMetadata = commandMetadata{
+	name: LREM,
+	spec: commandSpec{
+		argCount:      4,
+		flags:         []string{"write"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Removes elements from a list. Deletes the list if the last element was removed.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(N+M) where N is the length of the list and M is the number of elements removed.",
+	},
+}
+
+var ltrim commandMetadata = commandMetadata{
+	name: LTRIM,
+	spec: commandSpec{
+		argCount:      4,
+		flags:         []string{"write"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Removes elements from both ends a list. Deletes the list if all elements were trimmed.",
+		since:      "1.0.0",
+		group:      "list",
+		complexity: "O(N) where N is the number of elements to be removed by the operation.",
+	},
+}
+
+var lmove commandMetadata = commandMetadata{
+	name: LMOVE,
+	spec: commandSpec{
+		argCount:      5,
+		flags:         []string{"write", "denyoom"},
+		firstKey:      1,
+		lastKey:       2,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns an element after popping it from one list and pushing it to another. Deletes the list if the last element was moved.",
+		since:      "6.2.0",
+		group:      "list",
+		complexity: "O(1)",
+	},
+}
+
+var blpop commandMetadata = commandMetadata{
+	name: BLPOP,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "blocking"},
+		firstKey:      1,
+		lastKey:       -2,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@slow", "@blocking"},
+	},
+	doc: commandDoc{
+		summary:    "Removes and returns the first element in a list. Blocks until an element is available otherwise. Deletes the list if the last element was popped.",
+		since:      "2.0.0",
+		group:      "list",
+		complexity: "O(N) where N is the number of provided keys.",
+	},
+}
+
+var brpop commandMetadata = commandMetadata{
+	name: BRPOP,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "blocking"},
+		firstKey:      1,
+		lastKey:       -2,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@slow", "@blocking"},
+	},
+	doc: commandDoc{
+		summary:    "Removes and returns the last element in a list. Blocks until an element is available otherwise. Deletes the list if the last element was popped.",
+		since:      "2.0.0",
+		group:      "list",
+		complexity: "O(N) where N is the number of provided keys.",
+	},
+}
+
+var blmove commandMetadata = commandMetadata{
+	name: BLMOVE,
+	spec: commandSpec{
+		argCount:      6,
+		flags:         []string{"write", "denyoom", "blocking"},
+		firstKey:      1,
+		lastKey:       2,
+		steps:         1,
+		aclCategories: []string{"@write", "@list", "@slow", "@blocking"},
+	},
+	doc: commandDoc{
+		summary:    "Pops an element from a list, pushes it to another list and returns it. Blocks until an element is available otherwise. Deletes the list if the last element was moved.",
+		since:      "6.2.0",
+		group:      "list",
+		complexity: "O(1)",
+	},
+}
+
+var sadd commandMetadata = commandMetadata{
+	name: SADD,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@set", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Adds one or more members to a set. Creates the key if it doesn't exist.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(1) for each element added, so O(N) to add N elements when the command is called with multiple arguments.",
+	},
+}
+
+var srem commandMetadata = commandMetadata{
+	name: SREM,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@set", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Removes one or more members from a set. Deletes the set if the last member was removed.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N) where N is the number of members to be removed.",
+	},
+}
+
+var smembers commandMetadata = commandMetadata{
+	name: SMEMBERS,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@set", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns all members of a set.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N) where N is the set cardinality.",
+	},
+}
+
+var sismember commandMetadata = commandMetadata{
+	name: SISMEMBER,
+	spec: commandSpec{
+		argCount:      3,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@set", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Determines whether a member belongs to a set.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(1)",
+	},
+}
+
+var smismember commandMetadata = commandMetadata{
+	name: SMISMEMBER,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@set", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Determines whether multiple members belong to a set.",
+		since:      "6.2.0",
+		group:      "set",
+		complexity: "O(N) where N is the number of elements being checked for membership",
+	},
+}
+
+var scard commandMetadata = commandMetadata{
+	name: SCARD,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@set", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the number of members in a set.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(1)",
+	},
+}
+
+var spop commandMetadata = commandMetadata{
+	name: SPOP,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@set", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns one or more random members from a set after removing them. Deletes the set if the last member was popped.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N) where N is the set cardinality.",
+	},
+}
+
+var srandmember commandMetadata = commandMetadata{
+	name: SRANDMEMBER,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@set", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Get one or multiple random members from a set",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N) where N is the set cardinality.",
+	},
+}
+
+var sinter commandMetadata = commandMetadata{
+	name: SINTER,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       -1,
+		steps:         1,
+		aclCategories: []string{"@read", "@set", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the intersect of multiple sets.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N*M) worst case where N is the cardinality of the smallest set and M is the number of sets.",
+	},
+}
+
+var sunion commandMetadata = commandMetadata{
+	name: SUNION,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       -1,
+		steps:         1,
+		aclCategories: []string{"@read", "@set", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the union of multiple sets.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N) where N is the total number of elements in all given sets.",
+	},
+}
+
+var sdiff commandMetadata = commandMetadata{
+	name: SDIFF,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       -1,
+		steps:         1,
+		aclCategories: []string{"@read", "@set", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the difference of multiple sets.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N) where N is the total number of elements in all given sets.",
+	},
+}
+
+var sinterstore commandMetadata = commandMetadata{
+	name: SINTERSTORE,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "denyoom"},
+		firstKey:      1,
+		lastKey:       -1,
+		steps:         1,
+		aclCategories: []string{"@write", "@set", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Stores the intersect of multiple sets in a key.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N*M) worst case where N is the cardinality of the smallest set and M is the number of sets.",
+	},
+}
+
+var sunionstore commandMetadata = commandMetadata{
+	name: SUNIONSTORE,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "denyoom"},
+		firstKey:      1,
+		lastKey:       -1,
+		steps:         1,
+		aclCategories: []string{"@write", "@set", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Stores the union of multiple sets in a key.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N) where N is the total number of elements in all given sets.",
+	},
+}
+
+var sdiffstore commandMetadata = commandMetadata{
+	name: SDIFFSTORE,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "denyoom"},
+		firstKey:      1,
+		lastKey:       -1,
+		steps:         1,
+		aclCategories: []string{"@write", "@set", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Stores the difference of multiple sets in a key.",
+		since:      "1.0.0",
+		group:      "set",
+		complexity: "O(N) where N is the total number of elements in all given sets.",
+	},
+}
+
+var zadd commandMetadata = commandMetadata{
+	name: ZADD,
+	spec: commandSpec{
+		argCount:      -4,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Adds one or more members to a sorted set, or updates their scores. Creates the key if it doesn't exist.",
+		since:      "1.2.0",
+		group:      "sorted-set",
+		complexity: "O(log(N)) for each item added, where N is the number of elements in the sorted set.",
+	},
+}
+
+var zincrby commandMetadata = commandMetadata{
+	name: ZINCRBY,
+	spec: commandSpec{
+		argCount:      4,
+		flags:         []string{"write", "denyoom", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Increments the score of a member in a sorted set.",
+		since:      "1.2.0",
+		group:      "sorted-set",
+		complexity: "O(log(N)) where N is the number of elements in the sorted set.",
+	},
+}
+
+var zrem commandMetadata = commandMetadata{
+	name: ZREM,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Removes one or more members from a sorted set. Deletes the sorted set if all members were removed.",
+		since:      "1.2.0",
+		group:      "sorted-set",
+		complexity: "O(M*log(N)) with N being the number of elements in the sorted set and M the number of elements to be removed.",
+	},
+}
+
+var zcard commandMetadata = commandMetadata{
+	name: ZCARD,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the number of members in a sorted set.",
+		since:      "1.2.0",
+		group:      "sorted-set",
+		complexity: "O(1)",
+	},
+}
+
+var zscore commandMetadata = commandMetadata{
+	name: ZSCORE,
+	spec: commandSpec{
+		argCount:      3,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the score of a member in a sorted set.",
+		since:      "1.2.0",
+		group:      "sorted-set",
+		complexity: "O(1)",
+	},
+}
+
+var zcount commandMetadata = commandMetadata{
+	name: ZCOUNT,
+	spec: commandSpec{
+		argCount:      4,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the count of members in a sorted set that have scores within a range.",
+		since:      "2.0.0",
+		group:      "sorted-set",
+		complexity: "O(log(N)) with N being the number of elements in the sorted set.",
+	},
+}
+
+var zrank commandMetadata = commandMetadata{
+	name: ZRANK,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the index of a member in a sorted set ordered by ascending scores.",
+		since:      "2.0.0",
+		group:      "sorted-set",
+		complexity: "O(log(N))",
+	},
+}
+
+var zrevrank commandMetadata = commandMetadata{
+	name: ZREVRANK,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the index of a member in a sorted set ordered by descending scores.",
+		since:      "2.0.0",
+		group:      "sorted-set",
+		complexity: "O(log(N))",
+	},
+}
+
+var zpopmin commandMetadata = commandMetadata{
+	name: ZPOPMIN,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the lowest-scoring members from a sorted set after removing them. Deletes the sorted set if the last member was popped.",
+		since:      "5.0.0",
+		group:      "sorted-set",
+		complexity: "O(log(N)*M) with N being the number of elements in the sorted set, and M being the number of elements popped.",
+	},
+}
+
+var zpopmax commandMetadata = commandMetadata{
+	name: ZPOPMAX,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@write", "@sortedset", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the highest-scoring members from a sorted set after removing them. Deletes the sorted set if the last member was popped.",
+		since:      "5.0.0",
+		group:      "sorted-set",
+		complexity: "O(log(N)*M) with N being the number of elements in the sorted set, and M being the number of elements popped.",
+	},
+}
+
+var zrange commandMetadata = commandMetadata{
+	name: ZRANGE,
+	spec: commandSpec{
+		argCount:      -4,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@sortedset", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns members in a sorted set within a range of indexes.",
+		since:      "1.2.0",
+		group:      "sorted-set",
+		complexity: "O(log(N)+M) with N being the number of elements in the sorted set and M the number of elements returned.",
+	},
+}
+
+var zrangebyscore commandMetadata = commandMetadata{
+	name: ZRANGEBYSCORE,
+	spec: commandSpec{
+		argCount:      -4,
+		flags:         []string{"readonly"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@read", "@sortedset", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Returns members in a sorted set within a range of scores.",
+		since:      "1.0.5",
+		group:      "sorted-set",
+		complexity: "O(log(N)+M) with N being the number of elements in the sorted set and M the number of elements being returned.",
+	},
+}
+
+var exists commandMetadata = commandMetadata{
+	name: EXISTS,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       -1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@read", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Determines whether one or more keys exist.",
+		since:      "1.0.0",
+		group:      "keyspace",
+		complexity: "O(N) where N is the number of keys to check.",
+	},
+}
+
+// / Named keyType since type is a reserved word
+var keyType commandMetadata = commandMetadata{
+	name: TYPE,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@read", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Determines the type of value stored at a key.",
+		since:      "1.0.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var expire commandMetadata = commandMetadata{
+	name: EXPIRE,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@write", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Set a key's time to live in seconds.",
+		since:      "1.0.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var pexpire commandMetadata = commandMetadata{
+	name: PEXPIRE,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@write", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Set a key's time to live in milliseconds.",
+		since:      "2.6.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var expireat commandMetadata = commandMetadata{
+	name: EXPIREAT,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@write", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Set the expiration for a key as a UNIX timestamp.",
+		since:      "1.2.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var pexpireat commandMetadata = commandMetadata{
+	name: PEXPIREAT,
+	spec: commandSpec{
+		argCount:      -3,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@write", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Set the expiration for a key as a UNIX timestamp specified in milliseconds.",
+		since:      "2.6.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var ttl commandMetadata = commandMetadata{
+	name: TTL,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@read", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Get the time to live for a key in seconds.",
+		since:      "1.0.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var pttl commandMetadata = commandMetadata{
+	name: PTTL,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@read", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Get the time to live for a key in milliseconds.",
+		since:      "2.6.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var expiretime commandMetadata = commandMetadata{
+	name: EXPIRETIME,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@read", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Get the expiration Unix timestamp for a key.",
+		since:      "7.0.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var pexpiretime commandMetadata = commandMetadata{
+	name: PEXPIRETIME,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"readonly", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@read", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Get the expiration Unix timestamp for a key in milliseconds.",
+		since:      "7.0.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var persist commandMetadata = commandMetadata{
+	name: PERSIST,
+	spec: commandSpec{
+		argCount:      2,
+		flags:         []string{"write", "fast"},
+		firstKey:      1,
+		lastKey:       1,
+		steps:         1,
+		aclCategories: []string{"@keyspace", "@write", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Remove the expiration from a key.",
+		since:      "2.2.0",
+		group:      "keyspace",
+		complexity: "O(1)",
+	},
+}
+
+var bgrewriteaof commandMetadata = commandMetadata{
+	name: BGREWRITEAOF,
+	spec: commandSpec{
+		argCount:      1,
+		flags:         []string{"admin", "noscript", "no_async_loading"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@admin", "@slow", "@dangerous"},
+	},
+	doc: commandDoc{
+		summary:    "Asynchronously rewrites the append-only file to disk.",
+		since:      "1.0.0",
+		group:      "server",
+		complexity: "O(1)",
+	},
+}
+
+var save commandMetadata = commandMetadata{
+	name: SAVE,
+	spec: commandSpec{
+		argCount:      1,
+		flags:         []string{"admin", "noscript", "no_async_loading", "no_multi"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@admin", "@slow", "@dangerous"},
+	},
+	doc: commandDoc{
+		summary:    "Synchronously saves the database(s) to disk.",
+		since:      "1.0.0",
+		group:      "server",
+		complexity: "O(N) where N is the total number of keys in all databases",
+	},
+}
+
+var bgsave commandMetadata = commandMetadata{
+	name: BGSAVE,
+	spec: commandSpec{
+		argCount:      1,
+		flags:         []string{"admin", "noscript", "no_async_loading"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@admin", "@slow", "@dangerous"},
+	},
+	doc: commandDoc{
+		summary:    "Asynchronously saves the database(s) to disk.",
+		since:      "1.0.0",
+		group:      "server",
+		complexity: "O(1)",
+	},
+}
+
+var lastsave commandMetadata = commandMetadata{
+	name: LASTSAVE,
+	spec: commandSpec{
+		argCount:      1,
+		flags:         []string{"loading", "stale", "fast"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@admin", "@fast", "@dangerous"},
+	},
+	doc: commandDoc{
+		summary:    "Returns the Unix timestamp of the last successful save to disk.",
+		since:      "1.0.0",
+		group:      "server",
+		complexity: "O(1)",
+	},
+}
+
+var multi commandMetadata = commandMetadata{
+	name: MULTI,
+	spec: commandSpec{
+		argCount:      1,
+		flags:         []string{"noscript", "loading", "stale", "fast", "allow_busy"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@fast", "@transaction"},
+	},
+	doc: commandDoc{
+		summary:    "Starts a transaction.",
+		since:      "1.2.0",
+		group:      "transactions",
+		complexity: "O(1)",
+	},
+}
+
+var exec commandMetadata = commandMetadata{
+	name: EXEC,
+	spec: commandSpec{
+		argCount:      1,
+		flags:         []string{"noscript", "loading", "stale", "skip_slowlog"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@slow", "@transaction"},
+	},
+	doc: commandDoc{
+		summary:    "Executes all commands in a transaction.",
+		since:      "1.2.0",
+		group:      "transactions",
+		complexity: "Depends on commands in the transaction",
+	},
+}
+
+var discard commandMetadata = commandMetadata{
+	name: DISCARD,
+	spec: commandSpec{
+		argCount:      1,
+		flags:         []string{"noscript", "loading", "stale", "fast", "allow_busy"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@fast", "@transaction"},
+	},
+	doc: commandDoc{
+		summary:    "Discards a transaction.",
+		since:      "2.0.0",
+		group:      "transactions",
+		complexity: "O(N) when there are N queued commands",
+	},
+}
+
+var watch commandMetadata = commandMetadata{
+	name: WATCH,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"noscript", "loading", "stale", "fast", "allow_busy"},
+		firstKey:      1,
+		lastKey:       -1,
+		steps:         1,
+		aclCategories: []string{"@fast", "@transaction"},
+	},
+	doc: commandDoc{
+		summary:    "Monitors changes to keys to determine the execution of a transaction.",
+		since:      "2.2.0",
+		group:      "transactions",
+		complexity: "O(1) for every key.",
+	},
+}
+
+var unwatch commandMetadata = commandMetadata{
+	name: UNWATCH,
+	spec: commandSpec{
+		argCount:      1,
+		flags:         []string{"noscript", "loading", "stale", "fast", "allow_busy"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@fast", "@transaction"},
+	},
+	doc: commandDoc{
+		summary:    "Forgets about watched keys of a transaction.",
+		since:      "2.2.0",
+		group:      "transactions",
+		complexity: "O(1)",
+	},
+}
+
+var subscribe commandMetadata = commandMetadata{
+	name: SUBSCRIBE,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"pubsub", "noscript", "loading", "stale"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@pubsub", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Listens for messages published to channels.",
+		since:      "2.0.0",
+		group:      "pubsub",
+		complexity: "O(N) where N is the number of channels to subscribe to.",
+	},
+}
+
+var unsubscribe commandMetadata = commandMetadata{
+	name: UNSUBSCRIBE,
+	spec: commandSpec{
+		argCount:      -1,
+		flags:         []string{"pubsub", "noscript", "loading", "stale"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@pubsub", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Stops listening to messages posted to channels.",
+		since:      "2.0.0",
+		group:      "pubsub",
+		complexity: "O(N) where N is the number of channels to unsubscribe.",
+	},
+}
+
+var psubscribe commandMetadata = commandMetadata{
+	name: PSUBSCRIBE,
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{"pubsub", "noscript", "loading", "stale"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@pubsub", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Listens for messages published to channels that match one or more patterns.",
+		since:      "2.0.0",
+		group:      "pubsub",
+		complexity: "O(N) where N is the number of patterns to subscribe to.",
+	},
+}
+
+var punsubscribe commandMetadata = commandMetadata{
+	name: PUNSUBSCRIBE,
+	spec: commandSpec{
+		argCount:      -1,
+		flags:         []string{"pubsub", "noscript", "loading", "stale"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@pubsub", "@slow"},
+	},
+	doc: commandDoc{
+		summary:    "Stops listening to messages published to channels that match one or more patterns.",
+		since:      "2.0.0",
+		group:      "pubsub",
+		complexity: "O(N) where N is the number of patterns to unsubscribe.",
+	},
+}
+
+var publish commandMetadata = commandMetadata{
+	name: PUBLISH,
+	spec: commandSpec{
+		argCount:      3,
+		flags:         []string{"pubsub", "loading", "stale", "fast"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@pubsub", "@fast"},
+	},
+	doc: commandDoc{
+		summary:    "Posts a message to a channel.",
+		since:      "2.0.0",
+		group:      "pubsub",
+		complexity: "O(N+M) where N is the number of clients subscribed to the receiving channel and M is the total number of subscribed patterns (by any client).",
+	},
+}
+
+var pubsub commandMetadata = commandMetadata{
+	name: PUBSUB,
+	subCommands: []commandMetadata{
+		{
+			name: PUBSUB + " CHANNELS",
+			spec: commandSpec{
+				argCount:      -2,
+				flags:         []string{"pubsub", "loading", "stale"},
+				firstKey:      0,
+				lastKey:       0,
+				steps:         0,
+				aclCategories: []string{"@pubsub", "@slow"},
+			},
+			doc: commandDoc{
+				summary:    "Returns the active channels.",
+				since:      "2.8.0",
+				group:      "pubsub",
+				complexity: "O(N) where N is the number of active channels, and assuming constant time pattern matching (relatively short channels and patterns)",
+			},
+		},
+		{
+			name: PUBSUB + " NUMSUB",
+			spec: commandSpec{
+				argCount:      -2,
+				flags:         []string{"pubsub", "loading", "stale"},
+				firstKey:      0,
+				lastKey:       0,
+				steps:         0,
+				aclCategories: []string{"@pubsub", "@slow"},
+			},
+			doc: commandDoc{
+				summary:    "Returns a count of subscribers to channels.",
+				since:      "2.8.0",
+				group:      "pubsub",
+				complexity: "O(N) for the NUMSUB subcommand, where N is the number of requested channels",
+			},
+		},
+		{
+			name: PUBSUB + " NUMPAT",
+			spec: commandSpec{
+				argCount:      2,
+				flags:         []string{"pubsub", "loading", "stale"},
+				firstKey:      0,
+				lastKey:       0,
+				steps:         0,
+				aclCategories: []string{"@pubsub", "@slow"},
+			},
+			doc: commandDoc{
+				summary:    "Returns a count of unique pattern subscriptions.",
+				since:      "2.8.0",
+				group:      "pubsub",
+				complexity: "O(1)",
+			},
+		},
+	},
+	spec: commandSpec{
+		argCount:      -2,
+		flags:         []string{},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@slow"},
+	},
+	doc: commandDoc{
+		summary:    "A container for Pub/Sub commands.",
+		since:      "2.8.0",
+		group:      "pubsub",
+		complexity: "Depends on subcommand.",
+	},
+}
+
+var hello commandMetadata = commandMetadata{
+	name: HELLO,
+	spec: commandSpec{
+		argCount:      -1,
+		flags:         []string{"noscript", "loading", "stale", "fast", "no_auth"},
+		firstKey:      0,
+		lastKey:       0,
+		steps:         0,
+		aclCategories: []string{"@fast", "@connection"},
+	},
+	doc: commandDoc{
+		summary:    "Handshakes with the server and switches the protocol of the connection.",
+		since:      "6.0.0",
+		group:      "connection",
+		complexity: "O(1)",
+	},
+}
+
 var command commandMetadata = commandMetadata{
 	name: COMMAND,
 	subCommands: []commandMetadata{
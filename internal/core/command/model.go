@@ -33,19 +33,19 @@ type commandDoc struct {
 func (c commandMetadata) specs() []resp.Value {
 	flags := make([]resp.Value, len(c.spec.flags))
 	for i, v := range c.spec.flags {
-		flags[i] = resp.Value{Typ: resp.BULK.Typ, Bulk: v}
+		flags[i] = resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(v)}
 	}
 
 	aclCategories := make([]resp.Value, len(c.spec.aclCategories))
 	for i, v := range c.spec.aclCategories {
-		aclCategories[i] = resp.Value{Typ: resp.BULK.Typ, Bulk: v}
+		aclCategories[i] = resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(v)}
 	}
 
 	commandSpecs := []resp.Value{
 		{
 			Typ: resp.ARRAY.Typ,
 			Array: []resp.Value{
-				{Typ: resp.BULK.Typ, Bulk: c.name},
+				{Typ: resp.BULK.Typ, Bulk: []byte(c.name)},
 				{Typ: resp.INTEGER.Typ, Num: c.spec.argCount},
 				{
 					Typ:   resp.ARRAY.Typ,
@@ -73,44 +73,44 @@ func (c commandMetadata) docs() []resp.Value {
 	docs := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "summary",
+			Bulk: []byte("summary"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: c.doc.summary,
+			Bulk: []byte(c.doc.summary),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "since",
+			Bulk: []byte("since"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: c.doc.since,
+			Bulk: []byte(c.doc.since),
 		},
 
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "group",
+			Bulk: []byte("group"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: c.doc.group,
+			Bulk: []byte(c.doc.group),
 		},
 
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "complexity",
+			Bulk: []byte("complexity"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: c.doc.complexity,
+			Bulk: []byte(c.doc.complexity),
 		},
 	}
 
 	commandDocs := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: c.name,
+			Bulk: []byte(c.name),
 		},
 		{
 			Typ:   resp.ARRAY.Typ,
@@ -124,3 +124,23 @@ func (c commandMetadata) docs() []resp.Value {
 
 	return commandDocs
 }
+
+// / Returns the arity of a command like COMMAND INFO does. It counts the command name as well.
+// / A positive arity is the exact amount of arguments, a negative one the minimum
+func Arity(name string) (int, bool) {
+	for _, c := range commandMetadatas {
+		if c.name == name {
+			return c.spec.argCount, true
+		}
+	}
+
+	return 0, false
+}
+
+// / Returns true if a command with argCount arguments, including its name, fulfills the arity
+func MatchesArity(arity int, argCount int) bool {
+	if arity < 0 {
+		return argCount >= -arity
+	}
+	return argCount == arity
+}
@@ -0,0 +1,101 @@
+package command
+
+import (
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"log"
+)
+
+// / Rewrites the AOF in the background, so it only contains the commands needed to recreate the current state
+// / BGREWRITEAOF
+// / Example:
+// / Req: BGREWRITEAOF
+// / Res: Background append only file rewriting started
+func bgrewriteaofStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 0 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'bgrewriteaof' command"}
+	}
+
+	done, err := db.RewriteAof()
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	go func() {
+		if err := <-done; err != nil {
+			log.Println("Background AOF rewrite failed: " + err.Error())
+			return
+		}
+		log.Println("Background AOF rewrite finished successfully")
+	}()
+
+	return resp.Value{Typ: resp.STRING.Typ, Str: "Background append only file rewriting started"}
+}
+
+// / Takes a snapshot of the current state and waits until it is written
+// / SAVE
+// / Example:
+// / Req: SAVE
+// / Res: OK
+func saveStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 0 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'save' command"}
+	}
+
+	done, err := db.SaveSnapshot()
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+	if err := <-done; err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR " + err.Error()}
+	}
+
+	return okResponse
+}
+
+// / Takes a snapshot of the current state in the background
+// / BGSAVE
+// / Example:
+// / Req: BGSAVE
+// / Res: Background saving started
+func bgsaveStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 0 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'bgsave' command"}
+	}
+
+	done, err := db.SaveSnapshot()
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	go func() {
+		if err := <-done; err != nil {
+			log.Println("Background saving failed: " + err.Error())
+			return
+		}
+		log.Println("Background saving finished successfully")
+	}()
+
+	return resp.Value{Typ: resp.STRING.Typ, Str: "Background saving started"}
+}
+
+// / Returns the unix time in seconds of the last successful snapshot
+// / LASTSAVE
+// / Example:
+// / Req: LASTSAVE
+// / Res: 1700000000
+func lastsaveStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 0 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'lastsave' command"}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: int(db.LastSnapshot().Unix())}
+}
@@ -0,0 +1,382 @@
+package command
+
+import (
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"log"
+	"strconv"
+)
+
+// / Adds the members to the set stored at key. Creates the set if it does not exist
+// / SADD {key} {member1} [{member2}...]
+// / Example:
+// / Req: SADD tira misu cute
+// / Res: (integer) 2
+func saddStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'sadd' command"}
+	}
+
+	amountAdded, err := db.AddToSet(request, string(args[0].Bulk), bulkStrings(args[1:]))
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: amountAdded}
+}
+
+// / Removes the members from the set stored at key. Deletes the set if it is empty afterwards
+// / SREM {key} {member1} [{member2}...]
+// / Example:
+// / Req: SREM tira misu
+// / Res: (integer) 1
+func sremStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'srem' command"}
+	}
+
+	amountRemoved, err := db.RemoveFromSet(request, string(args[0].Bulk), bulkStrings(args[1:]))
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: amountRemoved}
+}
+
+// / Returns all members of the set stored at key
+// / SMEMBERS {key}
+// / Example:
+// / Req: SMEMBERS tira
+// / Res:
+// / misu
+// / cute
+func smembersStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'smembers' command"}
+	}
+
+	members, err := db.GetSetMembers(string(args[0].Bulk))
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		return bulkSet([]string{})
+	}
+
+	return bulkSet(members)
+}
+
+// / Returns 1 if member is part of the set stored at key, otherwise 0
+// / SISMEMBER {key} {member}
+// / Example:
+// / Req: SISMEMBER tira misu
+// / Res: (integer) 1
+func sismemberStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'sismember' command"}
+	}
+
+	result, err := db.IsSetMember(string(args[0].Bulk), []string{string(args[1].Bulk)})
+	if err != nil {
+		return errorValue(err)
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: boolToInt(result[0])}
+}
+
+// / Returns for every member whether it is part of the set stored at key
+// / SMISMEMBER {key} {member1} [{member2}...]
+// / Example:
+// / Req: SMISMEMBER tira misu void
+// / Res:
+// / (integer) 1
+// / (integer) 0
+func smismemberStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'smismember' command"}
+	}
+
+	result, err := db.IsSetMember(string(args[0].Bulk), bulkStrings(args[1:]))
+	if err != nil {
+		return errorValue(err)
+	}
+
+	values := make([]resp.Value, len(result))
+	for i, isMember := range result {
+		values[i] = resp.Value{Typ: resp.INTEGER.Typ, Num: boolToInt(isMember)}
+	}
+
+	return resp.Value{Typ: resp.ARRAY.Typ, Array: values}
+}
+
+// / Returns the amount of members of the set stored at key
+// / SCARD {key}
+// / Example:
+// / Req: SCARD tira
+// / Res: (integer) 2
+func scardStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'scard' command"}
+	}
+
+	length, err := db.GetSetLength(string(args[0].Bulk))
+	if err != nil {
+		return errorValue(err)
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: length}
+}
+
+// / Removes and returns random members of the set stored at key
+// / SPOP {key} [{count}]
+// / Example:
+// / Req: SPOP tira
+// / Res: misu
+func spopStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'spop' command"}
+	}
+
+	key := string(args[0].Bulk)
+
+	count := 1
+	if len(args) == 2 {
+		parsed, err := strconv.Atoi(string(args[1].Bulk))
+		if err != nil || parsed < 0 {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is out of range, must be positive"}
+		}
+		count = parsed
+	}
+
+	popped, err := db.PopSet(key, count)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		log.Printf("Did not find any set with key %s\n", key)
+		if len(args) == 2 {
+			return bulkSet([]string{})
+		}
+		return resp.Value{Typ: resp.NULL.Typ}
+	}
+
+	if len(args) == 1 {
+		return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(popped[0])}
+	}
+
+	return bulkSet(popped)
+}
+
+// / A negative count makes SRANDMEMBER reply with exactly that many members, so larger counts are rejected instead of allocating without bound
+const maxRandomMembers = 16 * 1024 * 1024
+
+// / Returns random members of the set stored at key without removing them. A negative count allows duplicates
+// / SRANDMEMBER {key} [{count}]
+// / Example:
+// / Req: SRANDMEMBER tira
+// / Res: misu
+func srandmemberStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'srandmember' command"}
+	}
+
+	key := string(args[0].Bulk)
+
+	count := 1
+	if len(args) == 2 {
+		parsed, err := strconv.Atoi(string(args[1].Bulk))
+		if err != nil {
+			return notAnIntegerError()
+		}
+		if parsed < -maxRandomMembers {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is out of range"}
+		}
+		count = parsed
+	}
+
+	members, err := db.GetRandomSetMembers(key, count)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		log.Printf("Did not find any set with key %s\n", key)
+		if len(args) == 2 {
+			return bulkArray([]string{})
+		}
+		return resp.Value{Typ: resp.NULL.Typ}
+	}
+
+	if len(args) == 1 {
+		return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(members[0])}
+	}
+
+	return bulkArray(members)
+}
+
+// / Returns the members that are part of every given set
+// / SINTER {key1} [{key2}...]
+// / Example:
+// / Req: SINTER tira misu
+// / Res: cute
+func sinterStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setAlgebra(request, db, intersect, "sinter")
+}
+
+// / Returns the members that are part of any given set
+// / SUNION {key1} [{key2}...]
+// / Example:
+// / Req: SUNION tira misu
+// / Res:
+// / cute
+// / scary
+func sunionStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setAlgebra(request, db, union, "sunion")
+}
+
+// / Returns the members of the first set that are not part of any of the following sets
+// / SDIFF {key1} [{key2}...]
+// / Example:
+// / Req: SDIFF tira misu
+// / Res: scary
+func sdiffStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setAlgebra(request, db, difference, "sdiff")
+}
+
+// / Same as SINTER, but stores the result in destination. Returns the size of the stored set
+// / SINTERSTORE {destination} {key1} [{key2}...]
+// / Example:
+// / Req: SINTERSTORE result tira misu
+// / Res: (integer) 1
+func sinterstoreStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setAlgebraStore(request, db, intersect, "sinterstore")
+}
+
+// / Same as SUNION, but stores the result in destination. Returns the size of the stored set
+// / SUNIONSTORE {destination} {key1} [{key2}...]
+// / Example:
+// / Req: SUNIONSTORE result tira misu
+// / Res: (integer) 2
+func sunionstoreStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setAlgebraStore(request, db, union, "sunionstore")
+}
+
+// / Same as SDIFF, but stores the result in destination. Returns the size of the stored set
+// / SDIFFSTORE {destination} {key1} [{key2}...]
+// / Example:
+// / Req: SDIFFSTORE result tira misu
+// / Res: (integer) 1
+func sdiffstoreStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return setAlgebraStore(request, db, difference, "sdiffstore")
+}
+
+type setOperation = func(sets [][]string) []string
+
+func setAlgebra(request resp.Value, db persistence.Database, operation setOperation, name string) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 1 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
+	}
+
+	sets, err := loadSets(db, args)
+	if err != nil {
+		return errorValue(err)
+	}
+
+	return bulkSet(operation(sets))
+}
+
+func setAlgebraStore(request resp.Value, db persistence.Database, operation setOperation, name string) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
+	}
+
+	size, err := db.StoreSet(request, string(args[0].Bulk), bulkStrings(args[1:]), operation)
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: size}
+}
+
+// / Missing keys are treated as empty sets
+func loadSets(db persistence.Database, keys []resp.Value) ([][]string, error) {
+	sets := make([][]string, len(keys))
+	for i, key := range keys {
+		members, err := db.GetSetMembers(string(key.Bulk))
+		if isWrongType(err) {
+			return nil, err
+		}
+		if err != nil {
+			members = []string{}
+		}
+		sets[i] = members
+	}
+	return sets, nil
+}
+
+func intersect(sets [][]string) []string {
+	counts := map[string]int{}
+	for _, set := range sets {
+		for _, member := range set {
+			counts[member]++
+		}
+	}
+
+	result := []string{}
+	for member, count := range counts {
+		if count == len(sets) {
+			result = append(result, member)
+		}
+	}
+	return result
+}
+
+func union(sets [][]string) []string {
+	seen := map[string]struct{}{}
+	result := []string{}
+	for _, set := range sets {
+		for _, member := range set {
+			if _, ok := seen[member]; !ok {
+				seen[member] = struct{}{}
+				result = append(result, member)
+			}
+		}
+	}
+	return result
+}
+
+func difference(sets [][]string) []string {
+	excluded := map[string]struct{}{}
+	for _, set := range sets[1:] {
+		for _, member := range set {
+			excluded[member] = struct{}{}
+		}
+	}
+
+	result := []string{}
+	for _, member := range sets[0] {
+		if _, ok := excluded[member]; !ok {
+			result = append(result, member)
+		}
+	}
+	return result
+}
@@ -0,0 +1,504 @@
+package command
+
+import (
+	"gocache/internal/core/resp"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sadd(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 1,
+	}
+
+	sadd, ok := Strategies[SADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sadd(request(SADD, bulks("tira", "misu", "cute", "cute")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	members, err := db.GetSetMembers("tira")
+	if err != nil {
+		t.Error("Set Storage did not contain key 'tira'")
+		return
+	}
+	assert.ElementsMatch(t, []string{"misu", "cute"}, members)
+}
+
+func Test_sadd_needsAtLeastTwoArgs(t *testing.T) {
+	// given
+	sadd, ok := Strategies[SADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sadd(request(SADD, bulks("tira")), defaultDb())
+
+	// then
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
+}
+
+func Test_srem_lastMemberDeletesSet(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 1,
+	}
+
+	srem, ok := Strategies[SREM]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := srem(request(SREM, bulks("tira", "misu", "void")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	_, err := db.GetSetMembers("tira")
+	if err == nil {
+		t.Error("Set Storage did not get key 'tira' deleted")
+	}
+}
+
+func Test_smembers(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute"})
+
+	smembers, ok := Strategies[SMEMBERS]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := smembers(request(SMEMBERS, bulks("tira")), db)
+
+	// then
+	assert.Equal(t, resp.SET.Typ, result.Typ)
+	assert.ElementsMatch(t, bulks("misu", "cute"), result.Array)
+}
+
+func Test_sismember(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 1,
+	}
+
+	sismember, ok := Strategies[SISMEMBER]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sismember(request(SISMEMBER, bulks("tira", "misu")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_smismember(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+
+	expected := resp.Value{
+		Typ: resp.ARRAY.Typ,
+		Array: []resp.Value{
+			{Typ: resp.INTEGER.Typ, Num: 1},
+			{Typ: resp.INTEGER.Typ, Num: 0},
+		},
+	}
+
+	smismember, ok := Strategies[SMISMEMBER]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := smismember(request(SMISMEMBER, bulks("tira", "misu", "void")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_scard(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	scard, ok := Strategies[SCARD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := scard(request(SCARD, bulks("tira")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_spop_withCount(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute", "void"})
+
+	spop, ok := Strategies[SPOP]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := spop(request(SPOP, bulks("tira", "2")), db)
+
+	// then
+	assert.Len(t, result.Array, 2)
+	length, _ := db.GetSetLength("tira")
+	assert.Equal(t, 1, length)
+
+	for _, popped := range result.Array {
+		isMember, _ := db.IsSetMember("tira", []string{string(popped.Bulk)})
+		assert.False(t, isMember[0])
+	}
+}
+
+func Test_spop_noValueAvailable(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.NULL.Typ,
+	}
+
+	spop, ok := Strategies[SPOP]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := spop(request(SPOP, bulks("tira")), defaultDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_srandmember_doesNotRemove(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute"})
+
+	srandmember, ok := Strategies[SRANDMEMBER]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := srandmember(request(SRANDMEMBER, bulks("tira", "5")), db)
+
+	// then
+	assert.ElementsMatch(t, bulks("misu", "cute"), result.Array)
+	length, _ := db.GetSetLength("tira")
+	assert.Equal(t, 2, length)
+}
+
+func Test_srandmember_negativeCountAllowsDuplicates(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+
+	srandmember, ok := Strategies[SRANDMEMBER]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := srandmember(request(SRANDMEMBER, bulks("tira", "-3")), db)
+
+	// then
+	assert.Equal(t, bulks("misu", "misu", "misu"), result.Array)
+}
+
+func Test_srandmember_countOutOfRange(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+
+	srandmember, ok := Strategies[SRANDMEMBER]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	tests := []string{strconv.FormatInt(math.MinInt64, 10), strconv.Itoa(-maxRandomMembers - 1)}
+
+	for _, count := range tests {
+		t.Run(count, func(t *testing.T) {
+			// when
+			result := srandmember(request(SRANDMEMBER, bulks("tira", count)), db)
+
+			// then
+			assert.Equal(t, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is out of range"}, result)
+		})
+	}
+}
+
+func Test_srandmember_returnsDistinctMembers(t *testing.T) {
+	// given
+	db := defaultDb()
+	members := make([]string, 100)
+	for i := range members {
+		members[i] = strconv.Itoa(i)
+	}
+	db.AddToSet(resp.Value{}, "tira", members)
+
+	srandmember, ok := Strategies[SRANDMEMBER]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// counts below and above the point where the whole set is copied
+	for _, count := range []int{1, 10, 50, 99} {
+		t.Run(strconv.Itoa(count), func(t *testing.T) {
+			// when
+			result := srandmember(request(SRANDMEMBER, bulks("tira", strconv.Itoa(count))), db)
+
+			// then
+			assert.Len(t, result.Array, count)
+			seen := map[string]bool{}
+			for _, member := range result.Array {
+				assert.Contains(t, members, string(member.Bulk))
+				assert.False(t, seen[string(member.Bulk)])
+				seen[string(member.Bulk)] = true
+			}
+		})
+	}
+}
+
+func Test_spop_popsEveryMemberOnce(t *testing.T) {
+	// given
+	db := defaultDb()
+	members := make([]string, 100)
+	for i := range members {
+		members[i] = strconv.Itoa(i)
+	}
+	db.AddToSet(resp.Value{}, "tira", members)
+
+	spop, ok := Strategies[SPOP]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	popped := []string{}
+	for range members {
+		popped = append(popped, string(spop(request(SPOP, bulks("tira")), db).Bulk))
+	}
+
+	// then
+	assert.ElementsMatch(t, members, popped)
+	_, err := db.GetSetMembers("tira")
+	assert.Error(t, err)
+}
+
+func Test_sinter(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute"})
+	db.AddToSet(resp.Value{}, "void", []string{"cute", "scary"})
+
+	sinter, ok := Strategies[SINTER]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sinter(request(SINTER, bulks("tira", "void")), db)
+
+	// then
+	assert.ElementsMatch(t, bulks("cute"), result.Array)
+}
+
+func Test_sinter_missingKeyIsEmptySet(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute"})
+
+	sinter, ok := Strategies[SINTER]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sinter(request(SINTER, bulks("tira", "void")), db)
+
+	// then
+	assert.Empty(t, result.Array)
+}
+
+func Test_sunion(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute"})
+	db.AddToSet(resp.Value{}, "void", []string{"cute", "scary"})
+
+	sunion, ok := Strategies[SUNION]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sunion(request(SUNION, bulks("tira", "void")), db)
+
+	// then
+	assert.ElementsMatch(t, bulks("misu", "cute", "scary"), result.Array)
+}
+
+func Test_sdiff(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute"})
+	db.AddToSet(resp.Value{}, "void", []string{"cute", "scary"})
+
+	sdiff, ok := Strategies[SDIFF]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sdiff(request(SDIFF, bulks("tira", "void")), db)
+
+	// then
+	assert.ElementsMatch(t, bulks("misu"), result.Array)
+}
+
+func Test_sunionstore_replacesDestination(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+	db.AddToSet(resp.Value{}, "void", []string{"cute"})
+	db.AddToSet(resp.Value{}, "result", []string{"scary"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	sunionstore, ok := Strategies[SUNIONSTORE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sunionstore(request(SUNIONSTORE, bulks("result", "tira", "void")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	members, err := db.GetSetMembers("result")
+	if err != nil {
+		t.Error("Set Storage did not contain key 'result'")
+		return
+	}
+	assert.ElementsMatch(t, []string{"misu", "cute"}, members)
+}
+
+func Test_sinterstore_emptyResultDeletesDestination(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+	db.AddToSet(resp.Value{}, "void", []string{"cute"})
+	db.AddToSet(resp.Value{}, "result", []string{"scary"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 0,
+	}
+
+	sinterstore, ok := Strategies[SINTERSTORE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sinterstore(request(SINTERSTORE, bulks("result", "tira", "void")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	_, err := db.GetSetMembers("result")
+	if err == nil {
+		t.Error("Set Storage did not get key 'result' deleted")
+	}
+}
+
+func Test_sdiffstore(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSet(resp.Value{}, "tira", []string{"misu", "cute"})
+	db.AddToSet(resp.Value{}, "void", []string{"cute"})
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 1,
+	}
+
+	sdiffstore, ok := Strategies[SDIFFSTORE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := sdiffstore(request(SDIFFSTORE, bulks("result", "tira", "void")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	members, _ := db.GetSetMembers("result")
+	assert.Equal(t, []string{"misu"}, members)
+}
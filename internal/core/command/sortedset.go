@@ -0,0 +1,550 @@
+package command
+
+import (
+	"errors"
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// / Adds members with their scores to the sorted set stored at key, or updates the score of existing members
+// / ZADD {key} [NX|XX] [GT|LT] [CH] [INCR] {score1} {member1} [{score2} {member2}...]
+// / Example:
+// / Req: ZADD tira 1 misu 2 cute
+// / Res: (integer) 2
+func zaddStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'zadd' command"}
+	}
+
+	key := string(args[0].Bulk)
+
+	options := persistence.SortedSetAddOptions{}
+	increment := false
+
+	i := 1
+parseOptions:
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i].Bulk)) {
+		case "NX":
+			options.OnlyNew = true
+		case "XX":
+			options.OnlyExisting = true
+		case "GT":
+			options.OnlyGreater = true
+		case "LT":
+			options.OnlyLess = true
+		case "CH":
+			options.CountChanged = true
+		case "INCR":
+			increment = true
+		default:
+			break parseOptions
+		}
+	}
+
+	if options.OnlyNew && options.OnlyExisting {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR XX and NX options at the same time are not compatible"}
+	}
+	if options.OnlyGreater && options.OnlyLess || options.OnlyNew && (options.OnlyGreater || options.OnlyLess) {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR GT, LT, and/or NX options at the same time are not compatible"}
+	}
+
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}
+	}
+	if increment && len(pairs) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR INCR option supports a single increment-element pair"}
+	}
+
+	members := make([]persistence.ScoredMember, 0, len(pairs)/2)
+	for j := 0; j < len(pairs); j += 2 {
+		score, err := parseScore(string(pairs[j].Bulk))
+		if err != nil {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+		}
+		members = append(members, persistence.ScoredMember{Member: string(pairs[j+1].Bulk), Score: score})
+	}
+
+	if increment {
+		score, applied, err := db.IncrementSortedSetScore(request, key, members[0].Member, members[0].Score, options)
+		if err != nil {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+		}
+		if !applied {
+			return resp.Value{Typ: resp.NULL.Typ}
+		}
+		return resp.Value{Typ: resp.DOUBLE.Typ, Double: score}
+	}
+
+	amount, err := db.AddToSortedSet(request, key, members, options)
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: amount}
+}
+
+// / Increments the score of member in the sorted set stored at key. Creates the member if it does not exist
+// / ZINCRBY {key} {increment} {member}
+// / Example:
+// / Req: ZINCRBY tira 2.5 misu
+// / Res: 3.5
+func zincrbyStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'zincrby' command"}
+	}
+
+	increment, err := parseScore(string(args[1].Bulk))
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	score, _, err := db.IncrementSortedSetScore(request, string(args[0].Bulk), string(args[2].Bulk), increment, persistence.SortedSetAddOptions{})
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.DOUBLE.Typ, Double: score}
+}
+
+// / Removes the members from the sorted set stored at key. Deletes the sorted set if it is empty afterwards
+// / ZREM {key} {member1} [{member2}...]
+// / Example:
+// / Req: ZREM tira misu
+// / Res: (integer) 1
+func zremStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'zrem' command"}
+	}
+
+	amountRemoved, err := db.RemoveFromSortedSet(request, string(args[0].Bulk), bulkStrings(args[1:]))
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: amountRemoved}
+}
+
+// / Returns the amount of members of the sorted set stored at key
+// / ZCARD {key}
+// / Example:
+// / Req: ZCARD tira
+// / Res: (integer) 2
+func zcardStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'zcard' command"}
+	}
+
+	length, err := db.GetSortedSetLength(string(args[0].Bulk))
+	if err != nil {
+		return errorValue(err)
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: length}
+}
+
+// / Returns the score of member in the sorted set stored at key
+// / ZSCORE {key} {member}
+// / Example:
+// / Req: ZSCORE tira misu
+// / Res: 1.5
+func zscoreStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'zscore' command"}
+	}
+
+	score, err := db.GetSortedSetScore(string(args[0].Bulk), string(args[1].Bulk))
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return resp.Value{Typ: resp.NULL.Typ}
+	}
+
+	return resp.Value{Typ: resp.DOUBLE.Typ, Double: score}
+}
+
+// / Returns the amount of members with a score between min and max. Prefixing a bound with ( makes it exclusive
+// / ZCOUNT {key} {min} {max}
+// / Example:
+// / Req: ZCOUNT tira (1 +inf
+// / Res: (integer) 1
+func zcountStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'zcount' command"}
+	}
+
+	min, err := parseScoreBound(string(args[1].Bulk))
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+	max, err := parseScoreBound(string(args[2].Bulk))
+	if err != nil {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+	}
+
+	amount, err := db.CountSortedSetByScore(string(args[0].Bulk), min, max)
+	if err != nil {
+		return errorValue(err)
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: amount}
+}
+
+// / Returns the 0 based rank of member, ordered from the lowest to the highest score
+// / ZRANK {key} {member} [WITHSCORE]
+// / Example:
+// / Req: ZRANK tira misu
+// / Res: (integer) 0
+func zrankStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return rank(request, db, false, "zrank")
+}
+
+// / Returns the 0 based rank of member, ordered from the highest to the lowest score
+// / ZREVRANK {key} {member} [WITHSCORE]
+// / Example:
+// / Req: ZREVRANK tira misu
+// / Res: (integer) 1
+func zrevrankStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return rank(request, db, true, "zrevrank")
+}
+
+func rank(request resp.Value, db persistence.Database, reverse bool, name string) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 2 && len(args) != 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
+	}
+
+	withScore := len(args) == 3
+	if withScore && strings.ToUpper(string(args[2].Bulk)) != "WITHSCORE" {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}
+	}
+
+	rank, score, err := db.GetSortedSetRank(string(args[0].Bulk), string(args[1].Bulk), reverse)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return resp.Value{Typ: resp.NULL.Typ}
+	}
+
+	if withScore {
+		return resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{
+			{Typ: resp.INTEGER.Typ, Num: rank},
+			{Typ: resp.DOUBLE.Typ, Double: score},
+		}}
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: rank}
+}
+
+// / Removes and returns the members with the lowest scores
+// / ZPOPMIN {key} [{count}]
+// / Example:
+// / Req: ZPOPMIN tira
+// / Res:
+// / misu
+// / 1
+func zpopminStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return popSorted(request, db, false, "zpopmin")
+}
+
+// / Removes and returns the members with the highest scores
+// / ZPOPMAX {key} [{count}]
+// / Example:
+// / Req: ZPOPMAX tira
+// / Res:
+// / cute
+// / 2
+func zpopmaxStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return popSorted(request, db, true, "zpopmax")
+}
+
+func popSorted(request resp.Value, db persistence.Database, highest bool, name string) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) != 1 && len(args) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
+	}
+
+	count := 1
+	if len(args) == 2 {
+		parsed, err := strconv.Atoi(string(args[1].Bulk))
+		if err != nil || parsed < 0 {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is out of range, must be positive"}
+		}
+		count = parsed
+	}
+
+	popped, err := db.PopSortedSet(request, string(args[0].Bulk), count, highest)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		return scoredArray([]persistence.ScoredMember{}, true)
+	}
+
+	return scoredArray(popped, true)
+}
+
+// / Returns a range of members of the sorted set stored at key. By default the range is by 0 based rank,
+// / with BYSCORE by score and with BYLEX lexicographically by member
+// / ZRANGE {key} {start} {stop} [BYSCORE|BYLEX] [REV] [LIMIT {offset} {count}] [WITHSCORES]
+// / Example:
+// / Req: ZRANGE tira 0 -1 WITHSCORES
+// / Res:
+// / misu
+// / 1
+// / cute
+// / 2
+func zrangeStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'zrange' command"}
+	}
+
+	query := rangeQuery{key: string(args[0].Bulk), start: string(args[1].Bulk), stop: string(args[2].Bulk), count: -1}
+
+	extraArgs := args[3:]
+	for i := 0; i < len(extraArgs); i++ {
+		switch strings.ToUpper(string(extraArgs[i].Bulk)) {
+		case "BYSCORE":
+			query.by = "score"
+		case "BYLEX":
+			query.by = "lex"
+		case "REV":
+			query.reverse = true
+		case "WITHSCORES":
+			query.withScores = true
+		case "LIMIT":
+			if i+2 >= len(extraArgs) {
+				return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}
+			}
+			if err := query.parseLimit(string(extraArgs[i+1].Bulk), string(extraArgs[i+2].Bulk)); err != nil {
+				return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+			}
+			query.limited = true
+			i += 2
+		default:
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}
+		}
+	}
+
+	if query.limited && query.by == "" {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"}
+	}
+	if query.withScores && query.by == "lex" {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error, WITHSCORES not supported in combination with BYLEX"}
+	}
+
+	// with REV the range is given from the highest to the lowest value
+	if query.reverse && query.by != "" {
+		query.start, query.stop = query.stop, query.start
+	}
+
+	return query.execute(db)
+}
+
+// / Returns the members with a score between min and max, ordered from the lowest to the highest score
+// / ZRANGEBYSCORE {key} {min} {max} [WITHSCORES] [LIMIT {offset} {count}]
+// / Example:
+// / Req: ZRANGEBYSCORE tira -inf (2
+// / Res: misu
+func zrangebyscoreStrategy(request resp.Value, db persistence.Database) resp.Value {
+	args := request.GetArgs()
+
+	if len(args) < 3 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'zrangebyscore' command"}
+	}
+
+	query := rangeQuery{key: string(args[0].Bulk), start: string(args[1].Bulk), stop: string(args[2].Bulk), by: "score", count: -1}
+
+	extraArgs := args[3:]
+	for i := 0; i < len(extraArgs); i++ {
+		switch strings.ToUpper(string(extraArgs[i].Bulk)) {
+		case "WITHSCORES":
+			query.withScores = true
+		case "LIMIT":
+			if i+2 >= len(extraArgs) {
+				return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}
+			}
+			if err := query.parseLimit(string(extraArgs[i+1].Bulk), string(extraArgs[i+2].Bulk)); err != nil {
+				return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+			}
+			i += 2
+		default:
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR syntax error"}
+		}
+	}
+
+	return query.execute(db)
+}
+
+type rangeQuery struct {
+	key        string
+	start      string
+	stop       string
+	by         string
+	reverse    bool
+	withScores bool
+	limited    bool
+	offset     int
+	count      int
+}
+
+func (q *rangeQuery) parseLimit(rawOffset string, rawCount string) error {
+	offset, err := strconv.Atoi(rawOffset)
+	if err != nil {
+		return errors.New("ERR value is not an integer or out of range")
+	}
+	count, err := strconv.Atoi(rawCount)
+	if err != nil {
+		return errors.New("ERR value is not an integer or out of range")
+	}
+
+	q.offset = offset
+	q.count = count
+	return nil
+}
+
+func (q *rangeQuery) execute(db persistence.Database) resp.Value {
+	var members []persistence.ScoredMember
+	var err error
+
+	switch q.by {
+	case "score":
+		min, parseErr := parseScoreBound(q.start)
+		if parseErr != nil {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: parseErr.Error()}
+		}
+		max, parseErr := parseScoreBound(q.stop)
+		if parseErr != nil {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: parseErr.Error()}
+		}
+
+		// a negative offset returns an empty result
+		if q.offset < 0 {
+			return scoredArray([]persistence.ScoredMember{}, q.withScores)
+		}
+		members, err = db.GetSortedSetRangeByScore(q.key, min, max, q.reverse, q.offset, q.count)
+	case "lex":
+		min, parseErr := parseLexBound(q.start)
+		if parseErr != nil {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: parseErr.Error()}
+		}
+		max, parseErr := parseLexBound(q.stop)
+		if parseErr != nil {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: parseErr.Error()}
+		}
+
+		if q.offset < 0 {
+			return scoredArray([]persistence.ScoredMember{}, q.withScores)
+		}
+		members, err = db.GetSortedSetRangeByLex(q.key, min, max, q.reverse, q.offset, q.count)
+	default:
+		start, parseErr := strconv.Atoi(q.start)
+		if parseErr != nil {
+			return notAnIntegerError()
+		}
+		stop, parseErr := strconv.Atoi(q.stop)
+		if parseErr != nil {
+			return notAnIntegerError()
+		}
+
+		members, err = db.GetSortedSetRange(q.key, start, stop, q.reverse)
+	}
+
+	if isWrongType(err) {
+		return errorValue(err)
+	}
+	if err != nil {
+		return scoredArray([]persistence.ScoredMember{}, q.withScores)
+	}
+
+	return scoredArray(members, q.withScores)
+}
+
+func scoredArray(members []persistence.ScoredMember, withScores bool) resp.Value {
+	values := make([]resp.Value, 0, len(members)*2)
+	for _, m := range members {
+		values = append(values, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(m.Member)})
+		if withScores {
+			values = append(values, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(formatScore(m.Score))})
+		}
+	}
+
+	return resp.Value{Typ: resp.ARRAY.Typ, Array: values}
+}
+
+func parseScore(raw string) (float64, error) {
+	score, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, errors.New("ERR value is not a valid float")
+	}
+	return score, nil
+}
+
+func parseScoreBound(raw string) (persistence.ScoreBound, error) {
+	bound := persistence.ScoreBound{}
+
+	if strings.HasPrefix(raw, "(") {
+		bound.Exclusive = true
+		raw = raw[1:]
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) {
+		return bound, errors.New("ERR min or max is not a float")
+	}
+	bound.Value = value
+
+	return bound, nil
+}
+
+func parseLexBound(raw string) (persistence.LexBound, error) {
+	switch {
+	case raw == "-":
+		return persistence.LexBound{Infinity: -1}, nil
+	case raw == "+":
+		return persistence.LexBound{Infinity: 1}, nil
+	case strings.HasPrefix(raw, "["):
+		return persistence.LexBound{Value: raw[1:]}, nil
+	case strings.HasPrefix(raw, "("):
+		return persistence.LexBound{Value: raw[1:], Exclusive: true}, nil
+	default:
+		return persistence.LexBound{}, errors.New("ERR min or max not valid string range item")
+	}
+}
+
+// / Formats scores the same way redis does, e.g. 1 instead of 1.0 and inf instead of +Inf
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(score, 'g', -1, 64)
+	}
+}
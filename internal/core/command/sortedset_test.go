@@ -0,0 +1,547 @@
+package command
+
+import (
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sortedSetDb() persistence.Database {
+	db := defaultDb()
+	db.AddToSortedSet(resp.Value{}, "tira", []persistence.ScoredMember{
+		{Member: "misu", Score: 1},
+		{Member: "cute", Score: 2},
+		{Member: "void", Score: 3},
+	}, persistence.SortedSetAddOptions{})
+	return db
+}
+
+func Test_zadd(t *testing.T) {
+	// given
+	db := defaultDb()
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zadd(request(ZADD, bulks("tira", "1.5", "misu", "2", "cute")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	score, err := db.GetSortedSetScore("tira", "misu")
+	if err != nil {
+		t.Error("Sorted Set Storage did not contain member 'misu'")
+		return
+	}
+	assert.Equal(t, 1.5, score)
+}
+
+func Test_zadd_chCountsUpdates(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zadd(request(ZADD, bulks("tira", "CH", "5", "misu", "2", "cute", "4", "scary")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zadd_nxDoesNotUpdate(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zadd(request(ZADD, bulks("tira", "NX", "5", "misu")), db)
+
+	// then
+	assert.Equal(t, 0, result.Num)
+	score, _ := db.GetSortedSetScore("tira", "misu")
+	assert.Equal(t, 1.0, score)
+}
+
+func Test_zadd_xxDoesNotAdd(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	zadd(request(ZADD, bulks("tira", "XX", "5", "misu", "5", "scary")), db)
+
+	// then
+	score, _ := db.GetSortedSetScore("tira", "misu")
+	assert.Equal(t, 5.0, score)
+	_, err := db.GetSortedSetScore("tira", "scary")
+	assert.NotNil(t, err)
+}
+
+func Test_zadd_gtOnlyIncreases(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	zadd(request(ZADD, bulks("tira", "GT", "0", "misu", "5", "cute")), db)
+
+	// then
+	misu, _ := db.GetSortedSetScore("tira", "misu")
+	cute, _ := db.GetSortedSetScore("tira", "cute")
+	assert.Equal(t, 1.0, misu)
+	assert.Equal(t, 5.0, cute)
+}
+
+func Test_zadd_incr(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	expected := resp.Value{
+		Typ:    resp.DOUBLE.Typ,
+		Double: 3.5,
+	}
+
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zadd(request(ZADD, bulks("tira", "INCR", "2.5", "misu")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zadd_incrAbortedReturnsNull(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	expected := resp.Value{
+		Typ: resp.NULL.Typ,
+	}
+
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zadd(request(ZADD, bulks("tira", "NX", "INCR", "2.5", "misu")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zadd_incompatibleOptions_err(t *testing.T) {
+	// given
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zadd(request(ZADD, bulks("tira", "NX", "XX", "1", "misu")), defaultDb())
+
+	// then
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
+}
+
+func Test_zadd_scoreNeedsToBeFloat(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.ERROR.Typ,
+		Str: "ERR value is not a valid float",
+	}
+
+	zadd, ok := Strategies[ZADD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zadd(request(ZADD, bulks("tira", "misu", "cute")), defaultDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zincrby(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	expected := resp.Value{
+		Typ:    resp.DOUBLE.Typ,
+		Double: -1,
+	}
+
+	zincrby, ok := Strategies[ZINCRBY]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zincrby(request(ZINCRBY, bulks("tira", "-2", "misu")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+
+	rank, _, _ := db.GetSortedSetRank("tira", "misu", false)
+	assert.Equal(t, 0, rank)
+}
+
+func Test_zrem_lastMemberDeletesSortedSet(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 3,
+	}
+
+	zrem, ok := Strategies[ZREM]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrem(request(ZREM, bulks("tira", "misu", "cute", "void", "scary")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+	length, _ := db.GetSortedSetLength("tira")
+	assert.Equal(t, 0, length)
+}
+
+func Test_zcard(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 3,
+	}
+
+	zcard, ok := Strategies[ZCARD]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zcard(request(ZCARD, bulks("tira")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zscore_noValueAvailable(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.NULL.Typ,
+	}
+
+	zscore, ok := Strategies[ZSCORE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zscore(request(ZSCORE, bulks("tira", "scary")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zcount_exclusiveAndInfinite(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 2,
+	}
+
+	zcount, ok := Strategies[ZCOUNT]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zcount(request(ZCOUNT, bulks("tira", "(1", "+inf")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zrank(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.INTEGER.Typ,
+		Num: 1,
+	}
+
+	zrank, ok := Strategies[ZRANK]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrank(request(ZRANK, bulks("tira", "cute")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zrevrank_withScore(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ: resp.ARRAY.Typ,
+		Array: []resp.Value{
+			{Typ: resp.INTEGER.Typ, Num: 0},
+			{Typ: resp.DOUBLE.Typ, Double: 3},
+		},
+	}
+
+	zrevrank, ok := Strategies[ZREVRANK]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrevrank(request(ZREVRANK, bulks("tira", "void", "WITHSCORE")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zpopmin(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("misu", "1", "cute", "2"),
+	}
+
+	zpopmin, ok := Strategies[ZPOPMIN]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zpopmin(request(ZPOPMIN, bulks("tira", "2")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+	length, _ := db.GetSortedSetLength("tira")
+	assert.Equal(t, 1, length)
+}
+
+func Test_zpopmax(t *testing.T) {
+	// given
+	db := sortedSetDb()
+
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("void", "3"),
+	}
+
+	zpopmax, ok := Strategies[ZPOPMAX]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zpopmax(request(ZPOPMAX, bulks("tira")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zrange_byRank(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("cute", "2", "void", "3"),
+	}
+
+	zrange, ok := Strategies[ZRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrange(request(ZRANGE, bulks("tira", "1", "-1", "WITHSCORES")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zrange_byRankReversed(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("void", "cute"),
+	}
+
+	zrange, ok := Strategies[ZRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrange(request(ZRANGE, bulks("tira", "0", "1", "REV")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zrange_byScoreWithLimit(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("cute"),
+	}
+
+	zrange, ok := Strategies[ZRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrange(request(ZRANGE, bulks("tira", "-inf", "+inf", "BYSCORE", "LIMIT", "1", "1")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zrange_byScoreReversed(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("void", "cute"),
+	}
+
+	zrange, ok := Strategies[ZRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrange(request(ZRANGE, bulks("tira", "+inf", "(1", "BYSCORE", "REV")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zrange_byLex(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.AddToSortedSet(resp.Value{}, "tira", []persistence.ScoredMember{
+		{Member: "a", Score: 0},
+		{Member: "b", Score: 0},
+		{Member: "c", Score: 0},
+		{Member: "d", Score: 0},
+	}, persistence.SortedSetAddOptions{})
+
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("b", "c"),
+	}
+
+	zrange, ok := Strategies[ZRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrange(request(ZRANGE, bulks("tira", "(a", "[c", "BYLEX")), db)
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
+
+func Test_zrange_limitWithoutBy_err(t *testing.T) {
+	// given
+	zrange, ok := Strategies[ZRANGE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrange(request(ZRANGE, bulks("tira", "0", "-1", "LIMIT", "0", "1")), sortedSetDb())
+
+	// then
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
+}
+
+func Test_zrangebyscore(t *testing.T) {
+	// given
+	expected := resp.Value{
+		Typ:   resp.ARRAY.Typ,
+		Array: bulks("misu", "1", "cute", "2"),
+	}
+
+	zrangebyscore, ok := Strategies[ZRANGEBYSCORE]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := zrangebyscore(request(ZRANGEBYSCORE, bulks("tira", "-inf", "(3", "WITHSCORES")), sortedSetDb())
+
+	// then
+	assert.EqualValues(t, expected, result)
+}
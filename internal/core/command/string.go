@@ -4,12 +4,14 @@ import (
 	"gocache/internal/core/resp"
 	"gocache/internal/persistence"
 	"log"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 )
 
-// / Saves a value at a specific key
-// / SET {key} {value}
+// / Saves a value at a specific key. EX/PX set a relative, EXAT/PXAT an absolute (unix time) expiration
+// / SET {key} {value} [EX {seconds}|PX {milliseconds}|EXAT {unix-time-seconds}|PXAT {unix-time-milliseconds}]
 // / Example:
 // / Req: SET tira misu
 // / Res: OK
@@ -17,14 +19,14 @@ func setStrategy(request resp.Value, db persistence.Database) resp.Value {
 	args := request.GetArgs()
 
 	if len(args) < 2 {
-		return resp.Value{Typ: "error", Str: "ERR wrong number of arguments for 'set' command"}
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'set' command"}
 	}
 
-	key := args[0].Bulk
-	value := args[1].Bulk
+	key := string(args[0].Bulk)
+	value := string(args[1].Bulk)
 
 	extraArgs := args[2:]
-	expiration := time.Duration(0)
+	entity := persistence.NewString(value, 0)
 	// We dont check the last one, because we will still need a parameter
 	for i := 0; i < len(extraArgs)-1; i++ {
 		v := extraArgs[i]
@@ -33,28 +35,40 @@ func setStrategy(request resp.Value, db persistence.Database) resp.Value {
 			continue
 		}
 		var unit time.Duration
-		switch v.Bulk {
+		absolute := false
+		switch strings.ToUpper(string(v.Bulk)) {
 		case "EX":
 			unit = time.Second
 		case "PX":
 			unit = time.Millisecond
+		case "EXAT":
+			unit = time.Second
+			absolute = true
+		case "PXAT":
+			unit = time.Millisecond
+			absolute = true
 		default:
 			continue
 		}
 
 		rawExpire := extraArgs[i+1]
-		expire, err := strconv.Atoi(rawExpire.Bulk)
+		expire, err := strconv.ParseInt(string(rawExpire.Bulk), 10, 64)
 		if err != nil {
 			return resp.Value{Typ: resp.ERROR.Typ, Str: "EX/PX parameter needs to be a number"}
 		}
 
-		expiration = unit * time.Duration(expire)
+		// like redis, an expiration has to be positive, otherwise the key would be stored without one
+		expiresAt, ok := expirationTime(expire, unit, absolute)
+		if expire <= 0 || !ok {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR invalid expire time in 'set' command"}
+		}
+		entity = persistence.NewStringExpiringAt(value, expiresAt)
 		break
 	}
 
-	err := db.SaveString(request, key, persistence.NewString(value, expiration))
+	err := db.SaveString(request, key, entity)
 	if err != nil {
-		return resp.Value{Typ: "error", Str: err.Error()}
+		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
 	}
 
 	return okResponse
@@ -69,78 +83,111 @@ func getStrategy(request resp.Value, db persistence.Database) resp.Value {
 	args := request.GetArgs()
 
 	if len(args) != 1 {
-		return resp.Value{Typ: "error", Str: "ERR wrong number of arguments for 'get' command"}
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'get' command"}
 	}
 
-	key := args[0].Bulk
+	key := string(args[0].Bulk)
 
 	value, err := db.GetString(key)
+	if isWrongType(err) {
+		return errorValue(err)
+	}
 	if err != nil || value.IsExpired() {
 		log.Printf("Did not find any value with key %s\n", key)
-		return resp.Value{Typ: "null"}
+		return resp.Value{Typ: resp.NULL.Typ}
 	}
 
-	return resp.Value{Typ: "bulk", Bulk: value.Value}
+	return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(value.Value)}
 }
 
-// / Deletes values at specified keys
-// / DEL {key1} [{key2}...]
+// / Increments the integer at key by one. A missing key counts as 0
+// / INCR {key}
 // / Example:
-// / Req: DEL tira
+// / Req: INCR tira
+// / Res: (integer) 2
+func incrStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return incrementBy(request, db, "incr", 1)
+}
+
+// / Increments the integer at key by increment. A missing key counts as 0
+// / INCRBY {key} {increment}
+// / Example:
+// / Req: INCRBY tira 5
+// / Res: (integer) 7
+func incrbyStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return incrementBy(request, db, "incrby", 1)
+}
+
+// / Decrements the integer at key by one. A missing key counts as 0
+// / DECR {key}
+// / Example:
+// / Req: DECR tira
 // / Res: (integer) 1
-func delStrategy(request resp.Value, db persistence.Database) resp.Value {
+func decrStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return incrementBy(request, db, "decr", -1)
+}
+
+// / Decrements the integer at key by decrement. A missing key counts as 0
+// / DECRBY {key} {decrement}
+// / Example:
+// / Req: DECRBY tira 5
+// / Res: (integer) -3
+func decrbyStrategy(request resp.Value, db persistence.Database) resp.Value {
+	return incrementBy(request, db, "decrby", -1)
+}
+
+// / Increments the float at key by increment, which may be negative. A missing key counts as 0
+// / INCRBYFLOAT {key} {increment}
+// / Example:
+// / Req: INCRBYFLOAT tira 0.1
+// / Res: 10.6
+func incrbyfloatStrategy(request resp.Value, db persistence.Database) resp.Value {
 	args := request.GetArgs()
 
-	if len(args) == 0 {
-		return resp.Value{Typ: "error", Str: "ERR wrong number of arguments for 'del' command"}
+	if len(args) != 2 {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'incrbyfloat' command"}
 	}
 
-	keys := []string{}
-	for _, key := range args {
-		if key.Typ != resp.BULK.Typ {
-			continue
-		}
-
-		keys = append(keys, key.Bulk)
+	increment, err := strconv.ParseFloat(string(args[1].Bulk), 64)
+	if err != nil {
+		return errorValue(persistence.ErrNotAFloat)
 	}
 
-	amountDeleted, err := db.DeleteAllStrings(request, keys)
+	result, err := db.IncrementStringByFloat(string(args[0].Bulk), increment)
 	if err != nil {
-		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+		return errorValue(err)
 	}
 
-	return resp.Value{Typ: resp.INTEGER.Typ, Num: amountDeleted}
+	return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(result)}
 }
 
-// / Increments number at key. Returns an error if the key is not interpretable as an int
-// / INCR {key1}
-// / Example:
-// / Req: INCR tira
-// / Res: (integer) 2
-func incrStrategy(request resp.Value, db persistence.Database) resp.Value {
+// / Shared by INCR, INCRBY, DECR and DECRBY. The commands ending with BY read the amount from their second argument, the others use 1.
+// / sign is -1 for the decrements
+func incrementBy(request resp.Value, db persistence.Database, name string, sign int64) resp.Value {
 	args := request.GetArgs()
+	withAmount := strings.HasSuffix(name, "by")
 
-	if len(args) == 0 {
-		return resp.Value{Typ: "error", Str: "ERR wrong number of arguments for 'incr' command"}
+	if (withAmount && len(args) != 2) || (!withAmount && len(args) != 1) {
+		return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + name + "' command"}
 	}
 
-	key := args[0].Bulk
-
-	value, err := db.GetString(key)
-	if err != nil || value.IsExpired() {
-		value = persistence.NewString("0", 0)
+	amount := int64(1)
+	if withAmount {
+		parsed, ok := persistence.ParseInteger(string(args[1].Bulk))
+		if !ok {
+			return notAnIntegerError()
+		}
+		// the negated minimum does not fit into an int64
+		if sign < 0 && parsed == math.MinInt64 {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR decrement would overflow"}
+		}
+		amount = parsed
 	}
 
-	savedNumber, err := strconv.Atoi(value.Value)
+	result, err := db.IncrementString(request, string(args[0].Bulk), sign*amount)
 	if err != nil {
-		return resp.Value{Typ: resp.ERROR.Typ, Str: "Value is not a number"}
-	}
-
-	savedNumber += 1
-	value.SetValue(strconv.Itoa(savedNumber))
-	if err = db.SaveString(request, key, value); err != nil {
-		return resp.Value{Typ: resp.ERROR.Typ, Str: err.Error()}
+		return errorValue(err)
 	}
 
-	return resp.Value{Typ: resp.INTEGER.Typ, Num: savedNumber}
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: int(result)}
 }
@@ -3,6 +3,8 @@ package command
 import (
 	"gocache/internal/core/resp"
 	"gocache/internal/persistence"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,16 +16,16 @@ func Test_set(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Misu",
+			Bulk: []byte("Misu"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "string",
+		Typ: resp.STRING.Typ,
 		Str: "OK",
 	}
 
@@ -54,26 +56,26 @@ func Test_setWithSecondsExpiration(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Misu",
+			Bulk: []byte("Misu"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "EX",
+			Bulk: []byte("EX"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "60",
+			Bulk: []byte("60"),
 		},
 	}
 
 	expectedTime := time.Now().UTC().Add(time.Second * 60)
 
 	expected := resp.Value{
-		Typ: "string",
+		Typ: resp.STRING.Typ,
 		Str: "OK",
 	}
 
@@ -110,26 +112,26 @@ func Test_setWithMillisecondsExpiration(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Misu",
+			Bulk: []byte("Misu"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "PX",
+			Bulk: []byte("PX"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "60000",
+			Bulk: []byte("60000"),
 		},
 	}
 
 	expectedTime := time.Now().UTC().Add(time.Second * 60)
 
 	expected := resp.Value{
-		Typ: "string",
+		Typ: resp.STRING.Typ,
 		Str: "OK",
 	}
 
@@ -166,7 +168,7 @@ func Test_setNeedsTwoArgs(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
@@ -180,7 +182,7 @@ func Test_setNeedsTwoArgs(t *testing.T) {
 	result := set(request(SET, args), defaultDb())
 
 	// then
-	assert.Equal(t, "error", result.Typ)
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
 }
 
 func Test_incr(t *testing.T) {
@@ -191,12 +193,12 @@ func Test_incr(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "integer",
+		Typ: resp.INTEGER.Typ,
 		Num: 6,
 	}
 
@@ -228,14 +230,14 @@ func Test_incr_doesNotOverrideExpiration(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
 	expectedExpiration := time.Now().UTC().Add(time.Second * 60)
 
 	expected := resp.Value{
-		Typ: "integer",
+		Typ: resp.INTEGER.Typ,
 		Num: 6,
 	}
 
@@ -289,7 +291,7 @@ func Test_incr_needsStringToBeNumber(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
@@ -306,17 +308,95 @@ func Test_incr_needsStringToBeNumber(t *testing.T) {
 	assert.Equal(t, resp.ERROR.Typ, result.Typ)
 }
 
+func Test_incr_errorsLikeRedis(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "Tira", persistence.NewString("number", 0))
+	db.SaveString(resp.Value{}, "Misu", persistence.NewString("9223372036854775807", 0))
+
+	// when
+	notAnInteger := Strategies[INCR](request(INCR, bulks("Tira")), db)
+	overflow := Strategies[INCR](request(INCR, bulks("Misu")), db)
+
+	// then
+	assert.Equal(t, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is not an integer or out of range"}, notAnInteger)
+	assert.Equal(t, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR increment or decrement would overflow"}, overflow)
+}
+
+func Test_incrby_decr_decrby(t *testing.T) {
+	tests := []struct {
+		command  string
+		args     []string
+		expected resp.Value
+	}{
+		{INCRBY, []string{"Tira", "5"}, resp.Value{Typ: resp.INTEGER.Typ, Num: 15}},
+		{INCRBY, []string{"Tira", "-15"}, resp.Value{Typ: resp.INTEGER.Typ, Num: -5}},
+		{DECR, []string{"Tira"}, resp.Value{Typ: resp.INTEGER.Typ, Num: 9}},
+		{DECRBY, []string{"Tira", "12"}, resp.Value{Typ: resp.INTEGER.Typ, Num: -2}},
+		{DECRBY, []string{"Void", "3"}, resp.Value{Typ: resp.INTEGER.Typ, Num: -3}},
+		{INCRBY, []string{"Tira", "+5"}, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is not an integer or out of range"}},
+		{INCRBY, []string{"Tira", "1.5"}, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is not an integer or out of range"}},
+		{DECRBY, []string{"Tira", "-9223372036854775808"}, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR decrement would overflow"}},
+		{DECRBY, []string{"Tira", "9223372036854775807"}, resp.Value{Typ: resp.INTEGER.Typ, Num: -9223372036854775797}},
+		{INCRBY, []string{"Tira"}, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'incrby' command"}},
+		{DECR, []string{"Tira", "1"}, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for 'decr' command"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.command+" "+strings.Join(test.args, " "), func(t *testing.T) {
+			// given
+			db := defaultDb()
+			db.SaveString(resp.Value{}, "Tira", persistence.NewString("10", 0))
+
+			// when
+			result := Strategies[test.command](request(test.command, bulks(test.args...)), db)
+
+			// then
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func Test_incrbyfloat(t *testing.T) {
+	tests := []struct {
+		stored    string
+		increment string
+		expected  resp.Value
+	}{
+		{"10.50", "0.1", resp.Value{Typ: resp.BULK.Typ, Bulk: []byte("10.6")}},
+		{"5.0e3", "2.0e2", resp.Value{Typ: resp.BULK.Typ, Bulk: []byte("5200")}},
+		{"3", "-5", resp.Value{Typ: resp.BULK.Typ, Bulk: []byte("-2")}},
+		{"misu", "1", resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is not a valid float"}},
+		{"1", "cute", resp.Value{Typ: resp.ERROR.Typ, Str: "ERR value is not a valid float"}},
+		{"1", "inf", resp.Value{Typ: resp.ERROR.Typ, Str: "ERR increment would produce NaN or Infinity"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.stored+" "+test.increment, func(t *testing.T) {
+			// given
+			db := defaultDb()
+			db.SaveString(resp.Value{}, "Tira", persistence.NewString(test.stored, 0))
+
+			// when
+			result := Strategies[INCRBYFLOAT](request(INCRBYFLOAT, bulks("Tira", test.increment)), db)
+
+			// then
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
 func Test_incr_createsKeyIfNotExists(t *testing.T) {
 	// given
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "integer",
+		Typ: resp.INTEGER.Typ,
 		Num: 1,
 	}
 
@@ -350,12 +430,12 @@ func Test_del(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "integer",
+		Typ: resp.INTEGER.Typ,
 		Num: 1,
 	}
 
@@ -387,16 +467,16 @@ func Test_del_multipleKeys(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Misu",
+			Bulk: []byte("Misu"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "integer",
+		Typ: resp.INTEGER.Typ,
 		Num: 2,
 	}
 
@@ -439,7 +519,7 @@ func Test_del_needsAtLeastOneKey(t *testing.T) {
 	result := del(request(DEL, args), defaultDb())
 
 	// then
-	assert.Equal(t, "error", result.Typ)
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
 }
 
 func Test_get(t *testing.T) {
@@ -450,13 +530,13 @@ func Test_get(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ:  "bulk",
-		Bulk: "Misu",
+		Typ:  resp.BULK.Typ,
+		Bulk: []byte("Misu"),
 	}
 
 	get, ok := Strategies[GET]
@@ -484,11 +564,11 @@ func Test_getCanOnlyReceiveOneArg(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
@@ -502,7 +582,7 @@ func Test_getCanOnlyReceiveOneArg(t *testing.T) {
 	result := get(request(GET, args), defaultDb())
 
 	// then
-	assert.Equal(t, "error", result.Typ)
+	assert.Equal(t, resp.ERROR.Typ, result.Typ)
 }
 
 func Test_getNoValueAvailable(t *testing.T) {
@@ -510,12 +590,12 @@ func Test_getNoValueAvailable(t *testing.T) {
 	args := []resp.Value{
 		{
 			Typ:  resp.BULK.Typ,
-			Bulk: "Tira",
+			Bulk: []byte("Tira"),
 		},
 	}
 
 	expected := resp.Value{
-		Typ: "null",
+		Typ: resp.NULL.Typ,
 	}
 
 	get, ok := Strategies[GET]
@@ -530,3 +610,76 @@ func Test_getNoValueAvailable(t *testing.T) {
 	// then
 	assert.EqualValues(t, expected, result)
 }
+
+func Test_setWithAbsoluteExpiration(t *testing.T) {
+	// given
+	db := defaultDb()
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+
+	set, ok := Strategies[SET]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := set(request(SET, bulks("Tira", "Misu", "PXAT", strconv.FormatInt(expiresAt.UnixMilli(), 10))), db)
+
+	// then
+	assert.Equal(t, "OK", result.Str)
+
+	value, err := db.GetString("Tira")
+	if err != nil {
+		t.Error("Set Storage Key 'Tira' does not exist")
+		return
+	}
+	assert.True(t, expiresAt.Equal(value.Expiration.ExpiresAt))
+}
+
+func Test_setWithPastAbsoluteExpiration(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "Tira", persistence.NewString("Cute", 0))
+
+	set, ok := Strategies[SET]
+	if !ok {
+		t.Error("Command does not exist")
+		return
+	}
+
+	// when
+	result := set(request(SET, bulks("Tira", "Misu", "EXAT", "1")), db)
+
+	// then
+	assert.Equal(t, "OK", result.Str)
+	assert.Equal(t, 0, db.CountExistingKeys([]string{"Tira"}))
+}
+
+func Test_setWithAbsoluteExpirationAfterYear2262(t *testing.T) {
+	// given
+	db := defaultDb()
+
+	// when
+	result := Strategies[SET](request(SET, bulks("Tira", "Misu", "PXAT", "10000000000000")), db)
+
+	// then
+	assert.Equal(t, okResponse, result)
+	assert.Equal(t, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte("Misu")}, Strategies[GET](request(GET, bulks("Tira")), db))
+	assert.Equal(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 10000000000000}, Strategies[PEXPIRETIME](request(PEXPIRETIME, bulks("Tira")), db))
+}
+
+func Test_setWithInvalidExpiration(t *testing.T) {
+	for _, args := range [][]string{{"EX", "0"}, {"PX", "-5"}, {"EXAT", "0"}, {"EXAT", "9223372036854776"}, {"EX", "9223372037"}} {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			// given
+			db := defaultDb()
+
+			// when
+			result := Strategies[SET](request(SET, bulks(append([]string{"Tira", "Misu"}, args...)...)), db)
+
+			// then
+			assert.Equal(t, resp.Value{Typ: resp.ERROR.Typ, Str: "ERR invalid expire time in 'set' command"}, result)
+			assert.Equal(t, 0, db.CountExistingKeys([]string{"Tira"}))
+		})
+	}
+}
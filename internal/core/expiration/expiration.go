@@ -1,43 +1,50 @@
 package expiration
 
 import (
-	"gocache/internal/core/resp"
 	"gocache/internal/persistence"
 	"log"
+	"time"
 )
 
 /// passive vs active
-/// passive: expires a key when its accessed while expired. This is only weakly implemented here.
-/// gocache will simply act as if a key is not there if its expired but will wait for the job to actively delete it
+/// passive: expires a key when its accessed while expired. The persistence layer deletes the key on access and writes a DEL to the AOF
 
-// / active: a job that will occassionally check random keys and expire them
-func ExpireRandomKeys(amountOfKeys int, db persistence.Database) {
-	for range amountOfKeys {
-		k, v, ok := db.GetRandomString()
+// / active: like redis, a cycle samples random keys that have an expiration and deletes the expired ones.
+// / If many of the sampled keys were expired, there are probably more, so the cycle repeats until the budget is used up
+const (
+	SampleSize = 20
+	// the cycle repeats while more than 25% of the sampled keys were expired
+	repeatThreshold = 0.25
+)
 
-		if !ok {
-			break
-		}
+type Stats struct {
+	Rounds  int
+	Sampled int
+	Expired int
+}
+
+// / Runs sampling rounds until less than 25% of a sample was expired or budget is exceeded.
+// / At least one round is run, even with an empty budget
+func ActiveExpireCycle(budget time.Duration, db persistence.Database) Stats {
+	start := time.Now()
+	stats := Stats{}
+
+	for {
+		sampled, expired, err := db.ExpireSampledKeys(SampleSize)
+		stats.Rounds++
+		stats.Sampled += sampled
+		stats.Expired += expired
 
-		if v.IsExpired() {
-			log.Println("Key is expired: " + k)
-			db.DeleteAllStrings(delRequest(k), []string{k})
+		if err != nil {
+			log.Println("Active expiration failed: " + err.Error())
+			return stats
 		}
-	}
-}
 
-func delRequest(key string) resp.Value {
-	return resp.Value{
-		Typ: resp.ARRAY.Typ,
-		Array: []resp.Value{
-			{
-				Typ:  resp.BULK.Typ,
-				Bulk: "DEL",
-			},
-			{
-				Typ:  resp.BULK.Typ,
-				Bulk: key,
-			},
-		},
+		if sampled == 0 || float64(expired) <= float64(sampled)*repeatThreshold {
+			return stats
+		}
+		if time.Since(start) >= budget {
+			return stats
+		}
 	}
 }
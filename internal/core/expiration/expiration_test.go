@@ -1,8 +1,10 @@
 package expiration
 
 import (
+	"gocache/internal/core/pubsub"
 	"gocache/internal/core/resp"
 	"gocache/internal/persistence"
+	"strconv"
 	"testing"
 	"time"
 
@@ -12,17 +14,31 @@ import (
 func Test_expiresKey(t *testing.T) {
 	// given
 	db := defaultDb()
-	// expires immidiately
-	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", time.Nanosecond))
+	// expires almost immidiately
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", time.Millisecond))
+	time.Sleep(2 * time.Millisecond)
 
 	// when
-	ExpireRandomKeys(1, db)
+	stats := ActiveExpireCycle(time.Millisecond, db)
 
 	// then
-	_, err := db.GetString("tira")
-	if err == nil {
-		t.Error("Key tira was not expired")
-	}
+	assert.Equal(t, 1, stats.Expired)
+	assert.Equal(t, "none", db.GetType("tira"))
+}
+
+func Test_expiresHash(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveHash(resp.Value{}, "tira", "misu", "cute")
+	db.SetExpiration(resp.Value{}, "tira", time.Now().Add(time.Millisecond), persistence.ExpirationOptions{})
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	stats := ActiveExpireCycle(time.Millisecond, db)
+
+	// then
+	assert.Equal(t, 1, stats.Expired)
+	assert.Equal(t, "none", db.GetType("tira"))
 }
 
 func Test_doesntExpireKeyWithRemainingExpiration(t *testing.T) {
@@ -32,9 +48,11 @@ func Test_doesntExpireKeyWithRemainingExpiration(t *testing.T) {
 	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", time.Hour))
 
 	// when
-	ExpireRandomKeys(1, db)
+	stats := ActiveExpireCycle(time.Millisecond, db)
 
 	// then
+	assert.Equal(t, Stats{Rounds: 1, Sampled: 1, Expired: 0}, stats)
+
 	value, err := db.GetString("tira")
 	if err != nil {
 		t.Error("Key tira was expired")
@@ -50,9 +68,11 @@ func Test_ignoredKeyWithoutExpiration(t *testing.T) {
 	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", time.Duration(0)))
 
 	// when
-	ExpireRandomKeys(1, db)
+	stats := ActiveExpireCycle(time.Millisecond, db)
 
 	// then
+	assert.Equal(t, 0, stats.Sampled)
+
 	value, err := db.GetString("tira")
 	if err != nil {
 		t.Error("Tira wasn't supposed to be deleted")
@@ -61,6 +81,75 @@ func Test_ignoredKeyWithoutExpiration(t *testing.T) {
 	assert.Equal(t, "misu", value.Value)
 }
 
+func Test_repeatsWhileManyKeysAreExpired(t *testing.T) {
+	// given
+	db := defaultDb()
+	amountOfKeys := SampleSize * 5
+	for i := range amountOfKeys {
+		db.SaveString(resp.Value{}, strconv.Itoa(i), persistence.NewString("misu", time.Millisecond))
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	stats := ActiveExpireCycle(time.Second, db)
+
+	// then
+	assert.Equal(t, amountOfKeys, stats.Expired)
+	assert.Greater(t, stats.Rounds, 1)
+}
+
+func Test_stopsWhenFewKeysAreExpired(t *testing.T) {
+	// given
+	db := defaultDb()
+	db.SaveString(resp.Value{}, "expired", persistence.NewString("misu", time.Millisecond))
+	for i := range SampleSize * 5 {
+		db.SaveString(resp.Value{}, strconv.Itoa(i), persistence.NewString("misu", time.Hour))
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	stats := ActiveExpireCycle(time.Second, db)
+
+	// then
+	assert.Equal(t, 1, stats.Rounds)
+	assert.Equal(t, SampleSize, stats.Sampled)
+}
+
+func Test_stopsWhenBudgetIsUsedUp(t *testing.T) {
+	// given
+	db := defaultDb()
+	for i := range SampleSize * 5 {
+		db.SaveString(resp.Value{}, strconv.Itoa(i), persistence.NewString("misu", time.Millisecond))
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	stats := ActiveExpireCycle(0, db)
+
+	// then
+	assert.Equal(t, 1, stats.Rounds)
+	assert.Equal(t, SampleSize, stats.Expired)
+}
+
 func defaultDb() persistence.Database {
 	return persistence.NewDatabase(nil)
 }
+
+func Test_activeExpirationPublishesExpiredEvents(t *testing.T) {
+	// given
+	db := defaultDb()
+	broker := pubsub.NewBroker()
+	subscriber := pubsub.NewSubscriber(pubsub.DefaultQueueSize)
+	broker.Subscribe(subscriber, "__keyevent@0__:expired")
+	<-subscriber.Messages()
+	db.EnableKeyspaceNotifications(broker, persistence.KeyeventChannel|persistence.ExpiredEvents)
+	db.SaveString(resp.Value{}, "tira", persistence.NewString("misu", time.Millisecond))
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	ActiveExpireCycle(time.Millisecond, db)
+
+	// then
+	message := <-subscriber.Messages()
+	assert.Equal(t, "tira", string(message.Array[2].Bulk))
+}
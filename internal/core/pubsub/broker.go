@@ -0,0 +1,258 @@
+package pubsub
+
+import (
+	"gocache/internal/core/resp"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// / How many messages may wait for a subscriber. A subscriber that falls further behind is dropped, so it can never block a publisher
+const DefaultQueueSize = 1024
+
+// / Receives the messages of its subscriptions in order, together with the confirmations of (un)subscribing
+type Subscriber struct {
+	messages chan resp.Value
+	dropped  chan struct{}
+	drop     sync.Once
+	queued   atomic.Int64
+
+	// protected by the mutex of the broker
+	channels map[string]struct{}
+	patterns map[string]struct{}
+}
+
+func NewSubscriber(queueSize int) *Subscriber {
+	return &Subscriber{
+		messages: make(chan resp.Value, queueSize),
+		dropped:  make(chan struct{}),
+		channels: map[string]struct{}{},
+		patterns: map[string]struct{}{},
+	}
+}
+
+func (s *Subscriber) Messages() <-chan resp.Value {
+	return s.messages
+}
+
+// / Closed once the subscriber fell behind and lost messages. It does not receive anything afterwards
+func (s *Subscriber) Dropped() <-chan struct{} {
+	return s.dropped
+}
+
+// / Returns how many values were queued so far. Values that were sent while holding the broker lock exclusively, like confirmations, are counted together with everything queued before them
+func (s *Subscriber) Queued() int64 {
+	return s.queued.Load()
+}
+
+// / Never blocks. Publishing callers hold the broker lock, so every subscriber sees the messages in the same order
+func (s *Subscriber) send(value resp.Value) bool {
+	select {
+	case <-s.dropped:
+		return false
+	default:
+	}
+
+	select {
+	case s.messages <- value:
+		s.queued.Add(1)
+		return true
+	default:
+		s.drop.Do(func() { close(s.dropped) })
+		return false
+	}
+}
+
+// / Routes published messages to the subscribers of a channel and of every pattern matching the channel
+type Broker struct {
+	mutex    sync.RWMutex
+	channels map[string]map[*Subscriber]struct{}
+	patterns map[string]map[*Subscriber]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		channels: map[string]map[*Subscriber]struct{}{},
+		patterns: map[string]map[*Subscriber]struct{}{},
+	}
+}
+
+func (b *Broker) Subscribe(subscriber *Subscriber, channels ...string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, channel := range channels {
+		add(b.channels, channel, subscriber, subscriber.channels)
+		// confirmed while holding the lock, so the confirmation arrives before the first message
+		subscriber.send(confirmation("subscribe", &channel, subscriber))
+	}
+}
+
+func (b *Broker) PSubscribe(subscriber *Subscriber, patterns ...string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, pattern := range patterns {
+		add(b.patterns, pattern, subscriber, subscriber.patterns)
+		subscriber.send(confirmation("psubscribe", &pattern, subscriber))
+	}
+}
+
+// / Without channels, unsubscribes from every channel
+func (b *Broker) Unsubscribe(subscriber *Subscriber, channels ...string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	unsubscribe(b.channels, subscriber, subscriber.channels, channels, "unsubscribe")
+}
+
+// / Without patterns, unsubscribes from every pattern
+func (b *Broker) PUnsubscribe(subscriber *Subscriber, patterns ...string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	unsubscribe(b.patterns, subscriber, subscriber.patterns, patterns, "punsubscribe")
+}
+
+// / Removes every subscription without confirming it, e.g. when the connection was closed
+func (b *Broker) Remove(subscriber *Subscriber) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for channel := range subscriber.channels {
+		remove(b.channels, channel, subscriber, subscriber.channels)
+	}
+	for pattern := range subscriber.patterns {
+		remove(b.patterns, pattern, subscriber, subscriber.patterns)
+	}
+}
+
+// / Returns the amount of channels and patterns the subscriber is subscribed to
+func (b *Broker) SubscriptionCount(subscriber *Subscriber) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return len(subscriber.channels) + len(subscriber.patterns)
+}
+
+// / Delivers the message without waiting for any subscriber and returns how many subscribers received it
+func (b *Broker) Publish(channel string, message string) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	received := 0
+	for subscriber := range b.channels[channel] {
+		if subscriber.send(bulkPush("message", channel, message)) {
+			received++
+		}
+	}
+	for pattern, subscribers := range b.patterns {
+		if !Match(pattern, channel) {
+			continue
+		}
+		for subscriber := range subscribers {
+			if subscriber.send(bulkPush("pmessage", pattern, channel, message)) {
+				received++
+			}
+		}
+	}
+
+	return received
+}
+
+// / Returns the channels with at least one subscriber, sorted. An empty pattern matches every channel
+func (b *Broker) Channels(pattern string) []string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	channels := []string{}
+	for channel := range b.channels {
+		if pattern == "" || Match(pattern, channel) {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+
+	return channels
+}
+
+// / Returns the amount of subscribers of every channel. Pattern subscriptions are not counted
+func (b *Broker) NumSub(channels ...string) []int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	counts := make([]int, len(channels))
+	for i, channel := range channels {
+		counts[i] = len(b.channels[channel])
+	}
+
+	return counts
+}
+
+// / Returns the amount of distinct patterns with at least one subscriber
+func (b *Broker) NumPat() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return len(b.patterns)
+}
+
+func add(index map[string]map[*Subscriber]struct{}, name string, subscriber *Subscriber, own map[string]struct{}) {
+	subscribers, ok := index[name]
+	if !ok {
+		subscribers = map[*Subscriber]struct{}{}
+		index[name] = subscribers
+	}
+	subscribers[subscriber] = struct{}{}
+	own[name] = struct{}{}
+}
+
+func remove(index map[string]map[*Subscriber]struct{}, name string, subscriber *Subscriber, own map[string]struct{}) {
+	delete(index[name], subscriber)
+	if len(index[name]) == 0 {
+		delete(index, name)
+	}
+	delete(own, name)
+}
+
+func unsubscribe(index map[string]map[*Subscriber]struct{}, subscriber *Subscriber, own map[string]struct{}, names []string, kind string) {
+	if len(names) == 0 {
+		for name := range own {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	// like redis, unsubscribing without any subscription is still confirmed once
+	if len(names) == 0 {
+		subscriber.send(confirmation(kind, nil, subscriber))
+		return
+	}
+
+	for _, name := range names {
+		remove(index, name, subscriber, own)
+		subscriber.send(confirmation(kind, &name, subscriber))
+	}
+}
+
+// / [kind, name, amount of remaining subscriptions] as a push. Without a name, the name is null
+func confirmation(kind string, name *string, subscriber *Subscriber) resp.Value {
+	nameValue := resp.Value{Typ: resp.NULL.Typ}
+	if name != nil {
+		nameValue = resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(*name)}
+	}
+
+	return resp.Value{Typ: resp.PUSH.Typ, Array: []resp.Value{
+		{Typ: resp.BULK.Typ, Bulk: []byte(kind)},
+		nameValue,
+		{Typ: resp.INTEGER.Typ, Num: len(subscriber.channels) + len(subscriber.patterns)},
+	}}
+}
+
+func bulkPush(values ...string) resp.Value {
+	array := make([]resp.Value, len(values))
+	for i, value := range values {
+		array[i] = resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(value)}
+	}
+	return resp.Value{Typ: resp.PUSH.Typ, Array: array}
+}
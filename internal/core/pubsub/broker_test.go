@@ -0,0 +1,144 @@
+package pubsub
+
+import (
+	"gocache/internal/core/resp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_publish_reachesChannelAndPatternSubscribers(t *testing.T) {
+	// given
+	broker := NewBroker()
+	channelSubscriber := NewSubscriber(DefaultQueueSize)
+	patternSubscriber := NewSubscriber(DefaultQueueSize)
+	otherSubscriber := NewSubscriber(DefaultQueueSize)
+	broker.Subscribe(channelSubscriber, "tira")
+	broker.PSubscribe(patternSubscriber, "t*")
+	broker.Subscribe(otherSubscriber, "misu")
+	drain(channelSubscriber, patternSubscriber, otherSubscriber)
+
+	// when
+	received := broker.Publish("tira", "cute")
+
+	// then
+	assert.Equal(t, 2, received)
+	assert.Equal(t, bulkPush("message", "tira", "cute"), <-channelSubscriber.Messages())
+	assert.Equal(t, bulkPush("pmessage", "t*", "tira", "cute"), <-patternSubscriber.Messages())
+	assert.Empty(t, otherSubscriber.Messages())
+}
+
+func Test_subscribe_confirmsEveryChannel(t *testing.T) {
+	// given
+	broker := NewBroker()
+	subscriber := NewSubscriber(DefaultQueueSize)
+
+	// when
+	broker.Subscribe(subscriber, "tira", "misu")
+	broker.PSubscribe(subscriber, "cute*")
+
+	// then
+	assert.Equal(t, confirmationValue("subscribe", "tira", 1), <-subscriber.Messages())
+	assert.Equal(t, confirmationValue("subscribe", "misu", 2), <-subscriber.Messages())
+	assert.Equal(t, confirmationValue("psubscribe", "cute*", 3), <-subscriber.Messages())
+	assert.Equal(t, 3, broker.SubscriptionCount(subscriber))
+}
+
+func Test_unsubscribe_withoutChannels_removesAll(t *testing.T) {
+	// given
+	broker := NewBroker()
+	subscriber := NewSubscriber(DefaultQueueSize)
+	broker.Subscribe(subscriber, "tira", "misu")
+	drain(subscriber)
+
+	// when
+	broker.Unsubscribe(subscriber)
+	broker.Unsubscribe(subscriber)
+
+	// then
+	assert.Equal(t, confirmationValue("unsubscribe", "misu", 1), <-subscriber.Messages())
+	assert.Equal(t, confirmationValue("unsubscribe", "tira", 0), <-subscriber.Messages())
+	assert.Equal(t, resp.Value{Typ: resp.PUSH.Typ, Array: []resp.Value{
+		{Typ: resp.BULK.Typ, Bulk: []byte("unsubscribe")},
+		{Typ: resp.NULL.Typ},
+		{Typ: resp.INTEGER.Typ, Num: 0},
+	}}, <-subscriber.Messages())
+	assert.Empty(t, broker.Channels(""))
+}
+
+func Test_publish_dropsSlowSubscriberWithoutBlocking(t *testing.T) {
+	// given
+	broker := NewBroker()
+	slow := NewSubscriber(2)
+	broker.Subscribe(slow, "tira")
+
+	// when
+	first := broker.Publish("tira", "cute")
+	second := broker.Publish("tira", "void")
+	third := broker.Publish("tira", "scary")
+
+	// then
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 0, second)
+	assert.Equal(t, 0, third)
+	select {
+	case <-slow.Dropped():
+	default:
+		t.Error("slow subscriber was not dropped")
+	}
+}
+
+func Test_introspection(t *testing.T) {
+	// given
+	broker := NewBroker()
+	first := NewSubscriber(DefaultQueueSize)
+	second := NewSubscriber(DefaultQueueSize)
+	broker.Subscribe(first, "tira", "misu")
+	broker.Subscribe(second, "tira")
+	broker.PSubscribe(first, "t*")
+	broker.PSubscribe(second, "t*", "m*")
+
+	// when
+	channels := broker.Channels("")
+	filtered := broker.Channels("t*")
+	numSub := broker.NumSub("tira", "misu", "cute")
+	numPat := broker.NumPat()
+
+	// then
+	assert.Equal(t, []string{"misu", "tira"}, channels)
+	assert.Equal(t, []string{"tira"}, filtered)
+	assert.Equal(t, []int{2, 1, 0}, numSub)
+	assert.Equal(t, 2, numPat)
+}
+
+func Test_remove_dropsAllSubscriptions(t *testing.T) {
+	// given
+	broker := NewBroker()
+	subscriber := NewSubscriber(DefaultQueueSize)
+	broker.Subscribe(subscriber, "tira")
+	broker.PSubscribe(subscriber, "t*")
+
+	// when
+	broker.Remove(subscriber)
+
+	// then
+	assert.Equal(t, 0, broker.Publish("tira", "cute"))
+	assert.Equal(t, 0, broker.SubscriptionCount(subscriber))
+	assert.Equal(t, 0, broker.NumPat())
+}
+
+func confirmationValue(kind string, name string, count int) resp.Value {
+	return resp.Value{Typ: resp.PUSH.Typ, Array: []resp.Value{
+		{Typ: resp.BULK.Typ, Bulk: []byte(kind)},
+		{Typ: resp.BULK.Typ, Bulk: []byte(name)},
+		{Typ: resp.INTEGER.Typ, Num: count},
+	}}
+}
+
+func drain(subscribers ...*Subscriber) {
+	for _, subscriber := range subscribers {
+		for len(subscriber.messages) > 0 {
+			<-subscriber.messages
+		}
+	}
+}
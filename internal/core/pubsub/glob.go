@@ -0,0 +1,89 @@
+package pubsub
+
+// / Matches like redis glob patterns do:
+// / * matches any sequence, ? any single character, [abc] and [a-z] a set of characters, [^a] everything except a set. \ escapes the next character.
+// / Unlike path.Match, * also matches / since channels have no path semantics
+// / Only the last star is retried on a mismatch, so matching takes at most len(pattern) * len(value) steps instead of backtracking exponentially
+func Match(pattern string, value string) bool {
+	// where to retry on a mismatch: the pattern after the last star and the value that star did not swallow yet
+	starred := false
+	var starPattern, starValue string
+
+	for len(pattern) > 0 || len(value) > 0 {
+		if len(pattern) > 0 && pattern[0] == '*' {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			starred, starPattern, starValue = true, pattern, value
+			continue
+		}
+
+		if len(pattern) > 0 && len(value) > 0 {
+			if matched, rest := matchSingle(pattern, value[0]); matched {
+				pattern, value = rest, value[1:]
+				continue
+			}
+		}
+
+		if !starred || len(starValue) == 0 {
+			return false
+		}
+		// the last star swallows one more character
+		starValue = starValue[1:]
+		pattern, value = starPattern, starValue
+	}
+
+	return true
+}
+
+// / Matches c against everything but a star at the start of pattern. Returns the pattern after it
+func matchSingle(pattern string, c byte) (bool, string) {
+	switch pattern[0] {
+	case '?':
+		return true, pattern[1:]
+	case '[':
+		return matchClass(pattern[1:], c)
+	case '\\':
+		if len(pattern) > 1 {
+			pattern = pattern[1:]
+		}
+		fallthrough
+	default:
+		return pattern[0] == c, pattern[1:]
+	}
+}
+
+// / Matches c against the class at the start of pattern, which starts after the opening bracket. Returns the pattern after the class
+func matchClass(pattern string, c byte) (bool, string) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) > 1:
+			matched = matched || pattern[1] == c
+			pattern = pattern[2:]
+		case len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']':
+			start, end := pattern[0], pattern[2]
+			if start > end {
+				start, end = end, start
+			}
+			matched = matched || start <= c && c <= end
+			pattern = pattern[3:]
+		default:
+			matched = matched || pattern[0] == c
+			pattern = pattern[1:]
+		}
+	}
+
+	// an unterminated class ends with the pattern, like in redis
+	if len(pattern) > 0 {
+		pattern = pattern[1:]
+	}
+
+	return matched != negate, pattern
+}
@@ -0,0 +1,60 @@
+package pubsub
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_match(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		value    string
+		expected bool
+	}{
+		{pattern: "tira", value: "tira", expected: true},
+		{pattern: "tira", value: "tiramisu", expected: false},
+		{pattern: "tira*", value: "tiramisu", expected: true},
+		{pattern: "*", value: "", expected: true},
+		{pattern: "*misu", value: "tira/misu", expected: true},
+		{pattern: "t**a*u", value: "tiramisu", expected: true},
+		{pattern: "t?ra", value: "tira", expected: true},
+		{pattern: "t?ra", value: "tra", expected: false},
+		{pattern: "t[aei]ra", value: "tira", expected: true},
+		{pattern: "t[^i]ra", value: "tira", expected: false},
+		{pattern: "t[a-j]ra", value: "tira", expected: true},
+		{pattern: "t[j-z]ra", value: "tira", expected: false},
+		{pattern: "tira\\*", value: "tira*", expected: true},
+		{pattern: "tira\\*", value: "tiramisu", expected: false},
+		{pattern: "__keyspace@0__:*", value: "__keyspace@0__:tira", expected: true},
+		{pattern: "*a*u", value: "tiramisu", expected: true},
+		{pattern: "*a*t", value: "tiramisu", expected: false},
+		{pattern: "*?", value: "", expected: false},
+		{pattern: "t*[m-n]isu", value: "tiramisu", expected: true},
+		{pattern: "*\\*", value: "tiramisu*", expected: true},
+		{pattern: "*\\*", value: "tira*misu", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.value, func(t *testing.T) {
+			// when
+			result := Match(test.pattern, test.value)
+
+			// then
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func Test_match_manyStarsDoNotBacktrackExponentially(t *testing.T) {
+	// given
+	pattern := strings.Repeat("*a", 50) + "b"
+	value := strings.Repeat("a", 10000)
+
+	// when
+	result := Match(pattern, value)
+
+	// then
+	assert.False(t, result)
+}
@@ -0,0 +1,131 @@
+package resp
+
+import (
+	"strconv"
+	"strings"
+)
+
+var errUnbalancedQuotes = protocolError("unbalanced quotes in request")
+
+// / Inline commands are plain text lines like "SET tira misu", so telnet and simple probes can talk to the server.
+// / The first byte of the line was already unread
+func (r *Resp) readInline() (Value, error) {
+	raw, err := r.readRawLine()
+	if err != nil {
+		return Value{}, err
+	}
+	line := strings.TrimSuffix(strings.TrimSuffix(string(raw), "\n"), "\r")
+
+	args, err := splitInlineArgs(line)
+	if err != nil {
+		return Value{}, err
+	}
+
+	v := Value{Typ: ARRAY.Typ, Array: make([]Value, len(args))}
+	for i, arg := range args {
+		v.Array[i] = Value{Typ: BULK.Typ, Bulk: []byte(arg)}
+	}
+	return v, nil
+}
+
+// / Splits the line at whitespace like redis does. Arguments can be "double quoted" with escapes like \n or \x41, or 'single quoted' where only \' is escaped
+func splitInlineArgs(line string) ([]string, error) {
+	args := []string{}
+
+	i := 0
+	for {
+		for i < len(line) && isSpace(line[i]) {
+			i++
+		}
+		if i == len(line) {
+			return args, nil
+		}
+
+		arg := []byte{}
+		for i < len(line) && !isSpace(line[i]) && line[i] != '"' && line[i] != '\'' {
+			arg = append(arg, line[i])
+			i++
+		}
+
+		// like in redis, a quote starts a quoted part that ends the argument, even in the middle of it
+		if i < len(line) && !isSpace(line[i]) {
+			var err error
+			if line[i] == '"' {
+				i, err = readDoubleQuoted(line, i+1, &arg)
+			} else {
+				i, err = readSingleQuoted(line, i+1, &arg)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		args = append(args, string(arg))
+	}
+}
+
+// / Reads from start to the closing quote and returns the index after it. The closing quote has to be followed by whitespace or the end of the line
+func readDoubleQuoted(line string, start int, arg *[]byte) (int, error) {
+	for i := start; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+3 < len(line) && line[i+1] == 'x' && isHex(line[i+2]) && isHex(line[i+3]):
+			b, _ := strconv.ParseUint(line[i+2:i+4], 16, 8)
+			*arg = append(*arg, byte(b))
+			i += 3
+		case c == '\\' && i+1 < len(line):
+			i++
+			*arg = append(*arg, unescape(line[i]))
+		case c == '"':
+			return closeQuote(line, i)
+		default:
+			*arg = append(*arg, c)
+		}
+	}
+	return 0, errUnbalancedQuotes
+}
+
+func readSingleQuoted(line string, start int, arg *[]byte) (int, error) {
+	for i := start; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '\'':
+			i++
+			*arg = append(*arg, '\'')
+		case c == '\'':
+			return closeQuote(line, i)
+		default:
+			*arg = append(*arg, c)
+		}
+	}
+	return 0, errUnbalancedQuotes
+}
+
+func closeQuote(line string, quote int) (int, error) {
+	if quote+1 < len(line) && !isSpace(line[quote+1]) {
+		return 0, errUnbalancedQuotes
+	}
+	return quote + 1, nil
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'a':
+		return '\a'
+	}
+	return c
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -2,87 +2,295 @@ package resp
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"strconv"
 )
 
+// / Invalid input, e.g. from a client. The server replies with the error and closes the connection, because it can not find the start of the next command anymore
+var ErrProtocol = errors.New("Protocol error")
+
+var (
+	errMissingLineBreak = protocolError("expected CRLF")
+	errLineTooLong      = protocolError("too big line")
+	errInvalidInteger   = protocolError("invalid integer")
+)
+
+const (
+	// lines only hold types, lengths, integers and simple strings. Like redis, longer ones are rejected
+	maxLineLength = 64 * 1024
+
+	preallocatedBulkLength  = 64 * 1024
+	preallocatedArrayLength = 1024
+
+	// small bulks are cut from shared chunks of this size, instead of allocating each of them on its own
+	arenaChunkLength = 4 * 1024
+	maxArenaBulk     = 512
+)
+
+// / Like proto-max-bulk-len in redis, limits how big a bulk or an array that a client sends may be. 0 disables a limit
+type Limits struct {
+	MaxBulkLength  int
+	MaxArrayLength int
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		MaxBulkLength:  512 * 1024 * 1024,
+		MaxArrayLength: 1024 * 1024,
+	}
+}
+
 type Resp struct {
 	reader *bufio.Reader
+	input  *countingReader
+	limits Limits
+	// the rest of the current chunk for small bulks. Chunks are never reused, so bulks stay valid after the next read
+	arena []byte
 }
 
 func NewReader(input io.Reader) *Resp {
-	return &Resp{reader: bufio.NewReader(input)}
+	counting := &countingReader{input: input}
+	return &Resp{reader: bufio.NewReader(counting), input: counting}
 }
 
+// / Readers have no limits by default, e.g. for the AOF, which only contains commands the server already accepted
+func (r *Resp) SetLimits(limits Limits) {
+	r.limits = limits
+}
+
+// / Returns how many bytes were already received, but not read yet. More than 0 means the client pipelined further commands
+func (r *Resp) Buffered() int {
+	return r.reader.Buffered()
+}
+
+// / Returns how many bytes of the input were consumed by the values read so far
+func (r *Resp) Consumed() int64 {
+	return r.input.count - int64(r.reader.Buffered())
+}
+
+// / Reads the next value of any RESP2 type, e.g. a persisted command or the reply of a server
 func (r *Resp) Read() (Value, error) {
 	typ, err := r.reader.ReadByte()
 	if err != nil {
 		return Value{}, err
 	}
 
+	return r.readValue(typ)
+}
+
+// / Reads the next command of a client. Besides RESP, clients may send inline commands. Like in redis, only an array starts a RESP command, anything else is read inline.
+// / Persisted commands are read with Read instead, since they are always RESP
+func (r *Resp) ReadCommand() (Value, error) {
+	for {
+		typ, err := r.reader.ReadByte()
+		if err != nil {
+			return Value{}, err
+		}
+		if typ == ARRAY.RespCode {
+			return r.readArray(true)
+		}
+
+		r.reader.UnreadByte()
+		value, err := r.readInline()
+		// like redis, empty lines are skipped
+		if err == nil && len(value.Array) == 0 {
+			continue
+		}
+		return value, err
+	}
+}
+
+func (r *Resp) readValue(typ byte) (Value, error) {
 	switch typ {
 	case ARRAY.RespCode:
-		return r.readArray()
+		return r.readArray(false)
 	case BULK.RespCode:
 		return r.readBulk()
+	case STRING.RespCode:
+		return r.readSimple(STRING)
+	case ERROR.RespCode:
+		return r.readSimple(ERROR)
+	case INTEGER.RespCode:
+		return r.readNumber()
 	default:
-		return Value{}, errors.New("Received unknown type: " + string(typ))
+		return Value{}, protocolError("unknown type '" + string(typ) + "'")
 	}
 }
 
 // type parsers
 func (r *Resp) readBulk() (Value, error) {
-	v := Value{Typ: "bulk"}
+	v := Value{Typ: BULK.Typ}
 
-	len, _, err := r.readInteger()
+	len, err := r.readLength("bulk")
 	if err != nil {
 		return v, err
 	}
+	if len == -1 {
+		return Value{Typ: NULL.Typ}, nil
+	}
+	if r.limits.MaxBulkLength > 0 && len > r.limits.MaxBulkLength {
+		return v, protocolError("invalid bulk length")
+	}
 
-	bulk := make([]byte, len)
-	r.reader.Read(bulk)
-
-	v.Bulk = string(bulk)
+	bulk, err := r.readBulkData(len)
+	if err != nil {
+		return v, err
+	}
+	if err := r.readLineBreak(); err != nil {
+		return v, err
+	}
 
-	r.readLine() // consume last linebreak
+	v.Bulk = bulk
 	return v, nil
 }
 
-func (r *Resp) readArray() (Value, error) {
-	v := Value{Typ: "array"}
+// / Big bulks are read as they arrive instead of allocating the announced length up front, so a client can not claim memory it never sends
+func (r *Resp) readBulkData(length int) ([]byte, error) {
+	if length <= preallocatedBulkLength {
+		bulk := r.allocate(length)
+		if _, err := io.ReadFull(r.reader, bulk); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return bulk, nil
+	}
+
+	var bulk bytes.Buffer
+	if _, err := io.CopyN(&bulk, r.reader, int64(length)); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return bulk.Bytes(), nil
+}
+
+// / Cuts small bulks from the arena. The capacity of a bulk ends with it, so appending to it can not overwrite the next one
+func (r *Resp) allocate(length int) []byte {
+	if length == 0 {
+		return []byte{}
+	}
+	if length > maxArenaBulk {
+		return make([]byte, length)
+	}
+	if cap(r.arena)-len(r.arena) < length {
+		r.arena = make([]byte, 0, arenaChunkLength)
+	}
+
+	start := len(r.arena)
+	r.arena = r.arena[:start+length]
+	return r.arena[start : start+length : start+length]
+}
+
+// / Like in redis, a command only consists of bulks. Nested arrays are rejected, so a client can not make the reader recurse without end
+func (r *Resp) readArray(command bool) (Value, error) {
+	v := Value{Typ: ARRAY.Typ}
 
-	len, _, err := r.readInteger()
+	len, err := r.readLength("multibulk")
 	if err != nil {
 		return v, err
 	}
+	if len == -1 {
+		return Value{Typ: NULL_ARRAY.Typ}, nil
+	}
+	if r.limits.MaxArrayLength > 0 && len > r.limits.MaxArrayLength {
+		return v, protocolError("invalid multibulk length")
+	}
 
-	v.Array = make([]Value, len)
-	for i := range len {
-		value, err := r.Read()
+	v.Array = make([]Value, 0, min(len, preallocatedArrayLength))
+	for range len {
+		typ, err := r.reader.ReadByte()
 		if err != nil {
-			return Value{}, err
+			return Value{}, unexpectedEOF(err)
+		}
+		if command && typ != BULK.RespCode {
+			return Value{}, protocolError("expected '$', got '" + string(typ) + "'")
+		}
+		value, err := r.readValue(typ)
+		if err != nil {
+			return Value{}, unexpectedEOF(err)
 		}
-		v.Array[i] = value
+		if command && value.Typ == NULL.Typ {
+			return Value{}, protocolError("invalid bulk length")
+		}
+		v.Array = append(v.Array, value)
 	}
 
 	return v, nil
 }
 
+// / Simple strings and errors are a single line without a length
+func (r *Resp) readSimple(typ Typ) (Value, error) {
+	line, _, err := r.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+
+	return Value{Typ: typ.Typ, Str: string(line)}, nil
+}
+
+func (r *Resp) readNumber() (Value, error) {
+	num, _, err := r.readInteger()
+	if err != nil {
+		return Value{}, err
+	}
+
+	return Value{Typ: INTEGER.Typ, Num: num}, nil
+}
+
 // base functions
+
+// / Lines have to end with CRLF. Anything else means the input is out of sync, so the following values can not be trusted either
 func (r *Resp) readLine() (line []byte, lineLength int, err error) {
-	for {
+	line, err = r.readRawLine()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, 0, errMissingLineBreak
+	}
+	return line[:len(line)-2], len(line), nil
+}
+
+// / Reads up to and including the next \n, but never more than maxLineLength bytes.
+// / The line may point into the buffer of the reader, so it is only valid until the next read
+func (r *Resp) readRawLine() ([]byte, error) {
+	line, err := r.reader.ReadSlice('\n')
+	if err == nil {
+		return line, nil
+	}
+
+	// lines longer than the buffer are collected in a copy
+	var long []byte
+	for err == bufio.ErrBufferFull {
+		long = append(long, line...)
+		if len(long) > maxLineLength {
+			return nil, errLineTooLong
+		}
+		line, err = r.reader.ReadSlice('\n')
+	}
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	long = append(long, line...)
+	if len(long) > maxLineLength {
+		return nil, errLineTooLong
+	}
+	return long, nil
+}
+
+// / Consumes the CRLF that follows the data of a bulk
+func (r *Resp) readLineBreak() error {
+	for _, expected := range []byte{'\r', '\n'} {
 		b, err := r.reader.ReadByte()
 		if err != nil {
-			return nil, 0, err
+			return unexpectedEOF(err)
 		}
-		lineLength += 1
-		line = append(line, b)
-		if len(line) >= 2 && line[len(line)-2] == '\r' {
-			break
+		if b != expected {
+			return errMissingLineBreak
 		}
 	}
-	return line[:len(line)-2], lineLength, nil
+	return nil
 }
 
 func (r *Resp) readInteger() (x int, lineLength int, err error) {
@@ -92,7 +300,39 @@ func (r *Resp) readInteger() (x int, lineLength int, err error) {
 	}
 	i64, err := strconv.ParseInt(string(line), 10, 64)
 	if err != nil {
-		return 0, lineLength, err
+		return 0, lineLength, errInvalidInteger
 	}
 	return int(i64), lineLength, nil
 }
+
+// / Reads the length of a bulk or an array. -1 is the only valid negative length, which stands for null
+func (r *Resp) readLength(kind string) (int, error) {
+	length, _, err := r.readInteger()
+	if err == errInvalidInteger || (err == nil && length < -1) {
+		return 0, protocolError("invalid " + kind + " length")
+	}
+	return length, err
+}
+
+// / readLine and the nested reads only run after the type of a value was read, so reaching the end of the input there means the value is incomplete
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+type countingReader struct {
+	input io.Reader
+	count int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.input.Read(p)
+	c.count += int64(n)
+	return n, err
+}
+
+func protocolError(problem string) error {
+	return fmt.Errorf("%w: %s", ErrProtocol, problem)
+}
@@ -1,39 +1,109 @@
 package resp
 
 import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"testing/quick"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_readBulkMissingLineBreak_succeeds(t *testing.T) {
+func Test_readBulkMissingLineBreak_failsWithUnexpectedEOF(t *testing.T) {
 	// given
-	input := "$8\r\nTiramisu"
-	expected := Value{
-		Typ:  "bulk",
-		Bulk: "Tiramisu",
+	reader := NewReader(strings.NewReader("$8\r\nTiramisu"))
+
+	// when
+	_, err := reader.Read()
+
+	// then
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func Test_readInvalidLineBreaks_failsWithProtocolError(t *testing.T) {
+	for _, input := range []string{"$8\r\nTiramisuXX", "$8\nTiramisu\r\n", "*1\r\n$4\rTira\r\n", "+OK\n"} {
+		// given
+		reader := NewReader(strings.NewReader(input))
+
+		// when
+		_, err := reader.Read()
+
+		// then
+		assert.ErrorIs(t, err, ErrProtocol, input)
 	}
+}
 
-	reader := NewReader(strings.NewReader(input))
+func Test_readBulk_shortReads(t *testing.T) {
+	// given
+	bulk := strings.Repeat("tiramisu", 100_000)
+	input := "$" + strconv.Itoa(len(bulk)) + "\r\n" + bulk + "\r\n"
+	reader := NewReader(iotest.OneByteReader(strings.NewReader(input)))
 
 	// when
 	result, err := reader.Read()
 
 	// then
-	if err != nil {
-		t.Error("Expected non-failure during parsing. Got an error instead: ", err)
+	assert.NoError(t, err)
+	assert.Equal(t, bulk, string(result.Bulk))
+}
+
+func Test_consumed_countsBytesOfReadValues(t *testing.T) {
+	// given
+	big := "$" + strconv.Itoa(10_000) + "\r\n" + strings.Repeat("t", 10_000) + "\r\n"
+	input := "*1\r\n$4\r\nPING\r\n" + big + ":1\r\n"
+	reader := NewReader(strings.NewReader(input))
+
+	// when
+	reader.Read()
+	afterFirst := reader.Consumed()
+	reader.Read()
+	afterSecond := reader.Consumed()
+
+	// then
+	assert.Equal(t, int64(14), afterFirst)
+	assert.Equal(t, int64(14+len(big)), afterSecond)
+}
+
+func Test_readWithLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"bulk too long", "$9999999999\r\n", "Protocol error: invalid bulk length"},
+		{"array too long", "*1025\r\n", "Protocol error: invalid multibulk length"},
+		{"bulk inside array too long", "*1\r\n$11\r\n", "Protocol error: invalid bulk length"},
+		{"invalid length", "$abc\r\n", "Protocol error: invalid bulk length"},
+		{"line too long", "+" + strings.Repeat("a", maxLineLength) + "\r\n", "Protocol error: too big line"},
 	}
 
-	assert.EqualValues(t, expected, result)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			reader := NewReader(strings.NewReader(test.input))
+			reader.SetLimits(Limits{MaxBulkLength: 10, MaxArrayLength: 1024})
+
+			// when
+			_, err := reader.Read()
+
+			// then
+			assert.ErrorIs(t, err, ErrProtocol)
+			assert.EqualError(t, err, test.expected)
+		})
+	}
 }
 
 func Test_readBulk(t *testing.T) {
 	// given
 	input := "$8\r\nTiramisu\r\n"
 	expected := Value{
-		Typ:  "bulk",
-		Bulk: "Tiramisu",
+		Typ:  BULK.Typ,
+		Bulk: []byte("Tiramisu"),
 	}
 
 	reader := NewReader(strings.NewReader(input))
@@ -53,15 +123,15 @@ func Test_readArrayWith2Bulks(t *testing.T) {
 	// given
 	input := "*2\r\n$4\r\nTira\r\n$4\r\nMisu\r\n"
 	expected := Value{
-		Typ: "array",
+		Typ: ARRAY.Typ,
 		Array: []Value{
 			{
-				Typ:  "bulk",
-				Bulk: "Tira",
+				Typ:  BULK.Typ,
+				Bulk: []byte("Tira"),
 			},
 			{
-				Typ:  "bulk",
-				Bulk: "Misu",
+				Typ:  BULK.Typ,
+				Bulk: []byte("Misu"),
 			},
 		},
 	}
@@ -78,3 +148,291 @@ func Test_readArrayWith2Bulks(t *testing.T) {
 
 	assert.EqualValues(t, expected, result)
 }
+
+func Test_readIncompleteArray_failsWithUnexpectedEOF(t *testing.T) {
+	// given
+	reader := NewReader(strings.NewReader("*2\r\n$4\r\nTira\r\n$4\r\nMi"))
+
+	// when
+	_, err := reader.Read()
+
+	// then
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func Test_readEmptyInput_failsWithEOF(t *testing.T) {
+	// given
+	reader := NewReader(strings.NewReader(""))
+
+	// when
+	_, err := reader.Read()
+
+	// then
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func Test_readResp2Types(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Value
+	}{
+		{"simple string", "+OK\r\n", Value{Typ: STRING.Typ, Str: "OK"}},
+		{"error", "-ERR unknown command\r\n", Value{Typ: ERROR.Typ, Str: "ERR unknown command"}},
+		{"integer", ":1000\r\n", Value{Typ: INTEGER.Typ, Num: 1000}},
+		{"negative integer", ":-3\r\n", Value{Typ: INTEGER.Typ, Num: -3}},
+		{"null bulk", "$-1\r\n", Value{Typ: NULL.Typ}},
+		{"null array", "*-1\r\n", Value{Typ: NULL_ARRAY.Typ}},
+		{"empty bulk", "$0\r\n\r\n", Value{Typ: BULK.Typ, Bulk: []byte{}}},
+		{"mixed array", "*3\r\n:1\r\n$-1\r\n+OK\r\n", Value{Typ: ARRAY.Typ, Array: []Value{
+			{Typ: INTEGER.Typ, Num: 1},
+			{Typ: NULL.Typ},
+			{Typ: STRING.Typ, Str: "OK"},
+		}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			reader := NewReader(strings.NewReader(test.input))
+
+			// when
+			result, err := reader.Read()
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func Test_readInvalidLengths_fails(t *testing.T) {
+	for _, input := range []string{"$-2\r\n", "*-2\r\n", ":one\r\n"} {
+		// given
+		reader := NewReader(strings.NewReader(input))
+
+		// when
+		_, err := reader.Read()
+
+		// then
+		assert.Error(t, err, input)
+	}
+}
+
+func Test_readMarshalledValue_roundTrip(t *testing.T) {
+	// given
+	roundTrip := func(value resp2Value) bool {
+		reader := NewReader(strings.NewReader(string(Value(value).Marshal())))
+
+		// when
+		result, err := reader.Read()
+
+		// then
+		return err == nil && reflect.DeepEqual(Value(value), result)
+	}
+
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+// / Generates random values of the types the reader and Marshal have in common
+type resp2Value Value
+
+func (resp2Value) Generate(random *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(resp2Value(randomValue(random, 3)))
+}
+
+func randomValue(random *rand.Rand, depth int) Value {
+	kinds := 6
+	if depth > 0 {
+		kinds = 7
+	}
+
+	switch random.Intn(kinds) {
+	case 0:
+		return Value{Typ: STRING.Typ, Str: randomLine(random)}
+	case 1:
+		return Value{Typ: ERROR.Typ, Str: randomLine(random)}
+	case 2:
+		return Value{Typ: INTEGER.Typ, Num: int(random.Int63()) - int(random.Int63())}
+	case 3:
+		bulk := make([]byte, random.Intn(32))
+		random.Read(bulk)
+		return Value{Typ: BULK.Typ, Bulk: bulk}
+	case 4:
+		return Value{Typ: NULL.Typ}
+	case 5:
+		return Value{Typ: NULL_ARRAY.Typ}
+	default:
+		array := make([]Value, random.Intn(5))
+		for i := range array {
+			array[i] = randomValue(random, depth-1)
+		}
+		return Value{Typ: ARRAY.Typ, Array: array}
+	}
+}
+
+// / Simple strings and errors can not contain line breaks
+func randomLine(random *rand.Rand) string {
+	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -_:$*+"
+	line := make([]byte, random.Intn(16))
+	for i := range line {
+		line[i] = letters[random.Intn(len(letters))]
+	}
+	return string(line)
+}
+
+func Test_readInlineCommand(t *testing.T) {
+	// given
+	reader := NewReader(strings.NewReader("SET tira  misu\r\nPING\n"))
+
+	// when
+	set, setErr := reader.ReadCommand()
+	ping, pingErr := reader.ReadCommand()
+
+	// then
+	assert.NoError(t, setErr)
+	assert.Equal(t, inlineValue("SET", "tira", "misu"), set)
+	assert.NoError(t, pingErr)
+	assert.Equal(t, inlineValue("PING"), ping)
+}
+
+func Test_readInlineCommand_skipsEmptyLines(t *testing.T) {
+	// given
+	reader := NewReader(strings.NewReader("\r\n  \r\nPING\r\n"))
+
+	// when
+	result, err := reader.ReadCommand()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, inlineValue("PING"), result)
+}
+
+func Test_readInlineCommand_withoutLineBreak_failsWithUnexpectedEOF(t *testing.T) {
+	// given
+	reader := NewReader(strings.NewReader("PING"))
+
+	// when
+	_, err := reader.ReadCommand()
+
+	// then
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func Test_readCommand_arrayDoesNotContainInlineElements(t *testing.T) {
+	// given
+	reader := NewReader(strings.NewReader("*1\r\nPING\r\n"))
+
+	// when
+	_, err := reader.ReadCommand()
+
+	// then
+	assert.Error(t, err)
+}
+
+func Test_readCommand_onlyAcceptsBulksInArray(t *testing.T) {
+	for _, input := range []string{strings.Repeat("*1\r\n", 100_000) + "$4\r\nPING\r\n", "*2\r\n$3\r\nGET\r\n:1\r\n", "*1\r\n$-1\r\n"} {
+		// given
+		reader := NewReader(strings.NewReader(input))
+
+		// when
+		_, err := reader.ReadCommand()
+
+		// then
+		assert.ErrorIs(t, err, ErrProtocol, input[:min(len(input), 20)])
+	}
+}
+
+func Test_readCommand_onlyArraysAreResp(t *testing.T) {
+	// given
+	reader := NewReader(strings.NewReader("$4\r\nPING\r\n"))
+
+	// when
+	length, lengthErr := reader.ReadCommand()
+	ping, pingErr := reader.ReadCommand()
+
+	// then
+	assert.NoError(t, lengthErr)
+	assert.Equal(t, inlineValue("$4"), length)
+	assert.NoError(t, pingErr)
+	assert.Equal(t, inlineValue("PING"), ping)
+}
+
+func Test_read_doesNotAcceptInlineCommands(t *testing.T) {
+	// given
+	reader := NewReader(strings.NewReader("PING\r\n"))
+
+	// when
+	_, err := reader.Read()
+
+	// then
+	assert.Error(t, err)
+}
+
+func Test_splitInlineArgs(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"", []string{}},
+		{"  PING  ", []string{"PING"}},
+		{"SET tira\tmisu", []string{"SET", "tira", "misu"}},
+		{`SET "tira misu" 'cute void'`, []string{"SET", "tira misu", "cute void"}},
+		{`SET tira "a\tb\n\"c\"\x41\\"`, []string{"SET", "tira", "a\tb\n\"c\"A\\"}},
+		{`SET tira 'it\'s \n'`, []string{"SET", "tira", `it's \n`}},
+		{`SET tira ""`, []string{"SET", "tira", ""}},
+		{`SET ti"ra misu"`, []string{"SET", "tira misu"}},
+	}
+
+	for _, test := range tests {
+		// when
+		args, err := splitInlineArgs(test.line)
+
+		// then
+		assert.NoError(t, err, test.line)
+		assert.Equal(t, test.expected, args, test.line)
+	}
+}
+
+func Test_splitInlineArgs_unbalancedQuotes(t *testing.T) {
+	for _, line := range []string{`SET "tira`, `SET 'tira`, `SET "tira"misu`, `SET 'tira'misu`} {
+		// when
+		_, err := splitInlineArgs(line)
+
+		// then
+		assert.Equal(t, errUnbalancedQuotes, err, line)
+	}
+}
+
+func inlineValue(args ...string) Value {
+	v := Value{Typ: ARRAY.Typ, Array: []Value{}}
+	for _, arg := range args {
+		v.Array = append(v.Array, Value{Typ: BULK.Typ, Bulk: []byte(arg)})
+	}
+	return v
+}
+
+func Benchmark_readSetCommand(b *testing.B) {
+	command := bulkValue("SET", "tira", "misu").Marshal()
+	input := bytes.Repeat(command, 1000)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		reader := NewReader(bytes.NewReader(input))
+		for range 1000 {
+			if _, err := reader.Read(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func bulkValue(values ...string) Value {
+	array := make([]Value, len(values))
+	for i, value := range values {
+		array[i] = Value{Typ: BULK.Typ, Bulk: []byte(value)}
+	}
+	return Value{Typ: ARRAY.Typ, Array: array}
+}
@@ -1,107 +1,276 @@
 package resp
 
 import (
+	"math"
 	"strconv"
+	"strings"
 )
 
+// / The kind of a value. The zero kind is no value at all, e.g. for commands that reply on their own
+type Kind uint8
+
+const (
+	kindNone Kind = iota
+	kindBulk
+	kindArray
+	kindInteger
+	kindNull
+	kindNullArray
+	kindString
+	kindError
+	kindMap
+	kindSet
+	kindDouble
+	kindBoolean
+	kindBigNumber
+	kindVerbatim
+	kindPush
+	kindAttribute
+)
+
+var kindNames = [...]string{
+	kindNone:      "none",
+	kindBulk:      "bulk",
+	kindArray:     "array",
+	kindInteger:   "integer",
+	kindNull:      "null",
+	kindNullArray: "nullArray",
+	kindString:    "string",
+	kindError:     "error",
+	kindMap:       "map",
+	kindSet:       "set",
+	kindDouble:    "double",
+	kindBoolean:   "boolean",
+	kindBigNumber: "bigNumber",
+	kindVerbatim:  "verbatim",
+	kindPush:      "push",
+	kindAttribute: "attribute",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "unknown"
+}
+
 type Typ struct {
 	RespCode byte
-	Typ      string
+	Typ      Kind
 }
 
 var (
 	// rd/wrt
-	BULK    = Typ{RespCode: '$', Typ: "bulk"}
-	ARRAY   = Typ{RespCode: '*', Typ: "array"}
-	INTEGER = Typ{RespCode: ':', Typ: "integer"}
+	BULK    = Typ{RespCode: '$', Typ: kindBulk}
+	ARRAY   = Typ{RespCode: '*', Typ: kindArray}
+	INTEGER = Typ{RespCode: ':', Typ: kindInteger}
 
 	// wrt only
-	NULL   = Typ{RespCode: '$', Typ: "null"}
-	STRING = Typ{RespCode: '+', Typ: "string"}
-	ERROR  = Typ{RespCode: '-', Typ: "error"}
+	NULL       = Typ{RespCode: '$', Typ: kindNull}
+	NULL_ARRAY = Typ{RespCode: '*', Typ: kindNullArray}
+	STRING     = Typ{RespCode: '+', Typ: kindString}
+	ERROR      = Typ{RespCode: '-', Typ: kindError}
+
+	// RESP3 only. Clients that speak RESP2 receive them as the closest RESP2 type
+	MAP        = Typ{RespCode: '%', Typ: kindMap}
+	SET        = Typ{RespCode: '~', Typ: kindSet}
+	DOUBLE     = Typ{RespCode: ',', Typ: kindDouble}
+	BOOLEAN    = Typ{RespCode: '#', Typ: kindBoolean}
+	BIG_NUMBER = Typ{RespCode: '(', Typ: kindBigNumber}
+	VERBATIM   = Typ{RespCode: '=', Typ: kindVerbatim}
+	PUSH       = Typ{RespCode: '>', Typ: kindPush}
+	ATTRIBUTE  = Typ{RespCode: '|', Typ: kindAttribute}
+)
+
+// / RESP3 has a single null, which both NULL and NULL_ARRAY are sent as
+const null3RespCode = '_'
+
+// / The protocol version a client speaks. It decides how the RESP3 types are sent
+type Protocol int
+
+const (
+	RESP2 Protocol = 2
+	RESP3 Protocol = 3
 )
 
 // This can be improved using union types, which go currently do not support
+// / Maps and attributes keep their keys and values alternating in Array. A verbatim string keeps its format, e.g. txt, in Str and its text in Bulk.
+// / Big numbers keep their digits in Str. Bulks that were read may share their memory with the other bulks of the same value, so they must not be modified
 type Value struct {
-	Typ   string
-	Str   string
-	Num   int
-	Bulk  string
-	Array []Value
+	Typ    Kind
+	Str    string
+	Num    int
+	Bulk   []byte
+	Array  []Value
+	Double float64
+	Bool   bool
 }
 
+// / Marshals the value for a RESP2 client
 func (v Value) Marshal() []byte {
+	return v.AppendMarshalProtocol(nil, RESP2)
+}
+
+func (v Value) MarshalProtocol(protocol Protocol) []byte {
+	return v.AppendMarshalProtocol(nil, protocol)
+}
+
+// / Appends the value for a RESP2 client to dst, so a buffer can be reused for many values
+func (v Value) AppendMarshal(dst []byte) []byte {
+	return v.AppendMarshalProtocol(dst, RESP2)
+}
+
+func (v Value) AppendMarshalProtocol(dst []byte, protocol Protocol) []byte {
 	switch v.Typ {
-	case ARRAY.Typ:
-		return v.marshalArray()
-	case BULK.Typ:
-		return v.marshalBulk()
-	case STRING.Typ:
-		return v.marshalString()
-	case INTEGER.Typ:
-		return v.marshalInteger()
-	case NULL.Typ:
-		return v.marshallNull()
-	case ERROR.Typ:
-		return v.marshallError()
-	default:
-		return []byte{}
+	case kindArray:
+		return v.appendAggregate(dst, ARRAY.RespCode, len(v.Array), protocol)
+	case kindBulk:
+		return appendBulk(dst, v.Bulk)
+	case kindString:
+		return appendLine(dst, STRING.RespCode, v.Str)
+	case kindInteger:
+		return appendInteger(dst, INTEGER.RespCode, v.Num)
+	case kindNull:
+		if protocol == RESP3 {
+			return append(dst, null3RespCode, '\r', '\n')
+		}
+		return append(dst, "$-1\r\n"...)
+	case kindNullArray:
+		if protocol == RESP3 {
+			return append(dst, null3RespCode, '\r', '\n')
+		}
+		return append(dst, "*-1\r\n"...)
+	case kindError:
+		return appendLine(dst, ERROR.RespCode, v.Str)
 	}
-}
 
-func (v Value) GetArgs() []Value {
-	return v.Array[1:]
+	if protocol == RESP3 {
+		return v.appendResp3(dst)
+	}
+	if converted := v.resp2(); converted.Typ != kindNone {
+		return converted.AppendMarshalProtocol(dst, RESP2)
+	}
+	return dst
 }
 
-func (v Value) marshalArray() []byte {
-	length := len(v.Array)
-	var bytes []byte
-	bytes = append(bytes, ARRAY.RespCode)
-	bytes = append(bytes, strconv.Itoa(length)...)
-	bytes = append(bytes, '\r', '\n')
+func (v Value) appendResp3(dst []byte) []byte {
+	switch v.Typ {
+	case kindMap:
+		return v.appendAggregate(dst, MAP.RespCode, len(v.Array)/2, RESP3)
+	case kindSet:
+		return v.appendAggregate(dst, SET.RespCode, len(v.Array), RESP3)
+	case kindPush:
+		return v.appendAggregate(dst, PUSH.RespCode, len(v.Array), RESP3)
+	case kindAttribute:
+		return v.appendAggregate(dst, ATTRIBUTE.RespCode, len(v.Array)/2, RESP3)
+	case kindDouble:
+		return appendLine(dst, DOUBLE.RespCode, FormatDouble(v.Double))
+	case kindBoolean:
+		if v.Bool {
+			return appendLine(dst, BOOLEAN.RespCode, "t")
+		}
+		return appendLine(dst, BOOLEAN.RespCode, "f")
+	case kindBigNumber:
+		return appendLine(dst, BIG_NUMBER.RespCode, v.Str)
+	case kindVerbatim:
+		dst = appendInteger(dst, VERBATIM.RespCode, len(v.Str)+1+len(v.Bulk))
+		dst = append(dst, v.Str...)
+		dst = append(dst, ':')
+		dst = append(dst, v.Bulk...)
+		return append(dst, '\r', '\n')
+	default:
+		return dst
+	}
+}
 
-	for i := range length {
-		bytes = append(bytes, v.Array[i].Marshal()...)
+// / Converts a RESP3 type into the type a RESP2 client expects, like redis does. Attributes are dropped
+func (v Value) resp2() Value {
+	switch v.Typ {
+	case kindMap, kindSet, kindPush:
+		return Value{Typ: kindArray, Array: v.Array}
+	case kindDouble:
+		return Value{Typ: kindBulk, Bulk: []byte(FormatDouble(v.Double))}
+	case kindBoolean:
+		if v.Bool {
+			return Value{Typ: kindInteger, Num: 1}
+		}
+		return Value{Typ: kindInteger, Num: 0}
+	case kindBigNumber:
+		return Value{Typ: kindBulk, Bulk: []byte(v.Str)}
+	case kindVerbatim:
+		return Value{Typ: kindBulk, Bulk: v.Bulk}
+	default:
+		return Value{}
 	}
+}
 
-	return bytes
+// / Formats a double like RESP3 does, which redis also uses for scores in RESP2
+func FormatDouble(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
 }
 
-func (v Value) marshalBulk() []byte {
-	var bytes []byte
-	bytes = append(bytes, BULK.RespCode)
-	bytes = append(bytes, strconv.Itoa(len(v.Bulk))...)
-	bytes = append(bytes, '\r', '\n')
-	bytes = append(bytes, v.Bulk...)
-	bytes = append(bytes, '\r', '\n')
-	return bytes
+// / Readable form of the value for logs, since bulks would be printed as numbers otherwise, e.g. [SET "tira" "misu"]
+func (v Value) String() string {
+	switch v.Typ {
+	case kindBulk, kindVerbatim:
+		return strconv.Quote(string(v.Bulk))
+	case kindString, kindError, kindBigNumber:
+		return v.Str
+	case kindInteger:
+		return strconv.Itoa(v.Num)
+	case kindDouble:
+		return FormatDouble(v.Double)
+	case kindBoolean:
+		return strconv.FormatBool(v.Bool)
+	case kindNull, kindNullArray:
+		return "nil"
+	case kindArray, kindMap, kindSet, kindPush, kindAttribute:
+		elements := make([]string, len(v.Array))
+		for i, element := range v.Array {
+			elements[i] = element.String()
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	default:
+		return v.Typ.String()
+	}
 }
 
-func (v Value) marshalString() []byte {
-	var bytes []byte
-	bytes = append(bytes, STRING.RespCode)
-	bytes = append(bytes, v.Str...)
-	bytes = append(bytes, '\r', '\n')
-	return bytes
+func (v Value) GetArgs() []Value {
+	return v.Array[1:]
 }
 
-func (v Value) marshalInteger() []byte {
-	var bytes []byte
-	bytes = append(bytes, INTEGER.RespCode)
-	bytes = append(bytes, []byte(strconv.Itoa(v.Num))...)
-	bytes = append(bytes, '\r', '\n')
-	return bytes
+func (v Value) appendAggregate(dst []byte, code byte, length int, protocol Protocol) []byte {
+	dst = appendInteger(dst, code, length)
+	for _, element := range v.Array {
+		dst = element.AppendMarshalProtocol(dst, protocol)
+	}
+
+	return dst
 }
 
-func (v Value) marshallError() []byte {
-	var bytes []byte
-	bytes = append(bytes, ERROR.RespCode)
-	bytes = append(bytes, v.Str...)
-	bytes = append(bytes, '\r', '\n')
+func appendLine(dst []byte, code byte, line string) []byte {
+	dst = append(dst, code)
+	dst = append(dst, line...)
+	return append(dst, '\r', '\n')
+}
 
-	return bytes
+func appendInteger(dst []byte, code byte, integer int) []byte {
+	dst = append(dst, code)
+	dst = strconv.AppendInt(dst, int64(integer), 10)
+	return append(dst, '\r', '\n')
 }
 
-func (v Value) marshallNull() []byte {
-	return []byte("$-1\r\n")
+func appendBulk(dst []byte, bulk []byte) []byte {
+	dst = appendInteger(dst, BULK.RespCode, len(bulk))
+	dst = append(dst, bulk...)
+	return append(dst, '\r', '\n')
 }
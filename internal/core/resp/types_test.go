@@ -1,6 +1,7 @@
 package resp
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,7 +11,7 @@ func Test_writeBulk(t *testing.T) {
 	// given
 	input := Value{
 		Typ:  BULK.Typ,
-		Bulk: "Niclas",
+		Bulk: []byte("Niclas"),
 	}
 	expected := []byte("$6\r\nNiclas\r\n")
 
@@ -73,11 +74,11 @@ func Test_writeArray(t *testing.T) {
 		Array: []Value{
 			{
 				Typ:  BULK.Typ,
-				Bulk: "Tira",
+				Bulk: []byte("Tira"),
 			},
 			{
 				Typ:  BULK.Typ,
-				Bulk: "Misu",
+				Bulk: []byte("Misu"),
 			},
 		},
 	}
@@ -93,7 +94,7 @@ func Test_writeArray(t *testing.T) {
 func Test_writeNull(t *testing.T) {
 	// given
 	input := Value{
-		Typ: "null",
+		Typ: NULL.Typ,
 	}
 	expected := []byte("$-1\r\n")
 
@@ -107,7 +108,7 @@ func Test_writeNull(t *testing.T) {
 func Test_writeUnknown(t *testing.T) {
 	// given
 	input := Value{
-		Typ: "unknown",
+		Typ: Kind(255),
 	}
 
 	// when
@@ -116,3 +117,103 @@ func Test_writeUnknown(t *testing.T) {
 	// then
 	assert.Equal(t, 0, len(result))
 }
+
+func Test_writeResp3Types(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Value
+		expected string
+	}{
+		{"map", Value{Typ: MAP.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("tira")}, {Typ: INTEGER.Typ, Num: 1}}}, "%1\r\n$4\r\ntira\r\n:1\r\n"},
+		{"set", Value{Typ: SET.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("misu")}}}, "~1\r\n$4\r\nmisu\r\n"},
+		{"double", Value{Typ: DOUBLE.Typ, Double: 1.5}, ",1.5\r\n"},
+		{"infinite double", Value{Typ: DOUBLE.Typ, Double: math.Inf(-1)}, ",-inf\r\n"},
+		{"boolean", Value{Typ: BOOLEAN.Typ, Bool: true}, "#t\r\n"},
+		{"big number", Value{Typ: BIG_NUMBER.Typ, Str: "3492890328409238509324850943850943825024385"}, "(3492890328409238509324850943850943825024385\r\n"},
+		{"verbatim", Value{Typ: VERBATIM.Typ, Str: "txt", Bulk: []byte("cute")}, "=8\r\ntxt:cute\r\n"},
+		{"null", Value{Typ: NULL.Typ}, "_\r\n"},
+		{"null array", Value{Typ: NULL_ARRAY.Typ}, "_\r\n"},
+		{"push", Value{Typ: PUSH.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("message")}}}, ">1\r\n$7\r\nmessage\r\n"},
+		{"attribute", Value{Typ: ATTRIBUTE.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("ttl")}, {Typ: INTEGER.Typ, Num: 3}}}, "|1\r\n$3\r\nttl\r\n:3\r\n"},
+		{"nested", Value{Typ: ARRAY.Typ, Array: []Value{{Typ: NULL.Typ}, {Typ: DOUBLE.Typ, Double: 2}}}, "*2\r\n_\r\n,2\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			result := test.input.MarshalProtocol(RESP3)
+
+			// then
+			assert.Equal(t, test.expected, string(result))
+		})
+	}
+}
+
+func Test_writeResp3TypesForResp2(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Value
+		expected string
+	}{
+		{"map", Value{Typ: MAP.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("tira")}, {Typ: INTEGER.Typ, Num: 1}}}, "*2\r\n$4\r\ntira\r\n:1\r\n"},
+		{"set", Value{Typ: SET.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("misu")}}}, "*1\r\n$4\r\nmisu\r\n"},
+		{"double", Value{Typ: DOUBLE.Typ, Double: 1.5}, "$3\r\n1.5\r\n"},
+		{"boolean", Value{Typ: BOOLEAN.Typ, Bool: false}, ":0\r\n"},
+		{"big number", Value{Typ: BIG_NUMBER.Typ, Str: "12345678901234567890"}, "$20\r\n12345678901234567890\r\n"},
+		{"verbatim", Value{Typ: VERBATIM.Typ, Str: "txt", Bulk: []byte("cute")}, "$4\r\ncute\r\n"},
+		{"null", Value{Typ: NULL.Typ}, "$-1\r\n"},
+		{"push", Value{Typ: PUSH.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("message")}}}, "*1\r\n$7\r\nmessage\r\n"},
+		{"attribute", Value{Typ: ATTRIBUTE.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("ttl")}, {Typ: INTEGER.Typ, Num: 3}}}, ""},
+		{"nested", Value{Typ: MAP.Typ, Array: []Value{{Typ: BULK.Typ, Bulk: []byte("score")}, {Typ: DOUBLE.Typ, Double: 2}}}, "*2\r\n$5\r\nscore\r\n$1\r\n2\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			result := test.input.Marshal()
+
+			// then
+			assert.Equal(t, test.expected, string(result))
+		})
+	}
+}
+
+func Benchmark_marshal(b *testing.B) {
+	value := Value{Typ: ARRAY.Typ}
+	for range 10 {
+		value.Array = append(value.Array, Value{Typ: BULK.Typ, Bulk: []byte("tiramisu")})
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		value.Marshal()
+	}
+}
+
+func Benchmark_appendMarshal(b *testing.B) {
+	value := Value{Typ: ARRAY.Typ}
+	for range 10 {
+		value.Array = append(value.Array, Value{Typ: BULK.Typ, Bulk: []byte("tiramisu")})
+	}
+	var buffer []byte
+
+	b.ReportAllocs()
+	for b.Loop() {
+		buffer = value.AppendMarshal(buffer[:0])
+	}
+}
+
+func Test_valueString(t *testing.T) {
+	// given
+	input := Value{Typ: ARRAY.Typ, Array: []Value{
+		{Typ: BULK.Typ, Bulk: []byte("SET")},
+		{Typ: INTEGER.Typ, Num: 3},
+		{Typ: NULL.Typ},
+	}}
+
+	// when
+	result := input.String()
+
+	// then
+	assert.Equal(t, `["SET" 3 nil]`, result)
+}
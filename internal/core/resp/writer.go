@@ -5,17 +5,25 @@ import (
 )
 
 type Writer struct {
-	writer io.Writer
+	writer   io.Writer
+	protocol Protocol
+	// reused for every value, so writing does not allocate once it grew big enough
+	buffer []byte
 }
 
+// / Writes RESP2 until the client switches the protocol
 func NewWriter(w io.Writer) *Writer {
-	return &Writer{writer: w}
+	return &Writer{writer: w, protocol: RESP2}
+}
+
+func (w *Writer) SetProtocol(protocol Protocol) {
+	w.protocol = protocol
 }
 
 func (w *Writer) Write(v Value) error {
-	var bytes = v.Marshal()
+	w.buffer = v.AppendMarshalProtocol(w.buffer[:0], w.protocol)
 
-	_, err := w.writer.Write(bytes)
+	_, err := w.writer.Write(w.buffer)
 	if err != nil {
 		return err
 	}
@@ -1,33 +1,165 @@
 package startup
 
 import (
-	"errors"
+	"fmt"
 	"gocache/internal/core/command"
 	"gocache/internal/core/resp"
 	"gocache/internal/persistence"
+	"log"
 	"strings"
+	"time"
 )
 
+// / How often the progress of a long replay is logged
+const progressInterval = 5 * time.Second
+
 func ReplayCommands(disk persistence.DiskPersistence, db persistence.Database) error {
-	commands, err := disk.ReadPersistedCommands()
+	replayer := newReplayer("disk", db)
+	if err := disk.ReplayPersistedCommands(replayer.replay); err != nil {
+		return err
+	}
+	replayer.finish()
+
+	db.EnablePersistence(disk)
+
+	return nil
+}
+
+// / Loads the snapshot first and replays only the part of the AOF that was written after it.
+// / If the AOF was rewritten or replaced since the snapshot was taken, the snapshot is ignored and the whole AOF is replayed
+func LoadPersistence(snapshot *persistence.Snapshot, aof *persistence.Aof, db persistence.Database) error {
+	position, ok, err := snapshot.Position()
 	if err != nil {
 		return err
 	}
 
-	for _, v := range commands {
-		name := strings.ToUpper(v.Array[0].Bulk)
-		strategy, ok := command.Strategies[name]
-		if !ok {
-			return errors.New("Command not found: " + name)
+	offset := int64(0)
+	// without an AOF, the snapshot is the only copy of the data and has to be written into the new AOF
+	rewrite := false
+	if ok {
+		matches, err := aof.MatchesPosition(position)
+		if err != nil {
+			return err
 		}
 
-		result := strategy(v, db)
-		if result.Typ == resp.ERROR.Typ {
-			return errors.New("Command returned error: " + result.Str)
+		empty := aof.Position().Offset == 0
+		switch {
+		case matches:
+			offset = position.Offset
+		case empty:
+			rewrite = true
+		default:
+			log.Println("Snapshot does not match the AOF, replaying the whole AOF instead")
+		}
+
+		if matches || empty {
+			replayer := newReplayer("snapshot", db)
+			if err := snapshot.ReplayPersistedCommands(replayer.replay); err != nil {
+				return err
+			}
+			replayer.finish()
 		}
 	}
 
-	db.EnablePersistence(disk)
+	replayer := newReplayer("AOF", db)
+	if err := aof.ReplayPersistedCommandsFrom(offset, replayer.replay); err != nil {
+		return err
+	}
+	replayer.finish()
+
+	db.EnablePersistence(aof, snapshot)
+
+	if rewrite {
+		done, err := db.RewriteAof()
+		if err != nil {
+			return err
+		}
+		return <-done
+	}
 
 	return nil
 }
+
+// / Executes persisted commands one at a time and keeps track of the progress.
+// / Commands of a MULTI/EXEC block are collected and only executed once the block is complete
+type replayer struct {
+	source   string
+	db       persistence.Database
+	start    time.Time
+	lastLog  time.Time
+	commands int
+	bytes    int64
+
+	// nil while no block is read
+	block []resp.Value
+}
+
+func newReplayer(source string, db persistence.Database) *replayer {
+	now := time.Now()
+	return &replayer{source: source, db: db, start: now, lastLog: now}
+}
+
+func (r *replayer) replay(v resp.Value, size int64) error {
+	if len(v.Array) == 0 {
+		return fmt.Errorf("Command %d is empty", r.commands)
+	}
+
+	name := strings.ToUpper(string(v.Array[0].Bulk))
+	switch {
+	case name == command.MULTI:
+		if r.block != nil {
+			return fmt.Errorf("Command %d (%s) is nested in another MULTI", r.commands, name)
+		}
+		r.block = []resp.Value{}
+	case name == command.EXEC:
+		if r.block == nil {
+			return fmt.Errorf("Command %d (%s) has no MULTI", r.commands, name)
+		}
+		block := r.block
+		r.block = nil
+		for i, queued := range block {
+			if err := r.execute(r.commands-len(block)+i, queued); err != nil {
+				return err
+			}
+		}
+	case r.block != nil:
+		r.block = append(r.block, v)
+	default:
+		if err := r.execute(r.commands, v); err != nil {
+			return err
+		}
+	}
+
+	r.commands++
+	r.bytes += size
+	if time.Since(r.lastLog) >= progressInterval {
+		r.lastLog = time.Now()
+		log.Printf("Replaying %s: %d commands, %d bytes in %v\n", r.source, r.commands, r.bytes, time.Since(r.start).Round(time.Millisecond))
+	}
+
+	return nil
+}
+
+func (r *replayer) execute(index int, v resp.Value) error {
+	name := strings.ToUpper(string(v.Array[0].Bulk))
+	strategy, ok := command.Strategies[name]
+	if !ok {
+		return fmt.Errorf("Command %d not found: %s", index, name)
+	}
+
+	result := strategy(v, r.db)
+	if result.Typ == resp.ERROR.Typ {
+		return fmt.Errorf("Command %d (%s) returned error: %s", index, name, result.Str)
+	}
+
+	return nil
+}
+
+// / A block without EXEC can only be at the end, where a crash cut it off. It is dropped, so transactions stay atomic
+func (r *replayer) finish() {
+	if r.block != nil {
+		log.Printf("Dropped an incomplete transaction of %d commands at the end of the %s\n", len(r.block), r.source)
+	}
+
+	log.Printf("Replayed %s: %d commands, %d bytes in %v\n", r.source, r.commands, r.bytes, time.Since(r.start).Round(time.Millisecond))
+}
@@ -2,9 +2,14 @@ package startup
 
 import (
 	"errors"
+	"gocache/internal/core/command"
 	"gocache/internal/core/resp"
 	"gocache/internal/persistence"
+	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,15 +26,15 @@ func Test_startup_repeatsSet(t *testing.T) {
 				{
 					Typ: resp.BULK.Typ,
 					// inconsistent cases to test that they dont matter
-					Bulk: "SeT",
+					Bulk: []byte("SeT"),
 				},
 				{
 					Typ:  resp.BULK.Typ,
-					Bulk: key,
+					Bulk: []byte(key),
 				},
 				{
 					Typ:  resp.BULK.Typ,
-					Bulk: expected,
+					Bulk: []byte(expected),
 				},
 			},
 		},
@@ -67,19 +72,19 @@ func Test_startup_repeatsHSet(t *testing.T) {
 				{
 					Typ: resp.BULK.Typ,
 					// inconsistent cases to test that they dont matter
-					Bulk: "HSEt",
+					Bulk: []byte("HSEt"),
 				},
 				{
 					Typ:  resp.BULK.Typ,
-					Bulk: hash,
+					Bulk: []byte(hash),
 				},
 				{
 					Typ:  resp.BULK.Typ,
-					Bulk: key,
+					Bulk: []byte(key),
 				},
 				{
 					Typ:  resp.BULK.Typ,
-					Bulk: expected,
+					Bulk: []byte(expected),
 				},
 			},
 		},
@@ -116,15 +121,15 @@ func Test_startup_repeatsDel(t *testing.T) {
 				{
 					Typ: resp.BULK.Typ,
 					// inconsistent cases to test that they dont matter
-					Bulk: "SEt",
+					Bulk: []byte("SEt"),
 				},
 				{
 					Typ:  resp.BULK.Typ,
-					Bulk: key,
+					Bulk: []byte(key),
 				},
 				{
 					Typ:  resp.BULK.Typ,
-					Bulk: value,
+					Bulk: []byte(value),
 				},
 			},
 		},
@@ -134,11 +139,11 @@ func Test_startup_repeatsDel(t *testing.T) {
 				{
 					Typ: resp.BULK.Typ,
 					// inconsistent cases to test that they dont matter
-					Bulk: "dEL",
+					Bulk: []byte("dEL"),
 				},
 				{
 					Typ:  resp.BULK.Typ,
-					Bulk: key,
+					Bulk: []byte(key),
 				},
 			},
 		},
@@ -162,6 +167,327 @@ func Test_startup_repeatsDel(t *testing.T) {
 	}
 }
 
+func Test_startup_repeatsListCommands(t *testing.T) {
+	// given
+	key := "Tira"
+
+	request := []resp.Value{
+		{
+			Typ: resp.ARRAY.Typ,
+			Array: []resp.Value{
+				{Typ: resp.BULK.Typ, Bulk: []byte("rpush")},
+				{Typ: resp.BULK.Typ, Bulk: []byte(key)},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Misu")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Cute")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Void")},
+			},
+		},
+		{
+			Typ: resp.ARRAY.Typ,
+			Array: []resp.Value{
+				{Typ: resp.BULK.Typ, Bulk: []byte("LPOP")},
+				{Typ: resp.BULK.Typ, Bulk: []byte(key)},
+			},
+		},
+	}
+
+	db := defaultDb()
+	disk := defaultDisk(request)
+
+	// when
+	err := ReplayCommands(disk, db)
+
+	// then
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+
+	values, err := db.GetListRange(key, 0, -1)
+	if err != nil {
+		t.Error("List was not created")
+		return
+	}
+	assert.Equal(t, []string{"Cute", "Void"}, values)
+}
+
+func Test_startup_dropsExpiredKeys(t *testing.T) {
+	// given
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).UnixMilli(), 10)
+	future := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+
+	request := []resp.Value{
+		{
+			Typ: resp.ARRAY.Typ,
+			Array: []resp.Value{
+				{Typ: resp.BULK.Typ, Bulk: []byte("SET")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Tira")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Misu")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("PXAT")},
+				{Typ: resp.BULK.Typ, Bulk: []byte(past)},
+			},
+		},
+		{
+			Typ: resp.ARRAY.Typ,
+			Array: []resp.Value{
+				{Typ: resp.BULK.Typ, Bulk: []byte("HSET")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Misu")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Cute")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Void")},
+			},
+		},
+		{
+			Typ: resp.ARRAY.Typ,
+			Array: []resp.Value{
+				{Typ: resp.BULK.Typ, Bulk: []byte("PEXPIREAT")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Misu")},
+				{Typ: resp.BULK.Typ, Bulk: []byte(past)},
+			},
+		},
+		{
+			Typ: resp.ARRAY.Typ,
+			Array: []resp.Value{
+				{Typ: resp.BULK.Typ, Bulk: []byte("SET")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Cute")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("Void")},
+				{Typ: resp.BULK.Typ, Bulk: []byte("PXAT")},
+				{Typ: resp.BULK.Typ, Bulk: []byte(strconv.FormatInt(future.UnixMilli(), 10))},
+			},
+		},
+	}
+
+	db := defaultDb()
+	disk := defaultDisk(request)
+
+	// when
+	err := ReplayCommands(disk, db)
+
+	// then
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+
+	assert.Equal(t, 0, db.CountExistingKeys([]string{"Tira", "Misu"}))
+
+	expiration, exists := db.GetExpiration("Cute")
+	assert.True(t, exists)
+	assert.True(t, future.Equal(expiration.ExpiresAt))
+}
+
+func Test_startup_replaysRewrittenAof(t *testing.T) {
+	// given
+	file, err := os.CreateTemp("", "database.test.aof")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.Remove(file.Name())
+
+	aof, err := persistence.NewAof(file.Name(), persistence.DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	db := defaultDb()
+	db.EnablePersistence(aof)
+	for _, request := range [][]string{
+		{"INCR", "Tira"},
+		{"INCR", "Tira"},
+		{"RPUSH", "Misu", "Cute", "Void", "Scary"},
+		{"LPOP", "Misu"},
+		{"ZADD", "Cute", "1", "Void", "2.5", "Scary"},
+		{"EXPIRE", "Cute", "100"},
+	} {
+		value := resp.Value{Typ: resp.ARRAY.Typ}
+		for _, arg := range request {
+			value.Array = append(value.Array, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(arg)})
+		}
+		command.Strategies[request[0]](value, db)
+	}
+
+	// when
+	done, err := db.RewriteAof()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+		return
+	}
+	aof.Close()
+
+	// then
+	rewritten, err := persistence.NewAof(file.Name(), persistence.DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer rewritten.Close()
+
+	commands, err := readAll(rewritten)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	assert.Len(t, commands, 4)
+
+	replayed := defaultDb()
+	if err := ReplayCommands(rewritten, replayed); err != nil {
+		t.Error(err)
+		return
+	}
+
+	value, _ := replayed.GetString("Tira")
+	assert.Equal(t, "2", value.Value)
+	values, _ := replayed.GetListRange("Misu", 0, -1)
+	assert.Equal(t, []string{"Void", "Scary"}, values)
+	members, _ := replayed.GetSortedSetRange("Cute", 0, -1, false)
+	assert.Equal(t, []persistence.ScoredMember{{Member: "Void", Score: 1}, {Member: "Scary", Score: 2.5}}, members)
+	original, _ := db.GetExpiration("Cute")
+	expiration, _ := replayed.GetExpiration("Cute")
+	assert.Equal(t, original.ExpiresAt.UnixMilli(), expiration.ExpiresAt.UnixMilli())
+}
+
+func Test_startup_loadsSnapshotAndAofTail(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	aof, _, db := persistedDb(t, dir)
+	execute(db, "SET", "Tira", "Misu")
+	execute(db, "RPUSH", "Cute", "Void")
+	done, _ := db.SaveSnapshot()
+	if err := <-done; err != nil {
+		t.Error(err)
+		return
+	}
+	execute(db, "RPUSH", "Cute", "Scary")
+	execute(db, "DEL", "Tira")
+	aof.Close()
+
+	// when
+	reopened, reopenedSnapshot, loaded := persistedDb(t, dir)
+	defer reopened.Close()
+
+	// then
+	commands, _ := readAll(reopenedSnapshot)
+	assert.Len(t, commands, 2)
+	_, err := loaded.GetString("Tira")
+	assert.Error(t, err)
+	values, _ := loaded.GetListRange("Cute", 0, -1)
+	assert.Equal(t, []string{"Void", "Scary"}, values)
+}
+
+func Test_startup_keepsExpirationsAfterYear2262(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	aof, _, db := persistedDb(t, dir)
+	execute(db, "SET", "Tira", "Misu")
+	execute(db, "PEXPIREAT", "Tira", "10000000000000")
+	done, _ := db.SaveSnapshot()
+	if err := <-done; err != nil {
+		t.Error(err)
+		return
+	}
+	execute(db, "SET", "Cute", "Void", "PXAT", "10000000000000")
+	aof.Close()
+
+	// when
+	reopened, _, loaded := persistedDb(t, dir)
+	defer reopened.Close()
+
+	// then
+	for _, key := range []string{"Tira", "Cute"} {
+		value, err := loaded.GetString(key)
+		assert.NoError(t, err, key)
+		assert.Equal(t, int64(10000000000000), value.Expiration.ExpiresAt.UnixMilli(), key)
+	}
+}
+
+func Test_startup_ignoresSnapshotOfOtherAof(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	aof, _, db := persistedDb(t, dir)
+	execute(db, "SET", "Tira", "Misu")
+	done, _ := db.SaveSnapshot()
+	<-done
+	aof.Close()
+	os.WriteFile(filepath.Join(dir, "database.aof"), resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{
+		{Typ: resp.BULK.Typ, Bulk: []byte("SET")},
+		{Typ: resp.BULK.Typ, Bulk: []byte("Misu")},
+		{Typ: resp.BULK.Typ, Bulk: []byte("Cute")},
+	}}.Marshal(), 0666)
+
+	// when
+	reopened, _, loaded := persistedDb(t, dir)
+	defer reopened.Close()
+
+	// then
+	_, err := loaded.GetString("Tira")
+	assert.Error(t, err)
+	value, _ := loaded.GetString("Misu")
+	assert.Equal(t, "Cute", value.Value)
+}
+
+func Test_startup_restoresSnapshotIntoEmptyAof(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	aof, _, db := persistedDb(t, dir)
+	execute(db, "SET", "Tira", "Misu")
+	done, _ := db.SaveSnapshot()
+	<-done
+	aof.Close()
+	os.Remove(filepath.Join(dir, "database.aof"))
+
+	// when
+	reopened, _, loaded := persistedDb(t, dir)
+	defer reopened.Close()
+	commands, _ := readAll(reopened)
+
+	// then
+	value, _ := loaded.GetString("Tira")
+	assert.Equal(t, "Misu", value.Value)
+	assert.Len(t, commands, 1)
+}
+
+func Test_startup_replaysTransactionAsBlock(t *testing.T) {
+	// given
+	db := defaultDb()
+	disk := defaultDisk([]resp.Value{
+		bulkCommand("MULTI"),
+		bulkCommand("SET", "Tira", "Misu"),
+		bulkCommand("RPUSH", "Cute", "Void"),
+		bulkCommand("EXEC"),
+		bulkCommand("MULTI"),
+		bulkCommand("SET", "Misu", "Cute"),
+	})
+
+	// when
+	err := ReplayCommands(disk, db)
+
+	// then
+	assert.NoError(t, err)
+	value, _ := db.GetString("Tira")
+	assert.Equal(t, "Misu", value.Value)
+	values, _ := db.GetListRange("Cute", 0, -1)
+	assert.Equal(t, []string{"Void"}, values)
+	// the last transaction is incomplete, so it is dropped as a whole
+	_, err = db.GetString("Misu")
+	assert.Error(t, err)
+}
+
+func Test_startup_failsOnExecWithoutMulti(t *testing.T) {
+	// given
+	disk := defaultDisk([]resp.Value{bulkCommand("SET", "Tira", "Misu"), bulkCommand("EXEC")})
+
+	// when
+	err := ReplayCommands(disk, defaultDb())
+
+	// then
+	assert.ErrorContains(t, err, "Command 1 (EXEC)")
+}
+
 func defaultDb() persistence.Database {
 	return persistence.NewDatabase(nil)
 }
@@ -178,10 +504,51 @@ func (_ simpleDisk) Save(resp.Value) error {
 	return errors.New("Save called but shouldnt be by the startup")
 }
 
-func (d simpleDisk) ReadPersistedCommands() ([]resp.Value, error) {
-	return d.request, nil
+func (d simpleDisk) ReplayPersistedCommands(replay func(resp.Value, int64) error) error {
+	for _, value := range d.request {
+		if err := replay(value, int64(len(value.Marshal()))); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (_ simpleDisk) Close() error {
 	return errors.New("Save called but shouldnt be by the startup")
 }
+
+func persistedDb(t *testing.T, dir string) (*persistence.Aof, *persistence.Snapshot, persistence.Database) {
+	aof, err := persistence.NewAof(filepath.Join(dir, "database.aof"), persistence.DefaultAofOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := persistence.NewSnapshot(filepath.Join(dir, "database.snapshot"), nil)
+
+	db := defaultDb()
+	if err := LoadPersistence(snapshot, aof, db); err != nil {
+		t.Fatal(err)
+	}
+
+	return aof, snapshot, db
+}
+
+func execute(db persistence.Database, args ...string) resp.Value {
+	return command.Strategies[args[0]](bulkCommand(args...), db)
+}
+
+func readAll(disk persistence.DiskPersistence) ([]resp.Value, error) {
+	values := []resp.Value{}
+	err := disk.ReplayPersistedCommands(func(value resp.Value, _ int64) error {
+		values = append(values, value)
+		return nil
+	})
+	return values, err
+}
+
+func bulkCommand(args ...string) resp.Value {
+	value := resp.Value{Typ: resp.ARRAY.Typ}
+	for _, arg := range args {
+		value.Array = append(value.Array, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(arg)})
+	}
+	return value
+}
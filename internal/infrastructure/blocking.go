@@ -0,0 +1,171 @@
+package infrastructure
+
+import (
+	"errors"
+	"gocache/internal/core/command"
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"log"
+	"math"
+	"strconv"
+	"time"
+)
+
+var errTimeoutNotFloat = errors.New("ERR timeout is not a float or out of range")
+var errTimeoutNegative = errors.New("ERR timeout is negative")
+var errSyntax = errors.New("ERR syntax error")
+
+// / Removes and returns the first element of the first non empty list. Blocks until one of the lists gets an element or the timeout in seconds expires. A timeout of 0 blocks forever
+// / BLPOP key [key ...] timeout
+// / Example:
+// / Req: BLPOP tira misu 1.5
+// / Res: [misu, cute]
+func (s *session) blpop(value resp.Value) resp.Value {
+	return s.blockingPop(value, true, command.BLPOP)
+}
+
+// / Removes and returns the last element of the first non empty list. Blocks until one of the lists gets an element or the timeout in seconds expires. A timeout of 0 blocks forever
+// / BRPOP key [key ...] timeout
+// / Example:
+// / Req: BRPOP tira misu 0
+// / Res: [misu, cute]
+func (s *session) brpop(value resp.Value) resp.Value {
+	return s.blockingPop(value, false, command.BRPOP)
+}
+
+func (s *session) blockingPop(value resp.Value, head bool, name string) resp.Value {
+	args := value.GetArgs()
+	if len(args) < 2 {
+		return wrongNumberOfArguments(name)
+	}
+
+	timeout, err := parseTimeout(string(args[len(args)-1].Bulk))
+	if err != nil {
+		return errorValue(err)
+	}
+
+	waiter := persistence.NewListWaiter(bulks(args[:len(args)-1]), head)
+	element, ok := s.block(waiter, timeout)
+	if !ok {
+		return resp.Value{Typ: resp.NULL_ARRAY.Typ}
+	}
+	if element.Err != nil {
+		return errorValue(element.Err)
+	}
+
+	return resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{
+		{Typ: resp.BULK.Typ, Bulk: []byte(element.Key)},
+		{Typ: resp.BULK.Typ, Bulk: []byte(element.Value)},
+	}}
+}
+
+// / Pops an element from source and pushes it to destination. Blocks until source gets an element or the timeout in seconds expires. A timeout of 0 blocks forever
+// / BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout
+// / Example:
+// / Req: BLMOVE tira misu LEFT RIGHT 0
+// / Res: cute
+func (s *session) blmove(value resp.Value) resp.Value {
+	args := value.GetArgs()
+	if len(args) != 5 {
+		return wrongNumberOfArguments(command.BLMOVE)
+	}
+
+	fromHead, ok := command.ParseListSide(string(args[2].Bulk))
+	if !ok {
+		return errorValue(errSyntax)
+	}
+	toHead, ok := command.ParseListSide(string(args[3].Bulk))
+	if !ok {
+		return errorValue(errSyntax)
+	}
+	timeout, err := parseTimeout(string(args[4].Bulk))
+	if err != nil {
+		return errorValue(err)
+	}
+
+	waiter := persistence.NewListMoveWaiter(string(args[0].Bulk), string(args[1].Bulk), fromHead, toHead)
+	element, ok := s.block(waiter, timeout)
+	if !ok {
+		return resp.Value{Typ: resp.NULL.Typ}
+	}
+	if element.Err != nil {
+		return errorValue(element.Err)
+	}
+
+	return resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(element.Value)}
+}
+
+// / Waits until the waiter is served, the timeout expires or the client disconnects. Returns false if the waiter was not served.
+// / Like in redis, a blocking command inside a transaction never blocks
+func (s *session) block(waiter *persistence.ListWaiter, timeout time.Duration) (persistence.ServedElement, bool) {
+	if s.executing {
+		return s.database.PopListOrWait(waiter, false)
+	}
+
+	// the execution lock is only held while trying, so a transaction can serve the waiter later
+	execution.RLock()
+	element, ok := s.database.PopListOrWait(waiter, true)
+	execution.RUnlock()
+	if ok {
+		return element, true
+	}
+
+	// the replies of the commands before must not wait for the blocked one
+	if err := s.flush(); err != nil {
+		log.Println("Sending replies before blocking failed: " + err.Error())
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case element := <-waiter.Served():
+		return element, true
+	case <-expired:
+		return s.database.CancelListWait(waiter)
+	case <-s.disconnected:
+		element, ok := s.database.CancelListWait(waiter)
+		if ok {
+			s.restore(waiter, element)
+		}
+		return persistence.ServedElement{}, false
+	}
+}
+
+// / A client that disconnected right when it was served can not receive the element anymore, so a popped element is pushed back where it came from
+func (s *session) restore(waiter *persistence.ListWaiter, element persistence.ServedElement) {
+	if element.Err != nil || waiter.Moves() {
+		return
+	}
+
+	name := command.RPUSH
+	if waiter.Head() {
+		name = command.LPUSH
+	}
+
+	request := resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{
+		{Typ: resp.BULK.Typ, Bulk: []byte(name)},
+		{Typ: resp.BULK.Typ, Bulk: []byte(element.Key)},
+		{Typ: resp.BULK.Typ, Bulk: []byte(element.Value)},
+	}}
+	if _, err := s.database.PushList(request, element.Key, []string{element.Value}, waiter.Head()); err != nil {
+		log.Println("Could not restore the element of a disconnected client: " + err.Error())
+	}
+}
+
+// / Timeouts are seconds with decimals, like in redis
+func parseTimeout(raw string) (time.Duration, error) {
+	seconds, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(seconds) || seconds > math.MaxInt64/float64(time.Second) {
+		return 0, errTimeoutNotFloat
+	}
+	if seconds < 0 {
+		return 0, errTimeoutNegative
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,173 @@
+package infrastructure
+
+import (
+	"gocache/internal/core/pubsub"
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_blpop_returnsRightAwayIfListHasElements(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	db.PushList(resp.Value{}, "misu", []string{"cute"}, false)
+	client := connectTo(t, db)
+
+	// when
+	result := client.request("BLPOP", "tira", "misu", "0")
+
+	// then
+	assert.Equal(t, string(bulkArray("misu", "cute").Marshal()), result)
+}
+
+func Test_blpop_blocksUntilPush(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	blocked := connectTo(t, db)
+	pusher := connectTo(t, db)
+	replies := make(chan string)
+	go func() { replies <- blocked.request("BLPOP", "tira", "0") }()
+	waitForBlockedClients(t, db, "tira", 1)
+
+	// when
+	pushed := pusher.request("RPUSH", "tira", "misu")
+
+	// then
+	assert.Equal(t, ":1\r\n", pushed)
+	assert.Equal(t, string(bulkArray("tira", "misu").Marshal()), <-replies)
+	assert.Equal(t, "none", db.GetType("tira"))
+}
+
+func Test_brpop_wakesClientsInOrder(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	first := connectTo(t, db)
+	second := connectTo(t, db)
+	pusher := connectTo(t, db)
+	firstReplies := make(chan string)
+	secondReplies := make(chan string)
+	go func() { firstReplies <- first.request("BRPOP", "tira", "0") }()
+	waitForBlockedClients(t, db, "tira", 1)
+	go func() { secondReplies <- second.request("BRPOP", "tira", "0") }()
+	waitForBlockedClients(t, db, "tira", 2)
+
+	// when
+	pusher.request("RPUSH", "tira", "misu", "cute")
+
+	// then
+	assert.Equal(t, string(bulkArray("tira", "cute").Marshal()), <-firstReplies)
+	assert.Equal(t, string(bulkArray("tira", "misu").Marshal()), <-secondReplies)
+}
+
+func Test_blpop_timeout(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	client := connectTo(t, db)
+
+	// when
+	result := client.request("BLPOP", "tira", "0.01")
+	negative := client.request("BLPOP", "tira", "-1")
+	invalid := client.request("BLPOP", "tira", "soon")
+
+	// then
+	assert.Equal(t, "*-1\r\n", result)
+	assert.Equal(t, "-ERR timeout is negative\r\n", negative)
+	assert.Equal(t, "-ERR timeout is not a float or out of range\r\n", invalid)
+	assert.Zero(t, db.BlockedClients("tira"))
+}
+
+func Test_blmove_blocksUntilPush(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	blocked := connectTo(t, db)
+	pusher := connectTo(t, db)
+	replies := make(chan string)
+	go func() { replies <- blocked.request("BLMOVE", "tira", "misu", "RIGHT", "LEFT", "0") }()
+	waitForBlockedClients(t, db, "tira", 1)
+
+	// when
+	pusher.request("LPUSH", "tira", "cute")
+
+	// then
+	assert.Equal(t, "$4\r\ncute\r\n", <-replies)
+	values, _ := db.GetListRange("misu", 0, -1)
+	assert.Equal(t, []string{"cute"}, values)
+}
+
+func Test_blpop_disconnectCleansUpWait(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	client, server := net.Pipe()
+	done := make(chan error)
+	go func() { done <- HandleConnection(server, db, pubsub.NewBroker(), resp.DefaultLimits()) }()
+	client.Write(bulkArray("BLPOP", "tira", "0").Marshal())
+	waitForBlockedClients(t, db, "tira", 1)
+
+	// when
+	client.Close()
+	<-done
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	// then
+	assert.Zero(t, db.BlockedClients("tira"))
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"misu"}, values)
+}
+
+func Test_blpop_disconnectWithPipelinedCommandsCleansUpWait(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	client, server := net.Pipe()
+	done := make(chan error)
+	go func() { done <- HandleConnection(server, db, pubsub.NewBroker(), resp.DefaultLimits()) }()
+	pipeline := bulkArray("BLPOP", "tira", "0").Marshal()
+	for range 3 {
+		pipeline = append(pipeline, bulkArray("PING").Marshal()...)
+	}
+	client.Write(pipeline)
+	waitForBlockedClients(t, db, "tira", 1)
+
+	// when
+	client.Close()
+	<-done
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, false)
+
+	// then
+	assert.Zero(t, db.BlockedClients("tira"))
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"misu"}, values)
+}
+
+func Test_blpop_insideTransactionDoesNotBlock(t *testing.T) {
+	// given
+	session := newSession(persistence.NewDatabase(), pubsub.NewBroker(), nil)
+
+	// when
+	send(session, "MULTI")
+	send(session, "BLPOP", "tira", "0")
+	send(session, "RPUSH", "tira", "misu")
+	send(session, "BRPOP", "tira", "0")
+	result := send(session, "EXEC")
+
+	// then
+	assert.Equal(t, resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{
+		{Typ: resp.NULL_ARRAY.Typ},
+		{Typ: resp.INTEGER.Typ, Num: 1},
+		bulkArray("tira", "misu"),
+	}}, result)
+}
+
+// / Blocking happens in the background, so the tests wait until the clients are queued
+func waitForBlockedClients(t *testing.T, db *persistence.DatabaseImpl, key string, amount int) {
+	for range 100 {
+		if db.BlockedClients(key) == amount {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d clients did not block on %s", amount, key)
+}
@@ -3,56 +3,159 @@ package infrastructure
 import (
 	"errors"
 	"gocache/internal/core/command"
+	"gocache/internal/core/pubsub"
 	"gocache/internal/core/resp"
 	"gocache/internal/persistence"
 	"io"
 	"log"
 	"net"
 	"strings"
+	"sync"
 )
 
-func HandleConnection(connection net.Conn, database persistence.Database) error {
+var errUnknownCommand = errors.New("Command is unknown")
+var errQueryBufferLimit = errors.New("Client reached the query buffer limit")
+
+// / Like client-query-buffer-limit in redis, the commands a client sent that were not handled yet may take up to 1GB
+const maxPendingBytes = 1024 * 1024 * 1024
+
+// / Limits protect the server from clients that announce huge bulks or arrays
+func HandleConnection(connection net.Conn, database persistence.Database, broker *pubsub.Broker, limits resp.Limits) error {
+	session := newSession(database, broker, connection)
+	defer session.close()
+
+	// one reader for the whole connection. A new reader would lose what the previous one already buffered of pipelined commands
+	reader := resp.NewReader(connection)
+	reader.SetLimits(limits)
+	requests := newRequestQueue()
+	go readRequests(reader, requests, session)
+
 	// the server allows long lived connections with many commands, until the client closes the connection
 	for {
-		reader := resp.NewReader(connection)
-		value, err := reader.Read()
-		if err != nil {
-			if err == io.EOF {
+		request := requests.pop()
+		if request.err != nil {
+			if request.err == io.EOF {
 				return nil
 			}
-			return err
+			// like redis, the client learns why it is disconnected
+			if errors.Is(request.err, resp.ErrProtocol) {
+				session.write(resp.Value{Typ: resp.ERROR.Typ, Str: "ERR " + request.err.Error()})
+				session.flush()
+			}
+			return request.err
 		}
-		log.Printf("Received the following Value: %v\n", value)
-		writer := resp.NewWriter(connection)
 
-		if err := verifyValueFormat(value); err != nil {
-			log.Println(err)
-			writer.Write(errorValue(err))
-			continue
+		result := respond(session, request.value)
+		if result.Typ != noReply.Typ {
+			session.write(result)
 		}
 
-		commandName, err := retrieveCommandName(value)
-		if err != nil {
-			log.Println(err)
-			writer.Write(errorValue(err))
-			continue
+		// replies of pipelined commands are collected and sent together, once every received command is answered
+		if !request.pipelined {
+			if err := session.flush(); err != nil {
+				return err
+			}
 		}
+	}
+}
+
+func respond(session *session, value resp.Value) resp.Value {
+	log.Printf("Received the following Value: %v\n", value)
+
+	if err := verifyValueFormat(value); err != nil {
+		log.Println(err)
+		return errorValue(err)
+	}
+
+	commandName, err := retrieveCommandName(value)
+	if err != nil {
+		log.Println(err)
+		return errorValue(err)
+	}
+
+	result := session.handle(commandName, value)
+	if result.Typ == resp.ERROR.Typ {
+		log.Printf("ERROR: Responding with: %#v \n", result.Str)
+	} else if result.Typ != noReply.Typ {
+		log.Printf("Responding with: %v %v\n", result.Typ, result)
+	}
+	return result
+}
 
-		command, err := retrieveCommand(commandName)
+type request struct {
+	value resp.Value
+	err   error
+	// true if the next command was already received, so the reply does not have to be sent right away
+	pipelined bool
+	size      int64
+}
+
+// / Reads while the previous commands still run, so a blocked command notices when the client disconnects, no matter how many commands wait behind it.
+// / The read error is the last request
+func readRequests(reader *resp.Resp, requests *requestQueue, session *session) {
+	consumed := int64(0)
+	for {
+		value, err := reader.ReadCommand()
+		size := reader.Consumed() - consumed
+		consumed += size
+
+		if err == nil && !requests.push(request{value, nil, reader.Buffered() > 0, size}) {
+			err = errQueryBufferLimit
+		}
 		if err != nil {
-			log.Println(err)
-			writer.Write(errorValue(err))
-			continue
+			close(session.disconnected)
+			requests.push(request{err: err})
+			return
 		}
+	}
+}
+
+// / Requests that were read, but not handled yet. Pushing never waits for the handler
+type requestQueue struct {
+	mutex    sync.Mutex
+	requests []request
+	bytes    int64
+	// signalled when a request was pushed to an empty queue
+	ready chan struct{}
+}
 
-		result := command(value, database)
+func newRequestQueue() *requestQueue {
+	return &requestQueue{ready: make(chan struct{}, 1)}
+}
+
+// / Returns false without queueing the request if the pending requests would exceed maxPendingBytes. Read errors are always queued
+func (q *requestQueue) push(request request) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
 
-		if result.Typ == resp.ERROR.Typ {
-			log.Printf("ERROR: Responding with: %#v \n", result.Str)
-		} else {
-			log.Printf("Responding with: %#v %v%v\n", result.Typ, result.Str, result.Bulk)
+	if q.bytes+request.size > maxPendingBytes {
+		return false
+	}
+	q.requests = append(q.requests, request)
+	q.bytes += request.size
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// / Waits for the next request
+func (q *requestQueue) pop() request {
+	for {
+		q.mutex.Lock()
+		if len(q.requests) > 0 {
+			next := q.requests[0]
+			q.requests[0] = request{}
+			q.requests = q.requests[1:]
+			q.bytes -= next.size
+			q.mutex.Unlock()
+			return next
 		}
-		writer.Write(result)
+		q.mutex.Unlock()
+
+		<-q.ready
 	}
 }
 
@@ -69,13 +172,13 @@ func retrieveCommandName(value resp.Value) (string, error) {
 		return "", errors.New("Unable to read command")
 	}
 
-	return strings.ToUpper(commandValue.Bulk), nil
+	return strings.ToUpper(string(commandValue.Bulk)), nil
 }
 
 func retrieveCommand(name string) (command.CommandStrategy, error) {
 	command, ok := command.Strategies[name]
 	if !ok {
-		return nil, errors.New("Command is unknown")
+		return nil, errUnknownCommand
 	}
 
 	return command, nil
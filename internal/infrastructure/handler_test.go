@@ -1,26 +1,45 @@
 package infrastructure
 
 import (
+	"bufio"
 	"errors"
+	"gocache/internal/core/pubsub"
 	"gocache/internal/core/resp"
 	"gocache/internal/persistence"
+	"io"
+	"log"
 	"net"
+	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_handlesConnection_noArray_err(t *testing.T) {
+func Test_handlesConnection_bulkIsReadAsInlineCommand(t *testing.T) {
+	// given
+	client := connectTo(t, defaultDb())
+
+	// when
+	client.connection.Write([]byte("$4\r\nPING\r\n"))
+
+	// then
+	assert.Equal(t, "-"+errUnknownCommand.Error()+"\r\n", client.read())
+	assert.Equal(t, "+PONG\r\n", client.read())
+}
+
+func Test_handlesConnection_unknownCommand_err(t *testing.T) {
 	// given
 	client, server := net.Pipe()
 	defer client.Close()
 	defer server.Close()
 
-	go HandleConnection(server, defaultDb())
+	go HandleConnection(server, defaultDb(), pubsub.NewBroker(), resp.DefaultLimits())
 
 	// when
-	client.Write([]byte("$4\r\nTira\r\n"))
+	client.Write([]byte("*1\r\n$7\r\nUNKNOWN\r\n"))
 
 	// then
 	buf := make([]byte, 1024)
@@ -34,22 +53,26 @@ func Test_handlesConnection_noArray_err(t *testing.T) {
 		t.Error("Response didn't contain an error")
 		return
 	}
-	if !strings.Contains(res, "array") {
+	if !strings.Contains(res, "Command is unknown") {
 		t.Error("Response didn't contain the correct error")
 		return
 	}
 }
 
-func Test_handlesConnection_noBulkInArray_err(t *testing.T) {
+func Test_handlesConnection_ping(t *testing.T) {
 	// given
 	client, server := net.Pipe()
 	defer client.Close()
 	defer server.Close()
 
-	go HandleConnection(server, defaultDb())
+	testDb := defaultDb()
+
+	go HandleConnection(server, testDb, pubsub.NewBroker(), resp.DefaultLimits())
+
+	expectedResponse := "+PONG\r\n"
 
 	// when
-	client.Write([]byte("*1\r\n*1\r\n$4\r\nTira\r\n"))
+	client.Write([]byte("*1\r\n$4\r\nPING\r\n"))
 
 	// then
 	buf := make([]byte, 1024)
@@ -57,72 +80,131 @@ func Test_handlesConnection_noBulkInArray_err(t *testing.T) {
 	if err != nil {
 		t.Error("The client was not able to read the handled connection response")
 	}
-
 	res := string(buf[:length])
-	if !strings.Contains(res, string(resp.ERROR.RespCode)) {
-		t.Error("Response didn't contain an error")
-		return
-	}
-	if !strings.Contains(res, "Unable to read command") {
-		t.Error("Response didn't contain the correct error")
-		return
-	}
+
+	assert.Equal(t, expectedResponse, res)
+	assert.Equal(t, 0, len(testDb.executedCommands))
 }
 
-func Test_handlesConnection_unknownCommand_err(t *testing.T) {
+func Test_handlesConnection_pipelinedCommandsInOneWrite(t *testing.T) {
 	// given
-	client, server := net.Pipe()
-	defer client.Close()
-	defer server.Close()
+	client := connectTo(t, persistence.NewDatabase())
 
-	go HandleConnection(server, defaultDb())
+	pipeline := []byte{}
+	pipeline = append(pipeline, bulkArray("SET", "tira", "misu").Marshal()...)
+	pipeline = append(pipeline, bulkArray("GET", "tira").Marshal()...)
+	pipeline = append(pipeline, bulkArray("DEL", "tira").Marshal()...)
+	pipeline = append(pipeline, bulkArray("PING").Marshal()...)
 
 	// when
-	client.Write([]byte("*1\r\n$7\r\nUNKNOWN\r\n"))
+	client.connection.Write(pipeline)
 
 	// then
-	buf := make([]byte, 1024)
-	length, err := client.Read(buf)
-	if err != nil {
-		t.Error("The client was not able to read the handled connection response")
-	}
+	assert.Equal(t, "+OK\r\n", client.read())
+	assert.Equal(t, "$4\r\nmisu\r\n", client.read())
+	assert.Equal(t, ":1\r\n", client.read())
+	assert.Equal(t, "+PONG\r\n", client.read())
+}
 
-	res := string(buf[:length])
-	if !strings.Contains(res, string(resp.ERROR.RespCode)) {
-		t.Error("Response didn't contain an error")
-		return
+func Test_handlesConnection_thousandsOfPipelinedCommands(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	client := connectTo(t, db)
+	amount := 5000
+
+	pipeline := []byte{}
+	for i := range amount {
+		pipeline = append(pipeline, bulkArray("RPUSH", "tira", strconv.Itoa(i)).Marshal()...)
 	}
-	if !strings.Contains(res, "Command is unknown") {
-		t.Error("Response didn't contain the correct error")
-		return
+
+	// when
+	// the replies are sent while the pipeline is still written, so writing must not block reading
+	go client.connection.Write(pipeline)
+
+	// then
+	for i := range amount {
+		assert.Equal(t, ":"+strconv.Itoa(i+1)+"\r\n", client.read())
 	}
+	length, _ := db.GetListLength("tira")
+	assert.Equal(t, amount, length)
 }
 
-func Test_handlesConnection_ping(t *testing.T) {
+func Test_handlesConnection_commandSplitAcrossWrites(t *testing.T) {
 	// given
-	client, server := net.Pipe()
-	defer client.Close()
-	defer server.Close()
+	client := connectTo(t, persistence.NewDatabase())
+	command := bulkArray("SET", "tira", "misu").Marshal()
 
-	testDb := defaultDb()
+	// when
+	client.connection.Write(command[:7])
+	client.connection.Write(command[7:])
 
-	go HandleConnection(server, testDb)
+	// then
+	assert.Equal(t, "+OK\r\n", client.read())
+}
 
-	expectedResponse := "+PONG\r\n"
+func Test_handlesConnection_pipelinedRepliesAreSentTogether(t *testing.T) {
+	// given
+	client := connectTo(t, persistence.NewDatabase())
+
+	pipeline := []byte{}
+	pipeline = append(pipeline, bulkArray("PING").Marshal()...)
+	pipeline = append(pipeline, bulkArray("PING", "tiramisu").Marshal()...)
 
 	// when
-	client.Write([]byte("*1\r\n$4\r\nPING\r\n"))
+	client.connection.Write(pipeline)
 
 	// then
 	buf := make([]byte, 1024)
-	length, err := client.Read(buf)
-	if err != nil {
-		t.Error("The client was not able to read the handled connection response")
+	length, err := client.connection.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "+PONG\r\n+tiramisu\r\n", string(buf[:length]))
+}
+
+func Test_handlesConnection_inlineCommands(t *testing.T) {
+	// given
+	client := connectTo(t, persistence.NewDatabase())
+
+	// when
+	client.connection.Write([]byte("PING\r\nSET tira \"misu cute\"\r\nGET tira\r\n"))
+
+	// then
+	assert.Equal(t, "+PONG\r\n", client.read())
+	assert.Equal(t, "+OK\r\n", client.read())
+	assert.Equal(t, "$9\r\nmisu cute\r\n", client.read())
+}
+
+func Test_handlesConnection_protocolErrorClosesConnection(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"bulk too long", "*1\r\n$9999999999\r\n", "-ERR Protocol error: invalid bulk length\r\n"},
+		{"array too long", "*2000000\r\n", "-ERR Protocol error: invalid multibulk length\r\n"},
+		{"missing line break", "*1\r\n$4\r\nPINGXX", "-ERR Protocol error: expected CRLF\r\n"},
+		{"nested array", "*1\r\n*1\r\n$4\r\nTira\r\n", "-ERR Protocol error: expected '$', got '*'\r\n"},
+		{"unbalanced quotes", "SET tira \"misu\r\n", "-ERR Protocol error: unbalanced quotes in request\r\n"},
 	}
-	res := string(buf[:length])
 
-	assert.Equal(t, expectedResponse, res)
-	assert.Equal(t, 0, len(testDb.executedCommands))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			client, server := net.Pipe()
+			defer client.Close()
+			done := make(chan error)
+			go func() {
+				done <- HandleConnection(server, persistence.NewDatabase(), pubsub.NewBroker(), resp.DefaultLimits())
+			}()
+			connection := newTestConnection(client)
+
+			// when
+			connection.connection.Write([]byte(test.input))
+
+			// then
+			assert.Equal(t, test.expected, connection.read())
+			assert.ErrorIs(t, <-done, resp.ErrProtocol)
+		})
+	}
 }
 
 type testDatabase struct {
@@ -133,7 +215,30 @@ func defaultDb() testDatabase {
 	return testDatabase{[]resp.Value{}}
 }
 
-func (db testDatabase) EnablePersistence(persistence.DiskPersistence) {}
+func (db testDatabase) EnablePersistence(...persistence.DiskPersistence) {}
+func (db testDatabase) EnableKeyspaceNotifications(persistence.Publisher, persistence.KeyspaceEvents) {
+}
+func (db testDatabase) RewriteAof() (<-chan error, error) {
+	return nil, errors.New("Should never run this unmocked method RewriteAof()")
+}
+func (db testDatabase) AofNeedsRewrite() bool {
+	return false
+}
+func (db testDatabase) SaveSnapshot() (<-chan error, error) {
+	return nil, errors.New("Should never run this unmocked method SaveSnapshot()")
+}
+func (db testDatabase) LastSnapshot() time.Time {
+	return time.Time{}
+}
+func (db testDatabase) SnapshotNeedsSave() bool {
+	return false
+}
+func (db testDatabase) WatchKeys(*persistence.Watch, []string) {}
+func (db testDatabase) UnwatchKeys(*persistence.Watch)         {}
+func (db testDatabase) PersistAsBlock(run func()) error {
+	run()
+	return nil
+}
 func (db testDatabase) Close() error {
 	return errors.New("Should never run this unmocked method Close()")
 }
@@ -142,15 +247,37 @@ func (db testDatabase) SaveString(value resp.Value, _ string, _ persistence.Stri
 	return nil
 }
 
-func (db testDatabase) GetRandomString() (string, persistence.StringEntity, bool) {
-	return "", persistence.StringEntity{}, false
+func (db testDatabase) ExpireSampledKeys(int) (int, int, error) {
+	return 0, 0, nil
 }
 
-func (db testDatabase) DeleteAllStrings(value resp.Value, _ []string) (int, error) {
+func (db testDatabase) DeleteKeys(value resp.Value, _ []string) (int, error) {
 	db.executedCommands = append(db.executedCommands, value)
 	return 1, nil
 }
 
+func (db testDatabase) CountExistingKeys([]string) int {
+	return 0
+}
+
+func (db testDatabase) GetType(string) string {
+	return "none"
+}
+
+func (db testDatabase) SetExpiration(value resp.Value, _ string, _ time.Time, _ persistence.ExpirationOptions) (bool, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return true, nil
+}
+
+func (db testDatabase) GetExpiration(string) (*persistence.Expirationable, bool) {
+	return nil, false
+}
+
+func (db testDatabase) RemoveExpiration(value resp.Value, _ string) (bool, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return true, nil
+}
+
 func (db testDatabase) DeleteAllHashKeys(value resp.Value, _ string, _ []string) (int, error) {
 	db.executedCommands = append(db.executedCommands, value)
 	return 1, nil
@@ -160,6 +287,15 @@ func (db testDatabase) GetString(string) (persistence.StringEntity, error) {
 	return persistence.StringEntity{}, errors.New("Should never run this unmocked method GetSet()")
 }
 
+func (db testDatabase) IncrementString(value resp.Value, _ string, _ int64) (int64, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 1, nil
+}
+
+func (db testDatabase) IncrementStringByFloat(string, float64) (string, error) {
+	return "", errors.New("Should never run this unmocked method IncrementStringByFloat()")
+}
+
 func (db testDatabase) SaveHash(value resp.Value, _ string, _ string, _ string) error {
 	db.executedCommands = append(db.executedCommands, value)
 	return nil
@@ -168,3 +304,203 @@ func (db testDatabase) SaveHash(value resp.Value, _ string, _ string, _ string)
 func (db testDatabase) GetHash(string) (map[string]string, error) {
 	return nil, errors.New("Should never run this unmocked method GetHSet()")
 }
+
+func (db testDatabase) PushList(value resp.Value, _ string, _ []string, _ bool) (int, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 1, nil
+}
+
+func (db testDatabase) PopList(value resp.Value, _ string, _ int, _ bool) ([]string, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return nil, nil
+}
+
+func (db testDatabase) GetListRange(string, int, int) ([]string, error) {
+	return nil, errors.New("Should never run this unmocked method GetListRange()")
+}
+
+func (db testDatabase) GetListLength(string) (int, error) {
+	return 0, nil
+}
+
+func (db testDatabase) SetListElement(value resp.Value, _ string, _ int, _ string) error {
+	db.executedCommands = append(db.executedCommands, value)
+	return nil
+}
+
+func (db testDatabase) RemoveListElements(value resp.Value, _ string, _ int, _ string) (int, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 0, nil
+}
+
+func (db testDatabase) TrimList(value resp.Value, _ string, _ int, _ int) error {
+	db.executedCommands = append(db.executedCommands, value)
+	return nil
+}
+
+func (db testDatabase) MoveList(value resp.Value, _ string, _ string, _ bool, _ bool) (string, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return "", nil
+}
+func (db testDatabase) PopListOrWait(*persistence.ListWaiter, bool) (persistence.ServedElement, bool) {
+	return persistence.ServedElement{}, false
+}
+func (db testDatabase) CancelListWait(*persistence.ListWaiter) (persistence.ServedElement, bool) {
+	return persistence.ServedElement{}, false
+}
+func (db testDatabase) AddToSet(value resp.Value, _ string, _ []string) (int, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 0, nil
+}
+
+func (db testDatabase) RemoveFromSet(value resp.Value, _ string, _ []string) (int, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 0, nil
+}
+
+func (db testDatabase) StoreSet(value resp.Value, _ string, _ []string, _ func([][]string) []string) (int, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 0, nil
+}
+
+func (db testDatabase) GetSetMembers(string) ([]string, error) {
+	return nil, errors.New("Should never run this unmocked method GetSetMembers()")
+}
+
+func (db testDatabase) IsSetMember(string, []string) ([]bool, error) {
+	return nil, nil
+}
+
+func (db testDatabase) GetSetLength(string) (int, error) {
+	return 0, nil
+}
+
+func (db testDatabase) PopSet(string, int) ([]string, error) {
+	return nil, errors.New("Should never run this unmocked method PopSet()")
+}
+
+func (db testDatabase) GetRandomSetMembers(string, int) ([]string, error) {
+	return nil, errors.New("Should never run this unmocked method GetRandomSetMembers()")
+}
+
+func (db testDatabase) AddToSortedSet(value resp.Value, _ string, _ []persistence.ScoredMember, _ persistence.SortedSetAddOptions) (int, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 0, nil
+}
+
+func (db testDatabase) IncrementSortedSetScore(value resp.Value, _ string, _ string, _ float64, _ persistence.SortedSetAddOptions) (float64, bool, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 0, false, nil
+}
+
+func (db testDatabase) RemoveFromSortedSet(value resp.Value, _ string, _ []string) (int, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return 0, nil
+}
+
+func (db testDatabase) PopSortedSet(value resp.Value, _ string, _ int, _ bool) ([]persistence.ScoredMember, error) {
+	db.executedCommands = append(db.executedCommands, value)
+	return nil, nil
+}
+
+func (db testDatabase) GetSortedSetScore(string, string) (float64, error) {
+	return 0, errors.New("Should never run this unmocked method GetSortedSetScore()")
+}
+
+func (db testDatabase) GetSortedSetRank(string, string, bool) (int, float64, error) {
+	return 0, 0, errors.New("Should never run this unmocked method GetSortedSetRank()")
+}
+
+func (db testDatabase) GetSortedSetLength(string) (int, error) {
+	return 0, nil
+}
+
+func (db testDatabase) CountSortedSetByScore(string, persistence.ScoreBound, persistence.ScoreBound) (int, error) {
+	return 0, nil
+}
+
+func (db testDatabase) GetSortedSetRange(string, int, int, bool) ([]persistence.ScoredMember, error) {
+	return nil, errors.New("Should never run this unmocked method GetSortedSetRange()")
+}
+
+func (db testDatabase) GetSortedSetRangeByScore(string, persistence.ScoreBound, persistence.ScoreBound, bool, int, int) ([]persistence.ScoredMember, error) {
+	return nil, errors.New("Should never run this unmocked method GetSortedSetRangeByScore()")
+}
+
+func (db testDatabase) GetSortedSetRangeByLex(string, persistence.LexBound, persistence.LexBound, bool, int, int) ([]persistence.ScoredMember, error) {
+	return nil, errors.New("Should never run this unmocked method GetSortedSetRangeByLex()")
+}
+
+type testConnection struct {
+	connection net.Conn
+	reader     *bufio.Reader
+}
+
+func newTestConnection(client net.Conn) *testConnection {
+	return &testConnection{client, bufio.NewReader(client)}
+}
+
+func connectTo(t *testing.T, db persistence.Database) *testConnection {
+	return connectWith(t, db, pubsub.NewBroker())
+}
+
+func connectWith(t *testing.T, db persistence.Database, broker *pubsub.Broker) *testConnection {
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+	go HandleConnection(server, db, broker, resp.DefaultLimits())
+
+	return newTestConnection(client)
+}
+
+func (c *testConnection) request(args ...string) string {
+	c.connection.Write(bulkArray(args...).Marshal())
+	return c.read()
+}
+
+// / Returns the next reply or pushed message as it was sent
+func (c *testConnection) read() string {
+	line, err := c.reader.ReadString('\n')
+	if err != nil || len(line) < 3 {
+		return line
+	}
+
+	length, _ := strconv.Atoi(line[1 : len(line)-2])
+	switch line[0] {
+	case resp.ARRAY.RespCode, resp.SET.RespCode, resp.PUSH.RespCode:
+		for range length {
+			line += c.read()
+		}
+	case resp.MAP.RespCode:
+		for range 2 * length {
+			line += c.read()
+		}
+	case resp.BULK.RespCode:
+		if length >= 0 {
+			data := make([]byte, length+2)
+			io.ReadFull(c.reader, data)
+			line += string(data)
+		}
+	}
+	return line
+}
+
+func Benchmark_handlesConnection_setAndGet(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go HandleConnection(server, persistence.NewDatabase(), pubsub.NewBroker(), resp.DefaultLimits())
+	connection := newTestConnection(client)
+
+	set := bulkArray("SET", "tira", "misu").Marshal()
+	get := bulkArray("GET", "tira").Marshal()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		connection.connection.Write(set)
+		connection.read()
+		connection.connection.Write(get)
+		connection.read()
+	}
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"gocache/internal/core/resp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	serverName    = "gocache"
+	serverVersion = "1.0.0"
+)
+
+// / Every connection gets an id, which HELLO returns
+var nextClientId atomic.Int64
+
+// / Switches the protocol of the connection and returns information about the server. Without a version the protocol is kept.
+// / AUTH is accepted, but not checked, because gocache has no users
+// / HELLO [protover [AUTH username password] [SETNAME clientname]]
+// / Example:
+// / Req: HELLO 3
+// / Res: {server: gocache, version: 1.0.0, proto: 3, id: 1, mode: standalone, role: master, modules: []}
+func (s *session) hello(value resp.Value) resp.Value {
+	args := value.GetArgs()
+	protocol := s.protocol
+
+	if len(args) > 0 {
+		version, err := strconv.Atoi(string(args[0].Bulk))
+		if err != nil {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR Protocol version is not an integer or out of range"}
+		}
+		if version != int(resp.RESP2) && version != int(resp.RESP3) {
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "NOPROTO unsupported protocol version"}
+		}
+		protocol = resp.Protocol(version)
+	}
+
+	name := s.name
+	for i := 1; i < len(args); i++ {
+		option := strings.ToUpper(string(args[i].Bulk))
+		switch {
+		case option == "AUTH" && i+2 < len(args):
+			i += 2
+		case option == "SETNAME" && i+1 < len(args):
+			name = string(args[i+1].Bulk)
+			i++
+		default:
+			return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR Syntax error in HELLO option '" + string(args[i].Bulk) + "'"}
+		}
+	}
+
+	s.name = name
+	s.setProtocol(protocol)
+
+	return resp.Value{Typ: resp.MAP.Typ, Array: []resp.Value{
+		{Typ: resp.BULK.Typ, Bulk: []byte("server")}, {Typ: resp.BULK.Typ, Bulk: []byte(serverName)},
+		{Typ: resp.BULK.Typ, Bulk: []byte("version")}, {Typ: resp.BULK.Typ, Bulk: []byte(serverVersion)},
+		{Typ: resp.BULK.Typ, Bulk: []byte("proto")}, {Typ: resp.INTEGER.Typ, Num: int(protocol)},
+		{Typ: resp.BULK.Typ, Bulk: []byte("id")}, {Typ: resp.INTEGER.Typ, Num: int(s.id)},
+		{Typ: resp.BULK.Typ, Bulk: []byte("mode")}, {Typ: resp.BULK.Typ, Bulk: []byte("standalone")},
+		{Typ: resp.BULK.Typ, Bulk: []byte("role")}, {Typ: resp.BULK.Typ, Bulk: []byte("master")},
+		{Typ: resp.BULK.Typ, Bulk: []byte("modules")}, {Typ: resp.ARRAY.Typ, Array: []resp.Value{}},
+	}}
+}
+
+// / Replies are marshalled when they are written, so the reply of HELLO is already sent in the new protocol
+func (s *session) setProtocol(protocol resp.Protocol) {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	s.protocol = protocol
+	s.writer.SetProtocol(protocol)
+}
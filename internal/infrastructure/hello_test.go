@@ -0,0 +1,104 @@
+package infrastructure
+
+import (
+	"gocache/internal/core/pubsub"
+	"gocache/internal/persistence"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_hello_switchesToResp3(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	client := connectTo(t, db)
+	client.request("HSET", "tira", "misu", "cute")
+	client.request("ZADD", "scores", "1.5", "tira")
+
+	// when
+	hello := client.request("HELLO", "3")
+	hash := client.request("HGETALL", "tira")
+	score := client.request("ZSCORE", "scores", "tira")
+	missing := client.request("GET", "void")
+
+	// then
+	assert.True(t, strings.HasPrefix(hello, "%7\r\n$6\r\nserver\r\n$7\r\ngocache\r\n"))
+	assert.Contains(t, hello, "$5\r\nproto\r\n:3\r\n")
+	assert.Equal(t, "%1\r\n$4\r\nmisu\r\n$4\r\ncute\r\n", hash)
+	assert.Equal(t, ",1.5\r\n", score)
+	assert.Equal(t, "_\r\n", missing)
+}
+
+func Test_hello_withoutVersionKeepsResp2(t *testing.T) {
+	// given
+	client := connectTo(t, persistence.NewDatabase())
+	client.request("ZADD", "scores", "1.5", "tira")
+
+	// when
+	hello := client.request("HELLO")
+	score := client.request("ZSCORE", "scores", "tira")
+
+	// then
+	assert.True(t, strings.HasPrefix(hello, "*14\r\n"))
+	assert.Contains(t, hello, "$5\r\nproto\r\n:2\r\n")
+	assert.Equal(t, "$3\r\n1.5\r\n", score)
+}
+
+func Test_hello_options(t *testing.T) {
+	// given
+	client := connectTo(t, persistence.NewDatabase())
+
+	// when
+	hello := client.request("HELLO", "3", "AUTH", "default", "secret", "SETNAME", "tira")
+
+	// then
+	assert.Contains(t, hello, "$5\r\nproto\r\n:3\r\n")
+}
+
+func Test_hello_invalidArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"unsupported version", []string{"HELLO", "4"}, "-NOPROTO unsupported protocol version\r\n"},
+		{"no number", []string{"HELLO", "three"}, "-ERR Protocol version is not an integer or out of range\r\n"},
+		{"unknown option", []string{"HELLO", "3", "CUTE"}, "-ERR Syntax error in HELLO option 'CUTE'\r\n"},
+		{"incomplete auth", []string{"HELLO", "3", "AUTH", "default"}, "-ERR Syntax error in HELLO option 'AUTH'\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			client := connectTo(t, persistence.NewDatabase())
+
+			// when
+			result := client.request(test.args...)
+			ping := client.request("PING")
+
+			// then
+			assert.Equal(t, test.expected, result)
+			assert.Equal(t, "+PONG\r\n", ping)
+		})
+	}
+}
+
+func Test_hello_resp3SubscriberReceivesPushesAndRunsCommands(t *testing.T) {
+	// given
+	broker := pubsub.NewBroker()
+	subscriber := connectWith(t, persistence.NewDatabase(), broker)
+	publisher := connectWith(t, persistence.NewDatabase(), broker)
+	subscriber.request("HELLO", "3")
+
+	// when
+	subscribed := subscriber.request("SUBSCRIBE", "news")
+	get := subscriber.request("GET", "tira")
+	publisher.request("PUBLISH", "news", "hello")
+	message := subscriber.read()
+
+	// then
+	assert.Equal(t, ">3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n", subscribed)
+	assert.Equal(t, "_\r\n", get)
+	assert.Equal(t, ">3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n", message)
+}
@@ -3,15 +3,68 @@ package infrastructure
 import (
 	"gocache/internal/core/expiration"
 	"gocache/internal/persistence"
+	"log"
 	"time"
 )
 
-// Could theoretically make this configurable but eh
-const amountOfKeys = 10
+// like redis, a cycle may use up to 25% of the time between two cycles
+const expirationBudgetPercentage = 25
 
 func ExpirationJob(delay time.Duration, db persistence.Database) {
+	budget := delay * expirationBudgetPercentage / 100
+	totalExpired := 0
+
+	for {
+		stats := expiration.ActiveExpireCycle(budget, db)
+
+		if stats.Expired > 0 {
+			totalExpired += stats.Expired
+			log.Printf("Expired %d of %d sampled keys in %d rounds, %d keys expired in total\n", stats.Expired, stats.Sampled, stats.Rounds, totalExpired)
+		}
+
+		time.Sleep(delay)
+	}
+}
+
+// / Like auto-aof-rewrite in redis, checks periodically whether the AOF grew enough to be rewritten
+func AofRewriteJob(delay time.Duration, db persistence.Database) {
 	for {
-		expiration.ExpireRandomKeys(amountOfKeys, db)
+		if db.AofNeedsRewrite() {
+			log.Println("Starting automatic AOF rewrite")
+			// a transaction changes the keyspace before its block is persisted, so the state must not be collected in the middle of EXEC
+			execution.RLock()
+			done, err := db.RewriteAof()
+			execution.RUnlock()
+			if err != nil {
+				log.Println("Automatic AOF rewrite failed: " + err.Error())
+			} else if err := <-done; err != nil {
+				log.Println("Automatic AOF rewrite failed: " + err.Error())
+			} else {
+				log.Println("Automatic AOF rewrite finished successfully")
+			}
+		}
+
+		time.Sleep(delay)
+	}
+}
+
+// / Like the save config in redis, checks periodically whether a save rule is fulfilled and takes a snapshot
+func SnapshotJob(delay time.Duration, db persistence.Database) {
+	for {
+		if db.SnapshotNeedsSave() {
+			log.Println("Starting automatic snapshot")
+			execution.RLock()
+			done, err := db.SaveSnapshot()
+			execution.RUnlock()
+			if err != nil {
+				log.Println("Automatic snapshot failed: " + err.Error())
+			} else if err := <-done; err != nil {
+				log.Println("Automatic snapshot failed: " + err.Error())
+			} else {
+				log.Println("Automatic snapshot finished successfully")
+			}
+		}
+
 		time.Sleep(delay)
 	}
 }
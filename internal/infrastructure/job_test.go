@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jobDatabase struct {
+	testDatabase
+	started chan string
+}
+
+func (db jobDatabase) AofNeedsRewrite() bool {
+	return true
+}
+func (db jobDatabase) RewriteAof() (<-chan error, error) {
+	db.started <- "rewrite"
+	return finished(), nil
+}
+func (db jobDatabase) SnapshotNeedsSave() bool {
+	return true
+}
+func (db jobDatabase) SaveSnapshot() (<-chan error, error) {
+	db.started <- "snapshot"
+	return finished(), nil
+}
+
+func finished() <-chan error {
+	done := make(chan error, 1)
+	done <- nil
+	return done
+}
+
+func Test_jobs_waitForRunningTransaction(t *testing.T) {
+	tests := []struct {
+		name string
+		job  func(time.Duration, jobDatabase)
+	}{
+		{"rewrite", func(delay time.Duration, db jobDatabase) { AofRewriteJob(delay, db) }},
+		{"snapshot", func(delay time.Duration, db jobDatabase) { SnapshotJob(delay, db) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			db := jobDatabase{defaultDb(), make(chan string, 1)}
+			execution.Lock()
+
+			// when
+			go test.job(time.Hour, db)
+
+			// then
+			select {
+			case <-db.started:
+				t.Fatal("job started while a transaction was executing")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			execution.Unlock()
+			select {
+			case started := <-db.started:
+				assert.Equal(t, test.name, started)
+			case <-time.After(time.Second):
+				t.Fatal("job did not start after the transaction finished")
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package infrastructure
+
+import (
+	"errors"
+	"gocache/internal/core/command"
+	"gocache/internal/core/pubsub"
+	"gocache/internal/core/resp"
+	"log"
+	"strings"
+)
+
+var errSubscriberDropped = errors.New("Subscriber was dropped, because it could not keep up with the published messages")
+
+// / Returns true while the connection has at least one channel or pattern subscription
+func (s *session) subscribed() bool {
+	return s.subscriber != nil && s.broker.SubscriptionCount(s.subscriber) > 0
+}
+
+// / Like redis, a subscribed connection only accepts commands to manage its subscriptions
+func (s *session) handleSubscribed(name string, value resp.Value) resp.Value {
+	switch name {
+	case command.SUBSCRIBE, command.UNSUBSCRIBE, command.PSUBSCRIBE, command.PUNSUBSCRIBE:
+		return sessionStrategies[name](s, value)
+	case command.PING:
+		args := value.GetArgs()
+		if len(args) > 1 {
+			return wrongNumberOfArguments(command.PING)
+		}
+
+		message := ""
+		if len(args) == 1 {
+			message = string(args[0].Bulk)
+		}
+		return resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{
+			{Typ: resp.BULK.Typ, Bulk: []byte("pong")},
+			{Typ: resp.BULK.Typ, Bulk: []byte(message)},
+		}}
+	}
+
+	return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR Can't execute '" + strings.ToLower(name) + "': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING are allowed in this context"}
+}
+
+// / Creates the subscriber of the connection on the first (un)subscribe and starts forwarding its messages
+func (s *session) ensureSubscriber() *pubsub.Subscriber {
+	if s.subscriber == nil {
+		s.subscriber = pubsub.NewSubscriber(pubsub.DefaultQueueSize)
+		go s.forward(s.subscriber)
+		go s.disconnectWhenDropped(s.subscriber)
+	}
+	return s.subscriber
+}
+
+// / Writes queued messages to the connection until the session is closed
+func (s *session) forward(subscriber *pubsub.Subscriber) {
+	for {
+		select {
+		case message := <-subscriber.Messages():
+			err := s.writeQueued(message)
+			// messages that are already queued are sent together
+			if err == nil && len(subscriber.Messages()) == 0 {
+				err = s.flush()
+			}
+			if err != nil {
+				log.Println("Writing a pushed message failed: " + err.Error())
+			}
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// / A subscriber that fell behind loses its connection, instead of blocking the publishers. Closing the connection also ends a write that is stuck on the client
+func (s *session) disconnectWhenDropped(subscriber *pubsub.Subscriber) {
+	select {
+	case <-subscriber.Dropped():
+		log.Println(errSubscriberDropped)
+		s.broker.Remove(subscriber)
+		s.connection.Close()
+	case <-s.closed:
+	}
+}
+
+// / Subscribes the connection to channels. Each subscription is confirmed with [subscribe, channel, amount of subscriptions]
+// / SUBSCRIBE channel [channel ...]
+// / Example:
+// / Req: SUBSCRIBE news
+// / Res: [subscribe, news, 1]
+func (s *session) subscribe(value resp.Value) resp.Value {
+	args := value.GetArgs()
+	if len(args) == 0 {
+		return wrongNumberOfArguments(command.SUBSCRIBE)
+	}
+
+	s.broker.Subscribe(s.ensureSubscriber(), bulks(args)...)
+	return noReply
+}
+
+// / Unsubscribes the connection from the given channels, or from all channels without arguments
+// / UNSUBSCRIBE [channel [channel ...]]
+// / Example:
+// / Req: UNSUBSCRIBE news
+// / Res: [unsubscribe, news, 0]
+func (s *session) unsubscribe(value resp.Value) resp.Value {
+	s.broker.Unsubscribe(s.ensureSubscriber(), bulks(value.GetArgs())...)
+	return noReply
+}
+
+// / Subscribes the connection to glob patterns. Messages of matching channels arrive as [pmessage, pattern, channel, message]
+// / PSUBSCRIBE pattern [pattern ...]
+// / Example:
+// / Req: PSUBSCRIBE news.*
+// / Res: [psubscribe, news.*, 1]
+func (s *session) psubscribe(value resp.Value) resp.Value {
+	args := value.GetArgs()
+	if len(args) == 0 {
+		return wrongNumberOfArguments(command.PSUBSCRIBE)
+	}
+
+	s.broker.PSubscribe(s.ensureSubscriber(), bulks(args)...)
+	return noReply
+}
+
+// / Unsubscribes the connection from the given patterns, or from all patterns without arguments
+// / PUNSUBSCRIBE [pattern [pattern ...]]
+// / Example:
+// / Req: PUNSUBSCRIBE news.*
+// / Res: [punsubscribe, news.*, 0]
+func (s *session) punsubscribe(value resp.Value) resp.Value {
+	s.broker.PUnsubscribe(s.ensureSubscriber(), bulks(value.GetArgs())...)
+	return noReply
+}
+
+// / Sends a message to all subscribers of the channel and of matching patterns. Returns the amount of receiving subscribers
+// / PUBLISH channel message
+// / Example:
+// / Req: PUBLISH news hello
+// / Res: 2
+func (s *session) publish(value resp.Value) resp.Value {
+	args := value.GetArgs()
+	if len(args) != 2 {
+		return wrongNumberOfArguments(command.PUBLISH)
+	}
+
+	return resp.Value{Typ: resp.INTEGER.Typ, Num: s.broker.Publish(string(args[0].Bulk), string(args[1].Bulk))}
+}
+
+// / Introspects the pub/sub state
+// / PUBSUB CHANNELS [pattern] -> Active channels, optionally filtered by a glob pattern
+// / PUBSUB NUMSUB [channel ...] -> Flat list of channels and their amount of subscribers
+// / PUBSUB NUMPAT -> Amount of patterns with subscribers
+// / Example:
+// / Req: PUBSUB NUMSUB news
+// / Res: [news, 2]
+func (s *session) pubsub(value resp.Value) resp.Value {
+	args := value.GetArgs()
+	if len(args) == 0 {
+		return wrongNumberOfArguments(command.PUBSUB)
+	}
+
+	rawSubCommand := string(args[0].Bulk)
+	subCommand := strings.ToUpper(rawSubCommand)
+	args = args[1:]
+
+	switch subCommand {
+	case "CHANNELS":
+		if len(args) > 1 {
+			return wrongNumberOfArguments(command.PUBSUB + "|" + subCommand)
+		}
+		pattern := ""
+		if len(args) == 1 {
+			pattern = string(args[0].Bulk)
+		}
+
+		channels := s.broker.Channels(pattern)
+		result := make([]resp.Value, len(channels))
+		for i, channel := range channels {
+			result[i] = resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(channel)}
+		}
+		return resp.Value{Typ: resp.ARRAY.Typ, Array: result}
+	case "NUMSUB":
+		channels := bulks(args)
+		counts := s.broker.NumSub(channels...)
+
+		result := make([]resp.Value, 0, 2*len(channels))
+		for i, channel := range channels {
+			result = append(result,
+				resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(channel)},
+				resp.Value{Typ: resp.INTEGER.Typ, Num: counts[i]},
+			)
+		}
+		return resp.Value{Typ: resp.ARRAY.Typ, Array: result}
+	case "NUMPAT":
+		if len(args) != 0 {
+			return wrongNumberOfArguments(command.PUBSUB + "|" + subCommand)
+		}
+		return resp.Value{Typ: resp.INTEGER.Typ, Num: s.broker.NumPat()}
+	}
+
+	return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR unknown subcommand '" + rawSubCommand + "'. Try PUBSUB HELP."}
+}
+
+func bulks(values []resp.Value) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = string(value.Bulk)
+	}
+	return result
+}
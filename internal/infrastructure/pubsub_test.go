@@ -0,0 +1,208 @@
+package infrastructure
+
+import (
+	"gocache/internal/core/pubsub"
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_pubsub_publishedMessageIsPushedToSubscriber(t *testing.T) {
+	// given
+	broker := pubsub.NewBroker()
+	subscriber := connectWith(t, persistence.NewDatabase(), broker)
+	publisher := connectWith(t, persistence.NewDatabase(), broker)
+
+	// when
+	subscribed := subscriber.request("SUBSCRIBE", "news")
+	receivers := publisher.request("PUBLISH", "news", "hello")
+	message := subscriber.read()
+
+	// then
+	assert.Equal(t, subscription("subscribe", "news", 1), subscribed)
+	assert.Equal(t, ":1\r\n", receivers)
+	assert.Equal(t, string(bulkArray("message", "news", "hello").Marshal()), message)
+}
+
+func Test_pubsub_patternSubscription(t *testing.T) {
+	// given
+	broker := pubsub.NewBroker()
+	subscriber := connectWith(t, persistence.NewDatabase(), broker)
+	publisher := connectWith(t, persistence.NewDatabase(), broker)
+
+	// when
+	subscribed := subscriber.request("PSUBSCRIBE", "news.*")
+	publisher.request("PUBLISH", "news.tech", "hello")
+	message := subscriber.read()
+
+	// then
+	assert.Equal(t, subscription("psubscribe", "news.*", 1), subscribed)
+	assert.Equal(t, string(bulkArray("pmessage", "news.*", "news.tech", "hello").Marshal()), message)
+}
+
+func Test_pubsub_subscriberModeOnlyAllowsSubscriptionCommands(t *testing.T) {
+	// given
+	subscriber := connectTo(t, persistence.NewDatabase())
+	subscriber.request("SUBSCRIBE", "news")
+
+	// when
+	get := subscriber.request("GET", "tira")
+	ping := subscriber.request("PING")
+	subscriber.request("UNSUBSCRIBE")
+	afterUnsubscribe := subscriber.request("PING")
+
+	// then
+	assert.Equal(t, "-ERR Can't execute 'get': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING are allowed in this context\r\n", get)
+	assert.Equal(t, string(bulkArray("pong", "").Marshal()), ping)
+	assert.Equal(t, "+PONG\r\n", afterUnsubscribe)
+}
+
+func Test_pubsub_unsubscribeAllConfirmsEveryChannel(t *testing.T) {
+	// given
+	subscriber := connectTo(t, persistence.NewDatabase())
+	subscriber.request("SUBSCRIBE", "tira", "misu")
+	subscriber.read()
+
+	// when
+	first := subscriber.request("UNSUBSCRIBE")
+	second := subscriber.read()
+	withoutSubscription := subscriber.request("UNSUBSCRIBE")
+
+	// then
+	assert.Equal(t, subscription("unsubscribe", "misu", 1), first)
+	assert.Equal(t, subscription("unsubscribe", "tira", 0), second)
+	assert.Equal(t, "*3\r\n$11\r\nunsubscribe\r\n$-1\r\n:0\r\n", withoutSubscription)
+}
+
+func Test_pubsub_introspection(t *testing.T) {
+	// given
+	broker := pubsub.NewBroker()
+	subscriber := connectWith(t, persistence.NewDatabase(), broker)
+	subscriber.request("SUBSCRIBE", "news.tech", "weather")
+	subscriber.read()
+	subscriber.request("PSUBSCRIBE", "news.*")
+	client := connectWith(t, persistence.NewDatabase(), broker)
+
+	// when
+	channels := client.request("PUBSUB", "CHANNELS")
+	filtered := client.request("PUBSUB", "channels", "news.*")
+	numsub := client.request("PUBSUB", "NUMSUB", "weather", "sports")
+	numpat := client.request("PUBSUB", "NUMPAT")
+	unknown := client.request("PUBSUB", "tiramisu")
+
+	// then
+	assert.Equal(t, string(bulkArray("news.tech", "weather").Marshal()), channels)
+	assert.Equal(t, string(bulkArray("news.tech").Marshal()), filtered)
+	assert.Equal(t, "*4\r\n$7\r\nweather\r\n:1\r\n$6\r\nsports\r\n:0\r\n", numsub)
+	assert.Equal(t, ":1\r\n", numpat)
+	assert.Equal(t, "-ERR unknown subcommand 'tiramisu'. Try PUBSUB HELP.\r\n", unknown)
+}
+
+func Test_pubsub_publishInsideTransaction(t *testing.T) {
+	// given
+	broker := pubsub.NewBroker()
+	subscriber := pubsub.NewSubscriber(pubsub.DefaultQueueSize)
+	broker.Subscribe(subscriber, "news")
+	<-subscriber.Messages()
+	session := newSession(persistence.NewDatabase(), broker, nil)
+
+	// when
+	send(session, "MULTI")
+	queued := send(session, "PUBLISH", "news", "hello")
+	subscribe := send(session, "SUBSCRIBE", "news")
+	result := send(session, "EXEC")
+
+	// then
+	assert.Equal(t, queuedResponse, queued)
+	assert.Equal(t, errorValue(errNotAllowedInsideMulti), subscribe)
+	assert.Equal(t, errorValue(errExecAbort), result)
+	assert.Empty(t, subscriber.Messages())
+}
+
+func Test_pubsub_droppedSubscriberIsDisconnected(t *testing.T) {
+	// given
+	broker := pubsub.NewBroker()
+	client, server := net.Pipe()
+	defer client.Close()
+	done := make(chan error)
+	go func() { done <- HandleConnection(server, persistence.NewDatabase(), broker, resp.DefaultLimits()) }()
+	connection := newTestConnection(client)
+	connection.request("SUBSCRIBE", "news")
+
+	// when
+	// nothing reads the pushed messages, so the queue overflows
+	for i := 0; i <= pubsub.DefaultQueueSize+1; i++ {
+		broker.Publish("news", "hello")
+	}
+
+	// then
+	<-done
+	assert.Equal(t, []int{0}, broker.NumSub("news"))
+}
+
+func Test_pubsub_repliesAreNotDroppedLikeMessages(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup [][]string
+	}{
+		{"after unsubscribe", [][]string{{"SUBSCRIBE", "news"}, {"UNSUBSCRIBE"}}},
+		{"subscribed with RESP3", [][]string{{"HELLO", "3"}, {"SUBSCRIBE", "news"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			client := connectTo(t, persistence.NewDatabase())
+			for _, args := range test.setup {
+				client.request(args...)
+			}
+			amount := 3 * pubsub.DefaultQueueSize
+
+			pipeline := []byte{}
+			for range amount {
+				pipeline = append(pipeline, bulkArray("PING").Marshal()...)
+			}
+
+			// when
+			go client.connection.Write(pipeline)
+			// the client does not read for a while, so a queue for the replies would overflow
+			time.Sleep(100 * time.Millisecond)
+
+			// then
+			for range amount {
+				assert.Equal(t, "+PONG\r\n", client.read())
+			}
+		})
+	}
+}
+
+func Test_pubsub_replyFollowsConfirmation(t *testing.T) {
+	// given
+	client := connectTo(t, persistence.NewDatabase())
+	client.request("HELLO", "3")
+
+	// when
+	client.connection.Write(append(bulkArray("SUBSCRIBE", "news").Marshal(), bulkArray("PING").Marshal()...))
+
+	// then
+	assert.Equal(t, ">3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n", client.read())
+	assert.Equal(t, "+PONG\r\n", client.read())
+}
+
+func bulkArray(values ...string) resp.Value {
+	array := resp.Value{Typ: resp.ARRAY.Typ}
+	for _, value := range values {
+		array.Array = append(array.Array, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(value)})
+	}
+	return array
+}
+
+func subscription(kind string, name string, count int) string {
+	value := bulkArray(kind, name)
+	value.Array = append(value.Array, resp.Value{Typ: resp.INTEGER.Typ, Num: count})
+	return string(value.Marshal())
+}
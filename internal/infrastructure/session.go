@@ -0,0 +1,197 @@
+package infrastructure
+
+import (
+	"bufio"
+	"gocache/internal/core/command"
+	"gocache/internal/core/pubsub"
+	"gocache/internal/core/resp"
+	"gocache/internal/persistence"
+	"io"
+	"log"
+	"strings"
+	"sync"
+)
+
+// / Commands that need the state of the connection or the broker instead of only the database
+var sessionStrategies map[string]func(*session, resp.Value) resp.Value
+
+// filled on init, because EXEC runs the other session strategies itself
+func init() {
+	sessionStrategies = map[string]func(*session, resp.Value) resp.Value{
+		command.MULTI:        (*session).multi,
+		command.EXEC:         (*session).exec,
+		command.DISCARD:      (*session).discard,
+		command.WATCH:        (*session).watchKeys,
+		command.UNWATCH:      (*session).unwatchKeys,
+		command.SUBSCRIBE:    (*session).subscribe,
+		command.UNSUBSCRIBE:  (*session).unsubscribe,
+		command.PSUBSCRIBE:   (*session).psubscribe,
+		command.PUNSUBSCRIBE: (*session).punsubscribe,
+		command.PUBLISH:      (*session).publish,
+		command.PUBSUB:       (*session).pubsub,
+		command.BLPOP:        (*session).blpop,
+		command.BRPOP:        (*session).brpop,
+		command.BLMOVE:       (*session).blmove,
+		command.HELLO:        (*session).hello,
+	}
+}
+
+// / Returned by commands whose replies are written by the session itself, e.g. the confirmations of SUBSCRIBE
+var noReply = resp.Value{}
+
+// / State of a single client connection, which lives as long as the connection
+type session struct {
+	database   persistence.Database
+	broker     *pubsub.Broker
+	connection io.WriteCloser
+
+	id   int64
+	name string
+	// switched with HELLO, the writer sends the replies in this protocol
+	protocol resp.Protocol
+
+	// replies and pushed messages are written from different goroutines
+	writeMutex sync.Mutex
+	buffer     *bufio.Writer
+	writer     *resp.Writer
+
+	// nil while no transaction was started with MULTI
+	transaction *transaction
+	watch       *persistence.Watch
+
+	// nil until the first subscription. forwarded counts the queued values that were written, protected by the write mutex
+	subscriber *pubsub.Subscriber
+	forwarded  int64
+	wroteQueue *sync.Cond
+
+	// true while EXEC runs the queued commands, which must not block
+	executing bool
+
+	// closed once the client is gone, so blocked commands stop waiting
+	disconnected chan struct{}
+	closed       chan struct{}
+}
+
+func newSession(database persistence.Database, broker *pubsub.Broker, connection io.WriteCloser) *session {
+	buffer := bufio.NewWriter(connection)
+
+	s := &session{
+		database:     database,
+		broker:       broker,
+		connection:   connection,
+		id:           nextClientId.Add(1),
+		protocol:     resp.RESP2,
+		buffer:       buffer,
+		writer:       resp.NewWriter(buffer),
+		watch:        persistence.NewWatch(),
+		disconnected: make(chan struct{}),
+		closed:       make(chan struct{}),
+	}
+	s.wroteQueue = sync.NewCond(&s.writeMutex)
+
+	return s
+}
+
+// / Runs a single command in the context of this connection
+func (s *session) handle(name string, value resp.Value) resp.Value {
+	// RESP3 clients receive pushed messages apart from replies, so they can keep using every command
+	if s.subscribed() && s.protocol == resp.RESP2 {
+		return s.handleSubscribed(name, value)
+	}
+
+	switch name {
+	case command.MULTI, command.EXEC, command.DISCARD, command.WATCH:
+		return sessionStrategies[name](s, value)
+	}
+
+	if s.transaction != nil {
+		return s.queue(name, value)
+	}
+
+	return s.run(name, value)
+}
+
+func (s *session) run(name string, value resp.Value) resp.Value {
+	if strategy, ok := sessionStrategies[name]; ok {
+		return strategy(s, value)
+	}
+
+	execution.RLock()
+	defer execution.RUnlock()
+
+	return s.runLocked(name, value)
+}
+
+// / Runs the command while the caller already holds the execution lock
+func (s *session) runLocked(name string, value resp.Value) resp.Value {
+	if strategy, ok := sessionStrategies[name]; ok {
+		return strategy(s, value)
+	}
+
+	strategy, err := retrieveCommand(name)
+	if err != nil {
+		log.Println(err)
+		return errorValue(err)
+	}
+
+	return strategy(value, s.database)
+}
+
+// / Writes a reply. Replies are never queued for the subscriber, so they are not dropped, but they wait until the values queued before are written,
+// / e.g. the confirmation of SUBSCRIBE
+func (s *session) write(value resp.Value) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	if s.subscriber != nil {
+		// the queue is only written by forward, so the values keep their order. Values queued later do not delay the reply
+		queued := s.subscriber.Queued()
+		for s.forwarded < queued {
+			s.wroteQueue.Wait()
+		}
+	}
+
+	return s.writer.Write(value)
+}
+
+// / Writes a value that was queued for the subscriber
+func (s *session) writeQueued(value resp.Value) error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	s.forwarded++
+	s.wroteQueue.Broadcast()
+
+	return s.writer.Write(value)
+}
+
+// / Sends the buffered replies to the client
+func (s *session) flush() error {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	return s.buffer.Flush()
+}
+
+// / Releases everything the connection still holds
+func (s *session) close() {
+	s.database.UnwatchKeys(s.watch)
+
+	if s.subscriber != nil {
+		s.broker.Remove(s.subscriber)
+	}
+	close(s.closed)
+}
+
+// / Returns true if the command is known, either to the session or as a strategy
+func isKnownCommand(name string) bool {
+	if _, ok := sessionStrategies[name]; ok {
+		return true
+	}
+	_, err := retrieveCommand(name)
+	return err == nil
+}
+
+func wrongNumberOfArguments(name string) resp.Value {
+	return resp.Value{Typ: resp.ERROR.Typ, Str: "ERR wrong number of arguments for '" + strings.ToLower(name) + "' command"}
+}
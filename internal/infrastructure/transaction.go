@@ -0,0 +1,185 @@
+package infrastructure
+
+import (
+	"errors"
+	"gocache/internal/core/command"
+	"gocache/internal/core/resp"
+	"log"
+	"strings"
+	"sync"
+)
+
+// / Commands of different connections run concurrently. EXEC takes this lock exclusively, so no other command runs in the middle of a transaction
+var execution sync.RWMutex
+
+var errNestedMulti = errors.New("ERR MULTI calls can not be nested")
+var errExecWithoutMulti = errors.New("ERR EXEC without MULTI")
+var errDiscardWithoutMulti = errors.New("ERR DISCARD without MULTI")
+var errWatchInsideMulti = errors.New("ERR WATCH inside MULTI is not allowed")
+var errExecAbort = errors.New("EXECABORT Transaction discarded because of previous errors.")
+var errNotAllowedInsideMulti = errors.New("ERR Command not allowed inside a transaction")
+
+type transaction struct {
+	queued []resp.Value
+	// like redis, a transaction with a command that could not be queued is discarded on EXEC
+	failed bool
+}
+
+var queuedResponse = resp.Value{Typ: resp.STRING.Typ, Str: "QUEUED"}
+var okResponse = resp.Value{Typ: resp.STRING.Typ, Str: "OK"}
+
+// / Starts a transaction. Following commands are queued until EXEC or DISCARD
+// / MULTI
+// / Example:
+// / Req: MULTI
+// / Res: OK
+func (s *session) multi(value resp.Value) resp.Value {
+	if len(value.GetArgs()) != 0 {
+		return wrongNumberOfArguments(command.MULTI)
+	}
+	if s.transaction != nil {
+		return errorValue(errNestedMulti)
+	}
+
+	s.transaction = &transaction{}
+	return okResponse
+}
+
+// / Validates the command and queues it for EXEC
+func (s *session) queue(name string, value resp.Value) resp.Value {
+	switch name {
+	// like redis, SAVE would block every other client until the snapshot is written
+	case command.SUBSCRIBE, command.UNSUBSCRIBE, command.PSUBSCRIBE, command.PUNSUBSCRIBE, command.SAVE:
+		s.transaction.failed = true
+		return errorValue(errNotAllowedInsideMulti)
+	}
+	if !isKnownCommand(name) {
+		s.transaction.failed = true
+		return errorValue(errUnknownCommand)
+	}
+	if arity, ok := command.Arity(name); ok && !command.MatchesArity(arity, len(value.Array)) {
+		s.transaction.failed = true
+		return wrongNumberOfArguments(name)
+	}
+
+	s.transaction.queued = append(s.transaction.queued, value)
+	return queuedResponse
+}
+
+// / Runs all queued commands atomically and returns their results. If a watched key was modified, nothing is run and the result is a null array
+// / EXEC
+// / Example:
+// / Req: EXEC
+// / Res: [OK, 1]
+func (s *session) exec(value resp.Value) resp.Value {
+	if len(value.GetArgs()) != 0 {
+		return wrongNumberOfArguments(command.EXEC)
+	}
+	if s.transaction == nil {
+		return errorValue(errExecWithoutMulti)
+	}
+
+	transaction := s.transaction
+	s.transaction = nil
+	defer s.database.UnwatchKeys(s.watch)
+
+	if transaction.failed {
+		return errorValue(errExecAbort)
+	}
+
+	execution.Lock()
+	defer execution.Unlock()
+
+	if s.watch.Dirty() {
+		return resp.Value{Typ: resp.NULL_ARRAY.Typ}
+	}
+
+	s.executing = true
+	defer func() { s.executing = false }()
+
+	results := make([]resp.Value, 0, len(transaction.queued))
+	// the state of a background rewrite or save must not contain the transaction before its block is persisted, otherwise the block is replayed twice
+	background := []int{}
+	err := s.database.PersistAsBlock(func() {
+		for i, queued := range transaction.queued {
+			name := strings.ToUpper(string(queued.Array[0].Bulk))
+			switch name {
+			// the watch is released after EXEC anyway
+			case command.UNWATCH:
+				results = append(results, okResponse)
+				continue
+			case command.BGREWRITEAOF, command.BGSAVE:
+				background = append(background, i)
+				results = append(results, resp.Value{})
+				continue
+			}
+
+			results = append(results, s.runLocked(name, queued))
+		}
+	})
+	if err != nil {
+		log.Println("Persisting the transaction failed: " + err.Error())
+	}
+
+	for _, i := range background {
+		queued := transaction.queued[i]
+		results[i] = s.runLocked(strings.ToUpper(string(queued.Array[0].Bulk)), queued)
+	}
+
+	return resp.Value{Typ: resp.ARRAY.Typ, Array: results}
+}
+
+// / Throws away all queued commands and releases the watched keys
+// / DISCARD
+// / Example:
+// / Req: DISCARD
+// / Res: OK
+func (s *session) discard(value resp.Value) resp.Value {
+	if len(value.GetArgs()) != 0 {
+		return wrongNumberOfArguments(command.DISCARD)
+	}
+	if s.transaction == nil {
+		return errorValue(errDiscardWithoutMulti)
+	}
+
+	s.transaction = nil
+	s.database.UnwatchKeys(s.watch)
+	return okResponse
+}
+
+// / Watches keys, so the next EXEC is aborted if any of them is modified before
+// / WATCH key [key ...]
+// / Example:
+// / Req: WATCH tira misu
+// / Res: OK
+func (s *session) watchKeys(value resp.Value) resp.Value {
+	args := value.GetArgs()
+	if len(args) == 0 {
+		return wrongNumberOfArguments(command.WATCH)
+	}
+	if s.transaction != nil {
+		return errorValue(errWatchInsideMulti)
+	}
+
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		keys[i] = string(arg.Bulk)
+	}
+
+	s.database.WatchKeys(s.watch, keys)
+	return okResponse
+}
+
+// / Forgets all watched keys
+// / UNWATCH
+// / Example:
+// / Req: UNWATCH
+// / Res: OK
+func (s *session) unwatchKeys(value resp.Value) resp.Value {
+	if len(value.GetArgs()) != 0 {
+		return wrongNumberOfArguments(command.UNWATCH)
+	}
+
+	s.database.UnwatchKeys(s.watch)
+	return okResponse
+}
@@ -0,0 +1,241 @@
+package infrastructure
+
+import (
+	"gocache/internal/core/pubsub"
+	"gocache/internal/core/resp"
+	"gocache/internal/core/startup"
+	"gocache/internal/persistence"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_transaction_execRunsQueuedCommands(t *testing.T) {
+	// given
+	session := newSession(persistence.NewDatabase(), pubsub.NewBroker(), nil)
+
+	// when
+	multi := send(session, "MULTI")
+	queued := send(session, "SET", "tira", "misu")
+	send(session, "GET", "tira")
+	result := send(session, "EXEC")
+
+	// then
+	assert.Equal(t, okResponse, multi)
+	assert.Equal(t, queuedResponse, queued)
+	assert.Equal(t, resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{
+		okResponse,
+		{Typ: resp.BULK.Typ, Bulk: []byte("misu")},
+	}}, result)
+}
+
+func Test_transaction_commandsAreNotRunBeforeExec(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	session := newSession(db, pubsub.NewBroker(), nil)
+
+	// when
+	send(session, "MULTI")
+	send(session, "SET", "tira", "misu")
+
+	// then
+	_, err := db.GetString("tira")
+	assert.Error(t, err)
+}
+
+func Test_transaction_discardDropsQueuedCommands(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	session := newSession(db, pubsub.NewBroker(), nil)
+	send(session, "MULTI")
+	send(session, "SET", "tira", "misu")
+
+	// when
+	result := send(session, "DISCARD")
+
+	// then
+	assert.Equal(t, okResponse, result)
+	assert.Equal(t, errorValue(errExecWithoutMulti), send(session, "EXEC"))
+	_, err := db.GetString("tira")
+	assert.Error(t, err)
+}
+
+func Test_transaction_queueErrorAbortsExec(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	session := newSession(db, pubsub.NewBroker(), nil)
+	send(session, "MULTI")
+	send(session, "SET", "tira", "misu")
+
+	// when
+	unknown := send(session, "MISU")
+	arity := send(session, "GET")
+	result := send(session, "EXEC")
+
+	// then
+	assert.Equal(t, resp.ERROR.Typ, unknown.Typ)
+	assert.Equal(t, wrongNumberOfArguments("GET"), arity)
+	assert.Equal(t, errorValue(errExecAbort), result)
+	_, err := db.GetString("tira")
+	assert.Error(t, err)
+}
+
+func Test_transaction_watchAbortsExecWhenKeyWasModified(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	session := newSession(db, pubsub.NewBroker(), nil)
+	other := newSession(db, pubsub.NewBroker(), nil)
+	send(session, "WATCH", "tira")
+	send(session, "MULTI")
+	send(session, "SET", "tira", "misu")
+
+	// when
+	send(other, "SET", "tira", "cute")
+	result := send(session, "EXEC")
+
+	// then
+	assert.Equal(t, resp.Value{Typ: resp.NULL_ARRAY.Typ}, result)
+	value, _ := db.GetString("tira")
+	assert.Equal(t, "cute", value.Value)
+}
+
+func Test_transaction_watchIsReleasedAfterExec(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	session := newSession(db, pubsub.NewBroker(), nil)
+	send(session, "WATCH", "tira")
+	send(session, "SET", "tira", "cute")
+	send(session, "MULTI")
+	send(session, "EXEC")
+
+	// when
+	send(session, "MULTI")
+	send(session, "SET", "tira", "misu")
+	result := send(session, "EXEC")
+
+	// then
+	assert.Equal(t, resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{okResponse}}, result)
+}
+
+func Test_transaction_unwatchKeepsExecRunning(t *testing.T) {
+	// given
+	db := persistence.NewDatabase()
+	session := newSession(db, pubsub.NewBroker(), nil)
+	send(session, "WATCH", "tira")
+	send(session, "UNWATCH")
+	send(session, "SET", "tira", "cute")
+	send(session, "MULTI")
+	send(session, "GET", "tira")
+
+	// when
+	result := send(session, "EXEC")
+
+	// then
+	assert.Equal(t, resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{{Typ: resp.BULK.Typ, Bulk: []byte("cute")}}}, result)
+}
+
+func Test_transaction_invalidUsage(t *testing.T) {
+	// given
+	session := newSession(persistence.NewDatabase(), pubsub.NewBroker(), nil)
+
+	// when
+	exec := send(session, "EXEC")
+	discard := send(session, "DISCARD")
+	send(session, "MULTI")
+	nested := send(session, "MULTI")
+	watch := send(session, "WATCH", "tira")
+
+	// then
+	assert.Equal(t, errorValue(errExecWithoutMulti), exec)
+	assert.Equal(t, errorValue(errDiscardWithoutMulti), discard)
+	assert.Equal(t, errorValue(errNestedMulti), nested)
+	assert.Equal(t, errorValue(errWatchInsideMulti), watch)
+}
+
+func send(session *session, args ...string) resp.Value {
+	value := resp.Value{Typ: resp.ARRAY.Typ}
+	for _, arg := range args {
+		value.Array = append(value.Array, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(arg)})
+	}
+	return session.handle(strings.ToUpper(args[0]), value)
+}
+
+// / Waits for background rewrites and saves, so a test can check the files right after the command
+type waitingDatabase struct {
+	persistence.Database
+}
+
+func (db waitingDatabase) RewriteAof() (<-chan error, error) {
+	return waitFor(db.Database.RewriteAof())
+}
+func (db waitingDatabase) SaveSnapshot() (<-chan error, error) {
+	return waitFor(db.Database.SaveSnapshot())
+}
+
+func waitFor(done <-chan error, err error) (<-chan error, error) {
+	if err != nil {
+		return nil, err
+	}
+	finished := make(chan error, 1)
+	finished <- <-done
+	return finished, nil
+}
+
+func Test_transaction_backgroundPersistenceRunsAfterTheBlock(t *testing.T) {
+	for _, name := range []string{"BGREWRITEAOF", "BGSAVE"} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			dir := t.TempDir()
+			aof, snapshot, db := loadPersisted(t, dir)
+			session := newSession(waitingDatabase{db}, pubsub.NewBroker(), nil)
+			send(session, "SET", "c", "1")
+
+			// when
+			send(session, "MULTI")
+			send(session, "INCR", "c")
+			send(session, name)
+			result := send(session, "EXEC")
+			aof.Close()
+			snapshot.Close()
+			_, _, restarted := loadPersisted(t, dir)
+
+			// then
+			assert.Equal(t, resp.Value{Typ: resp.INTEGER.Typ, Num: 2}, result.Array[0])
+			assert.Equal(t, resp.STRING.Typ, result.Array[1].Typ)
+			value, err := restarted.GetString("c")
+			assert.NoError(t, err)
+			assert.Equal(t, "2", value.Value)
+		})
+	}
+}
+
+func Test_transaction_saveIsNotAllowed(t *testing.T) {
+	// given
+	session := newSession(persistence.NewDatabase(), pubsub.NewBroker(), nil)
+	send(session, "MULTI")
+
+	// when
+	save := send(session, "SAVE")
+	result := send(session, "EXEC")
+
+	// then
+	assert.Equal(t, errorValue(errNotAllowedInsideMulti), save)
+	assert.Equal(t, errorValue(errExecAbort), result)
+}
+
+func loadPersisted(t *testing.T, dir string) (*persistence.Aof, *persistence.Snapshot, persistence.Database) {
+	aof, err := persistence.NewAof(filepath.Join(dir, "database.aof"), persistence.DefaultAofOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := persistence.NewSnapshot(filepath.Join(dir, "database.snapshot"), nil)
+
+	db := persistence.NewDatabase()
+	if err := startup.LoadPersistence(snapshot, aof, db); err != nil {
+		t.Fatal(err)
+	}
+
+	return aof, snapshot, db
+}
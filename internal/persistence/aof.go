@@ -2,86 +2,435 @@ package persistence
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"gocache/internal/core/resp"
+	"hash/crc64"
 	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
+var ErrRewriteInProgress = errors.New("ERR Background append only file rewriting already in progress")
+
+var crcTable = crc64.MakeTable(crc64.ECMA)
+
+// / Returned while loading the AOF if a command can not be parsed. Offset is the byte the broken command starts at
+type AofCorruptionError struct {
+	Offset    int64
+	Truncated bool
+	Err       error
+}
+
+func (e *AofCorruptionError) Error() string {
+	if e.Truncated {
+		return fmt.Sprintf("AOF ends with an incomplete command at byte %d, enable aof-load-truncated to drop it: %v", e.Offset, e.Err)
+	}
+	return fmt.Sprintf("AOF is corrupt at byte %d: %v", e.Offset, e.Err)
+}
+
+func (e *AofCorruptionError) Unwrap() error {
+	return e.Err
+}
+
+// / A point in the AOF. The checksum covers every byte before the offset, so a position taken before a rewrite does not match the rewritten file
+type AofPosition struct {
+	Offset   int64
+	Checksum uint64
+}
+
+// / Like auto-aof-rewrite-percentage and auto-aof-rewrite-min-size in redis.
+// / The AOF is rewritten once it grew by AutoRewritePercentage since the last rewrite and is at least AutoRewriteMinSize bytes big.
+// / A percentage of 0 disables automatic rewrites
+// / FsyncPolicy decides how often the AOF is synced to disk, like appendfsync in redis.
+// / Like aof-load-truncated in redis, LoadTruncated drops an incomplete command at the end of the AOF, e.g. after a crash mid-write, instead of failing
+type AofOptions struct {
+	AutoRewritePercentage int
+	AutoRewriteMinSize    int64
+	FsyncPolicy           FsyncPolicy
+	LoadTruncated         bool
+}
+
+func DefaultAofOptions() AofOptions {
+	return AofOptions{
+		AutoRewritePercentage: 100,
+		AutoRewriteMinSize:    64 * 1024 * 1024,
+		FsyncPolicy:           FsyncEverySec,
+		LoadTruncated:         true,
+	}
+}
+
+type FsyncPolicy string
+
+const (
+	// syncs after every write, before the command is answered. Slow, but nothing acknowledged is ever lost
+	FsyncAlways FsyncPolicy = "always"
+	// syncs once per second in the background, so at most one second of writes is lost on a crash
+	FsyncEverySec FsyncPolicy = "everysec"
+	// leaves syncing to the operating system
+	FsyncNo FsyncPolicy = "no"
+)
+
+func ParseFsyncPolicy(raw string) (FsyncPolicy, error) {
+	switch policy := FsyncPolicy(strings.ToLower(raw)); policy {
+	case FsyncAlways, FsyncEverySec, FsyncNo:
+		return policy, nil
+	default:
+		return "", errors.New("unknown fsync policy " + raw + ", expected always, everysec or no")
+	}
+}
+
 type Aof struct {
-	file   *os.File
-	reader *bufio.Reader
-	mutex  sync.Mutex
+	path    string
+	options AofOptions
+	file    *os.File
+	reader  *bufio.Reader
+	mutex   sync.Mutex
+
+	size     int64
+	checksum uint64
+	// size after the last rewrite (or at startup), which the auto rewrite growth is compared against
+	baseSize int64
+
+	// while a rewrite is running, every saved command is also collected here and appended to the rewritten file before swapping it in
+	rewriting     bool
+	rewriteBuffer bytes.Buffer
+
+	// reused by Save for the marshalled command
+	saveBuffer []byte
+
+	// closed by Close to stop the background sync of FsyncEverySec
+	stop    chan struct{}
+	stopped chan struct{}
 }
 
-func NewAof(path string) (*Aof, error) {
+func NewAof(path string, options AofOptions) (*Aof, error) {
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666)
 	if err != nil {
 		return nil, err
 	}
 
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	checksum := crc64.New(crcTable)
+	if _, err := io.Copy(checksum, file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
 	aof := &Aof{
-		file:   file,
-		reader: bufio.NewReader(file),
+		path:     path,
+		options:  options,
+		file:     file,
+		reader:   bufio.NewReader(file),
+		size:     info.Size(),
+		checksum: checksum.Sum64(),
+		baseSize: info.Size(),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	if options.FsyncPolicy == FsyncEverySec {
+		go aof.syncEverySecond()
+	} else {
+		close(aof.stopped)
 	}
 
-	// ensuring data integrity, even if the program crashes
-	go func() {
-		for {
+	return aof, nil
+}
+
+// / Ensures data integrity, even if the program crashes, losing at most one second of writes
+func (aof *Aof) syncEverySecond() {
+	defer close(aof.stopped)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-aof.stop:
+			return
+		case <-ticker.C:
 			aof.mutex.Lock()
-			aof.file.Sync()
+			if err := aof.file.Sync(); err != nil {
+				log.Println("Syncing the AOF failed: " + err.Error())
+			}
 			aof.mutex.Unlock()
-
-			time.Sleep(time.Second)
 		}
-	}()
+	}
+}
 
-	return aof, nil
+func (aof *Aof) ReplayPersistedCommands(replay func(resp.Value, int64) error) error {
+	return aof.ReplayPersistedCommandsFrom(0, replay)
 }
 
-func (aof *Aof) ReadPersistedCommands() ([]resp.Value, error) {
+// / Streams the commands after offset into replay while reading them, e.g. the ones that are not part of a snapshot yet. Stops at the first error replay returns.
+// / An incomplete last command is dropped and cut off the file if LoadTruncated is set, any other broken command fails with an AofCorruptionError
+func (aof *Aof) ReplayPersistedCommandsFrom(offset int64, replay func(resp.Value, int64) error) error {
 	aof.mutex.Lock()
 	defer aof.mutex.Unlock()
 
-	// move current file buffer to start
-	aof.file.Seek(0, io.SeekStart)
-
-	var values []resp.Value
-	reader := resp.NewReader(aof.file)
+	reader := resp.NewReader(io.NewSectionReader(aof.file, offset, aof.size-offset))
 
+	position := offset
+	// start of the MULTI/EXEC block that is currently read, a block without EXEC at the end is incomplete as a whole
+	blockStart := int64(-1)
 	for {
 		value, err := reader.Read()
-		if err != nil {
-			if err == io.EOF {
-				break
+		if err == io.EOF && blockStart < 0 {
+			return nil
+		}
+
+		size := offset + reader.Consumed() - position
+
+		// a command that got cut off at the end of the file, even if only its last line break is missing, can not be read completely
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			if blockStart >= 0 {
+				position = blockStart
+			}
+			if !aof.options.LoadTruncated {
+				return &AofCorruptionError{Offset: position, Truncated: true, Err: io.ErrUnexpectedEOF}
 			}
+			return aof.truncate(position)
+		}
+		if err != nil {
+			return &AofCorruptionError{Offset: position, Err: err}
+		}
 
-			return nil, err
+		switch {
+		case isBlockMarker(value, "MULTI"):
+			blockStart = position
+		case isBlockMarker(value, "EXEC"):
+			blockStart = -1
 		}
 
-		values = append(values, value)
+		if err := replay(value, size); err != nil {
+			return err
+		}
+		position += size
 	}
+}
 
-	return values, nil
+// / Cuts off everything after size. Expects the caller to hold the lock
+func (aof *Aof) truncate(size int64) error {
+	dropped := aof.size - size
+	if err := aof.file.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := aof.file.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+
+	checksum := crc64.New(crcTable)
+	if _, err := io.Copy(checksum, io.NewSectionReader(aof.file, 0, size)); err != nil {
+		return err
+	}
+
+	aof.size = size
+	aof.checksum = checksum.Sum64()
+	aof.baseSize = min(aof.baseSize, size)
+	log.Printf("AOF ended with an incomplete command or transaction, dropped the last %d bytes starting at byte %d\n", dropped, size)
+
+	return nil
 }
 
 func (aof *Aof) Save(value resp.Value) error {
-	bytes := value.Marshal()
-
 	aof.mutex.Lock()
 	defer aof.mutex.Unlock()
 
+	aof.saveBuffer = value.AppendMarshal(aof.saveBuffer[:0])
+	bytes := aof.saveBuffer
+
 	if _, err := aof.file.Write(bytes); err != nil {
 		return err
 	}
+	aof.size += int64(len(bytes))
+	aof.checksum = crc64.Update(aof.checksum, crcTable, bytes)
+
+	if aof.options.FsyncPolicy == FsyncAlways {
+		if err := aof.file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	if aof.rewriting {
+		aof.rewriteBuffer.Write(bytes)
+	}
+
+	return nil
+}
+
+func (aof *Aof) Position() AofPosition {
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	return AofPosition{Offset: aof.size, Checksum: aof.checksum}
+}
+
+// / Returns true if the AOF still starts with the bytes the position was taken at
+func (aof *Aof) MatchesPosition(position AofPosition) (bool, error) {
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	if position.Offset > aof.size {
+		return false, nil
+	}
+
+	checksum := crc64.New(crcTable)
+	if _, err := io.Copy(checksum, io.NewSectionReader(aof.file, 0, position.Offset)); err != nil {
+		return false, err
+	}
+
+	return checksum.Sum64() == position.Checksum, nil
+}
+
+// / Returns true if the AOF grew enough since the last rewrite to be rewritten automatically
+func (aof *Aof) NeedsRewrite() bool {
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	if aof.rewriting || aof.options.AutoRewritePercentage <= 0 || aof.size < aof.options.AutoRewriteMinSize {
+		return false
+	}
+
+	growth := (aof.size - aof.baseSize) * 100 / max(aof.baseSize, 1)
+	return growth >= int64(aof.options.AutoRewritePercentage)
+}
+
+// / Starts buffering saved commands. Has to be called at the same point in time the state for FinishRewrite is taken,
+// / so every command is either part of the state or of the buffer
+func (aof *Aof) StartRewrite() error {
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	if aof.rewriting {
+		return ErrRewriteInProgress
+	}
+
+	aof.rewriting = true
+	aof.rewriteBuffer.Reset()
+
+	return nil
+}
+
+// / Writes the state into a temporary file, appends the commands saved in the meantime and atomically replaces the AOF with it.
+// / Writing the state does not block Save, only appending the buffer and swapping the files does
+func (aof *Aof) FinishRewrite(state []resp.Value) error {
+	temp, err := os.CreateTemp(filepath.Dir(aof.path), filepath.Base(aof.path)+".rewrite-*")
+	if err != nil {
+		aof.abortRewrite()
+		return err
+	}
+
+	if err := aof.writeRewrite(temp, state); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		aof.abortRewrite()
+		return err
+	}
+
+	// the rename only survives a crash once the directory entry is synced as well
+	return syncDir(filepath.Dir(aof.path))
+}
+
+func (aof *Aof) writeRewrite(temp *os.File, state []resp.Value) error {
+	checksum := crc64.New(crcTable)
+	writer := bufio.NewWriter(io.MultiWriter(temp, checksum))
+	var buffer []byte
+	for _, value := range state {
+		buffer = value.AppendMarshal(buffer[:0])
+		if _, err := writer.Write(buffer); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	if _, err := io.MultiWriter(temp, checksum).Write(aof.rewriteBuffer.Bytes()); err != nil {
+		return err
+	}
+
+	// CreateTemp only grants the owner access, the rewritten AOF keeps the permissions of the one it replaces
+	original, err := aof.file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := temp.Chmod(original.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		return err
+	}
+
+	// the rename is atomic, so a crash leaves either the old or the new AOF, never a mix of both
+	if err := os.Rename(temp.Name(), aof.path); err != nil {
+		return err
+	}
+
+	info, err := temp.Stat()
+	if err != nil {
+		return err
+	}
+
+	aof.file.Close()
+	aof.file = temp
+	aof.reader = bufio.NewReader(temp)
+	aof.size = info.Size()
+	aof.checksum = checksum.Sum64()
+	aof.baseSize = info.Size()
+	aof.rewriting = false
+	aof.rewriteBuffer.Reset()
 
 	return nil
 }
 
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(dir.Sync(), dir.Close())
+}
+
+func (aof *Aof) abortRewrite() {
+	aof.mutex.Lock()
+	defer aof.mutex.Unlock()
+
+	aof.rewriting = false
+	aof.rewriteBuffer.Reset()
+}
+
+// / Stops the background sync and closes the file. Everything written so far is synced before, regardless of the fsync policy
 func (aof *Aof) Close() error {
+	aof.mutex.Lock()
+	select {
+	case <-aof.stop:
+		aof.mutex.Unlock()
+		return os.ErrClosed
+	default:
+		close(aof.stop)
+	}
+	aof.mutex.Unlock()
+
+	// waiting outside of the lock, as the sync goroutine may need it to finish its current sync
+	<-aof.stopped
+
 	aof.mutex.Lock()
 	defer aof.mutex.Unlock()
 
-	return aof.file.Close()
+	return errors.Join(aof.file.Sync(), aof.file.Close())
 }
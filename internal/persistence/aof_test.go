@@ -1,8 +1,10 @@
 package persistence
 
 import (
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"gocache/internal/core/resp"
@@ -17,15 +19,15 @@ func Test_savePersistsCommand(t *testing.T) {
 		Array: []resp.Value{
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "SET",
+				Bulk: []byte("SET"),
 			},
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "Tira",
+				Bulk: []byte("Tira"),
 			},
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "Misu",
+				Bulk: []byte("Misu"),
 			},
 		},
 	}
@@ -39,7 +41,7 @@ func Test_savePersistsCommand(t *testing.T) {
 	}
 	defer os.Remove(file.Name())
 
-	aof, err := NewAof(file.Name())
+	aof, err := NewAof(file.Name(), DefaultAofOptions())
 	if err != nil {
 		t.Error(err)
 		return
@@ -69,15 +71,15 @@ func Test_initializeReturnsCommands(t *testing.T) {
 		Array: []resp.Value{
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "SET",
+				Bulk: []byte("SET"),
 			},
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "Tira",
+				Bulk: []byte("Tira"),
 			},
 			{
 				Typ:  resp.BULK.Typ,
-				Bulk: "Misu",
+				Bulk: []byte("Misu"),
 			},
 		},
 	}
@@ -90,14 +92,14 @@ func Test_initializeReturnsCommands(t *testing.T) {
 	defer os.Remove(file.Name())
 	file.Write(request.Marshal())
 
-	aof, err := NewAof(file.Name())
+	aof, err := NewAof(file.Name(), DefaultAofOptions())
 	if err != nil {
 		t.Error(err)
 		return
 	}
 
 	// when
-	result, err := aof.ReadPersistedCommands()
+	result, err := readAll(aof)
 	if err != nil {
 		t.Error(err)
 		return
@@ -106,3 +108,394 @@ func Test_initializeReturnsCommands(t *testing.T) {
 	// then
 	assert.ElementsMatch(t, []resp.Value{request}, result)
 }
+
+func Test_rewriteReplacesFileAndKeepsBufferedCommands(t *testing.T) {
+	// given
+	file, err := os.CreateTemp("", "database.test.aof")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.Remove(file.Name())
+
+	aof, err := NewAof(file.Name(), DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer aof.Close()
+
+	for range 3 {
+		aof.Save(commandValue("INCR", "tira"))
+	}
+	state := []resp.Value{commandValue("SET", "tira", "3")}
+	during := commandValue("INCR", "tira")
+
+	// when
+	err = aof.StartRewrite()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	aof.Save(during)
+	err = aof.FinishRewrite(state)
+
+	// then
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	result, err := readAll(aof)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	assert.Equal(t, []resp.Value{state[0], during}, result)
+
+	after := commandValue("INCR", "tira")
+	aof.Save(after)
+	result, _ = readAll(aof)
+	assert.Equal(t, []resp.Value{state[0], during, after}, result)
+}
+
+func Test_rewriteKeepsFileMode(t *testing.T) {
+	// given
+	file, err := os.CreateTemp("", "database.test.aof")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.Remove(file.Name())
+	if err := file.Chmod(0644); err != nil {
+		t.Error(err)
+		return
+	}
+
+	aof, err := NewAof(file.Name(), DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer aof.Close()
+
+	// when
+	err = aof.StartRewrite()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = aof.FinishRewrite([]resp.Value{commandValue("SET", "tira", "misu")})
+
+	// then
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	info, err := os.Stat(file.Name())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func Test_rewriteCanOnlyRunOnce(t *testing.T) {
+	// given
+	file, err := os.CreateTemp("", "database.test.aof")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.Remove(file.Name())
+
+	aof, err := NewAof(file.Name(), DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer aof.Close()
+	aof.StartRewrite()
+
+	// when
+	err = aof.StartRewrite()
+
+	// then
+	assert.ErrorIs(t, err, ErrRewriteInProgress)
+}
+
+func Test_needsRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  AofOptions
+		saves    int
+		expected bool
+	}{
+		{"disabled", AofOptions{AutoRewritePercentage: 0, AutoRewriteMinSize: 0}, 10, false},
+		{"below min size", AofOptions{AutoRewritePercentage: 100, AutoRewriteMinSize: 1024 * 1024}, 10, false},
+		{"grown enough", AofOptions{AutoRewritePercentage: 100, AutoRewriteMinSize: 0}, 10, true},
+		{"not grown", AofOptions{AutoRewritePercentage: 100, AutoRewriteMinSize: 0}, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			file, err := os.CreateTemp("", "database.test.aof")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer os.Remove(file.Name())
+			file.Write(commandValue("SET", "tira", "misu").Marshal())
+
+			aof, err := NewAof(file.Name(), test.options)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer aof.Close()
+
+			// when
+			for range test.saves {
+				aof.Save(commandValue("SET", "tira", "misu"))
+			}
+
+			// then
+			assert.Equal(t, test.expected, aof.NeedsRewrite())
+		})
+	}
+}
+
+func Test_aofSavesWithEveryFsyncPolicy(t *testing.T) {
+	for _, policy := range []FsyncPolicy{FsyncAlways, FsyncEverySec, FsyncNo} {
+		t.Run(string(policy), func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "database.aof")
+			options := DefaultAofOptions()
+			options.FsyncPolicy = policy
+			aof, err := NewAof(path, options)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			// when
+			saveErr := aof.Save(commandValue("SET", "Tira", "Misu"))
+			closeErr := aof.Close()
+
+			// then
+			assert.NoError(t, saveErr)
+			assert.NoError(t, closeErr)
+			content, _ := os.ReadFile(path)
+			assert.Equal(t, "*3\r\n$3\r\nSET\r\n$4\r\nTira\r\n$4\r\nMisu\r\n", string(content))
+		})
+	}
+}
+
+func Test_aofCloseStopsSync(t *testing.T) {
+	// given
+	aof, err := NewAof(filepath.Join(t.TempDir(), "database.aof"), DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	// when
+	err = aof.Close()
+
+	// then
+	assert.NoError(t, err)
+	select {
+	case <-aof.stopped:
+	default:
+		t.Error("sync goroutine is still running")
+	}
+	assert.ErrorIs(t, aof.Close(), os.ErrClosed)
+}
+
+func Test_parseFsyncPolicy(t *testing.T) {
+	// when
+	policy, err := ParseFsyncPolicy("Always")
+	_, invalidErr := ParseFsyncPolicy("sometimes")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, FsyncAlways, policy)
+	assert.Error(t, invalidErr)
+}
+
+func Test_aofDropsTruncatedTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{name: "cut in a bulk", tail: "*3\r\n$3\r\nSET\r\n$4\r\nTi"},
+		{name: "cut in a length", tail: "*3\r\n$3\r\nSET\r\n$"},
+		{name: "missing last line break", tail: "*3\r\n$3\r\nSET\r\n$4\r\nTira\r\n$4\r\nMisu"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			complete := string(commandValue("SET", "Tira", "Misu").Marshal())
+			path := filepath.Join(t.TempDir(), "database.aof")
+			os.WriteFile(path, []byte(complete+test.tail), 0666)
+			aof, err := NewAof(path, DefaultAofOptions())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer aof.Close()
+
+			// when
+			commands, err := readAll(aof)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, []resp.Value{commandValue("SET", "Tira", "Misu")}, commands)
+			content, _ := os.ReadFile(path)
+			assert.Equal(t, complete, string(content))
+			assert.Equal(t, int64(len(complete)), aof.Position().Offset)
+		})
+	}
+}
+
+func Test_aofFailsOnTruncatedTailWithoutLoadTruncated(t *testing.T) {
+	// given
+	complete := string(commandValue("SET", "Tira", "Misu").Marshal())
+	path := filepath.Join(t.TempDir(), "database.aof")
+	os.WriteFile(path, []byte(complete+"*3\r\n$3\r\nSE"), 0666)
+	options := DefaultAofOptions()
+	options.LoadTruncated = false
+	aof, err := NewAof(path, options)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer aof.Close()
+
+	// when
+	_, err = readAll(aof)
+
+	// then
+	corruption := &AofCorruptionError{}
+	assert.ErrorAs(t, err, &corruption)
+	assert.True(t, corruption.Truncated)
+	assert.Equal(t, int64(len(complete)), corruption.Offset)
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, complete+"*3\r\n$3\r\nSE", string(content))
+}
+
+func Test_aofReplayCountsBytesAsWritten(t *testing.T) {
+	// given
+	// lengths with leading zeros are valid, but longer than the marshalled command
+	complete := "*3\r\n$03\r\nSET\r\n$004\r\nTira\r\n$4\r\nMisu\r\n"
+	path := filepath.Join(t.TempDir(), "database.aof")
+	os.WriteFile(path, []byte(complete+"*3\r\n$3\r\nSE"), 0666)
+	options := DefaultAofOptions()
+	options.LoadTruncated = false
+	aof, err := NewAof(path, options)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer aof.Close()
+	sizes := []int64{}
+
+	// when
+	err = aof.ReplayPersistedCommands(func(_ resp.Value, size int64) error {
+		sizes = append(sizes, size)
+		return nil
+	})
+
+	// then
+	corruption := &AofCorruptionError{}
+	assert.ErrorAs(t, err, &corruption)
+	assert.Equal(t, int64(len(complete)), corruption.Offset)
+	assert.Equal(t, []int64{int64(len(complete))}, sizes)
+}
+
+func Test_aofFailsOnCorruptionInTheMiddle(t *testing.T) {
+	// given
+	complete := string(commandValue("SET", "Tira", "Misu").Marshal())
+	path := filepath.Join(t.TempDir(), "database.aof")
+	os.WriteFile(path, []byte(complete+"garbage\r\n"+complete), 0666)
+	aof, err := NewAof(path, DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer aof.Close()
+
+	// when
+	_, err = readAll(aof)
+
+	// then
+	corruption := &AofCorruptionError{}
+	assert.ErrorAs(t, err, &corruption)
+	assert.False(t, corruption.Truncated)
+	assert.Equal(t, int64(len(complete)), corruption.Offset)
+	assert.ErrorContains(t, err, "byte 33")
+}
+
+func readAll(disk DiskPersistence) ([]resp.Value, error) {
+	values := []resp.Value{}
+	err := disk.ReplayPersistedCommands(func(value resp.Value, _ int64) error {
+		values = append(values, value)
+		return nil
+	})
+	return values, err
+}
+
+func Test_aofReplayStopsAtFirstError(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "database.aof")
+	aof, err := NewAof(path, DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer aof.Close()
+	aof.Save(commandValue("SET", "Tira", "Misu"))
+	aof.Save(commandValue("SET", "Misu", "Cute"))
+	aof.Save(commandValue("SET", "Cute", "Void"))
+	expected := errors.New("replay failed")
+	replayed := []resp.Value{}
+
+	// when
+	err = aof.ReplayPersistedCommands(func(value resp.Value, _ int64) error {
+		replayed = append(replayed, value)
+		if len(replayed) == 2 {
+			return expected
+		}
+		return nil
+	})
+
+	// then
+	assert.ErrorIs(t, err, expected)
+	assert.Len(t, replayed, 2)
+}
+
+func Test_aofDropsIncompleteTransaction(t *testing.T) {
+	// given
+	complete := string(commandValue("SET", "Tira", "Misu").Marshal())
+	block := string(blockMarker("MULTI").Marshal()) + string(commandValue("SET", "Misu", "Cute").Marshal())
+	path := filepath.Join(t.TempDir(), "database.aof")
+	os.WriteFile(path, []byte(complete+block), 0666)
+	aof, err := NewAof(path, DefaultAofOptions())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer aof.Close()
+
+	// when
+	commands, err := readAll(aof)
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, commands, 3)
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, complete, string(content))
+}
@@ -0,0 +1,182 @@
+package persistence
+
+import (
+	"log"
+)
+
+// / A client that waits for the first element of one of its lists, like BLPOP, BRPOP and BLMOVE.
+// / Each key serves its waiters in the order they blocked, so no client starves
+type ListWaiter struct {
+	keys []string
+	head bool
+
+	// only for BLMOVE. The element is pushed to destination before it is handed to the client
+	move        bool
+	destination string
+	toHead      bool
+
+	// buffered, so serving never blocks on the client
+	served chan ServedElement
+}
+
+// / The element a waiter received and the list it was taken from. Err is set if the waiter could not be served, e.g. because of a wrong type
+type ServedElement struct {
+	Key   string
+	Value string
+	Err   error
+}
+
+// / Waits for the first element of any of keys. The keys are checked in order
+func NewListWaiter(keys []string, head bool) *ListWaiter {
+	return &ListWaiter{keys: keys, head: head, served: make(chan ServedElement, 1)}
+}
+
+// / Waits for an element of source and moves it to destination
+func NewListMoveWaiter(source string, destination string, fromHead bool, toHead bool) *ListWaiter {
+	return &ListWaiter{
+		keys:        []string{source},
+		head:        fromHead,
+		move:        true,
+		destination: destination,
+		toHead:      toHead,
+		served:      make(chan ServedElement, 1),
+	}
+}
+
+// / True if elements are popped from the head of the lists
+func (w *ListWaiter) Head() bool {
+	return w.head
+}
+
+// / True for BLMOVE, whose elements are pushed to another list when served
+func (w *ListWaiter) Moves() bool {
+	return w.move
+}
+
+// / Receives the element once a push served the waiter
+func (w *ListWaiter) Served() <-chan ServedElement {
+	return w.served
+}
+
+// / Serves the waiter right away if one of its lists has an element and returns true.
+// / Otherwise, if wait is set, the waiter is queued behind the clients that blocked on its keys before
+func (db *DatabaseImpl) PopListOrWait(waiter *ListWaiter, wait bool) (ServedElement, bool) {
+	db.deleteIfExpired(waiter.keys...)
+	if waiter.move {
+		db.deleteIfExpired(waiter.destination)
+	}
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	for _, key := range waiter.keys {
+		e, err := db.lookup(key, typeList)
+		if err != nil {
+			return ServedElement{Err: err}, true
+		}
+		if e != nil {
+			return db.serve(waiter, key, e), true
+		}
+	}
+
+	if wait {
+		for _, key := range waiter.keys {
+			db.keyspace.blocked[key] = append(db.keyspace.blocked[key], waiter)
+		}
+	}
+
+	return ServedElement{}, false
+}
+
+// / Stops waiting, e.g. after a timeout. Returns true with the element, if the waiter was served in the meantime
+func (db *DatabaseImpl) CancelListWait(waiter *ListWaiter) (ServedElement, bool) {
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	db.unblock(waiter)
+
+	select {
+	case element := <-waiter.served:
+		return element, true
+	default:
+		return ServedElement{}, false
+	}
+}
+
+// / Returns how many clients wait for an element of key
+func (db *DatabaseImpl) BlockedClients(key string) int {
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	return len(db.keyspace.blocked[key])
+}
+
+// / Hands the elements of key to its waiters, until the list is empty or nobody waits anymore.
+// / Expects the caller to hold the keyspace write lock
+func (db *DatabaseImpl) serveBlocked(key string) {
+	for len(db.keyspace.blocked[key]) > 0 {
+		e, err := db.lookup(key, typeList)
+		if err != nil || e == nil {
+			return
+		}
+
+		waiter := db.keyspace.blocked[key][0]
+		db.unblock(waiter)
+		waiter.served <- db.serve(waiter, key, e)
+	}
+}
+
+// / Pops the element for the waiter and persists it like the non blocking command, so replaying the AOF does not depend on blocked clients.
+// / Expects the caller to hold the keyspace write lock
+func (db *DatabaseImpl) serve(waiter *ListWaiter, key string, e *entry) ServedElement {
+	if !waiter.move {
+		if err := db.persist(commandValue(popCommand(waiter.head), key)); err != nil {
+			log.Println("Could not persist serving a blocked client: " + err.Error())
+			return ServedElement{Err: err}
+		}
+		return ServedElement{Key: key, Value: db.popListElement(e, key, waiter.head)}
+	}
+
+	if _, err := db.lookup(waiter.destination, typeList); err != nil {
+		return ServedElement{Err: err}
+	}
+	if err := db.persist(commandValue("LMOVE", key, waiter.destination, listSide(waiter.head), listSide(waiter.toHead))); err != nil {
+		log.Println("Could not persist serving a blocked client: " + err.Error())
+		return ServedElement{Err: err}
+	}
+
+	return ServedElement{Key: key, Value: db.moveListElement(e, key, waiter.destination, waiter.head, waiter.toHead)}
+}
+
+// / Removes the waiter from the queues of all its keys. Expects the caller to hold the keyspace write lock
+func (db *DatabaseImpl) unblock(waiter *ListWaiter) {
+	for _, key := range waiter.keys {
+		waiters := db.keyspace.blocked[key]
+		for i, blocked := range waiters {
+			if blocked == waiter {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+
+		if len(waiters) == 0 {
+			delete(db.keyspace.blocked, key)
+		} else {
+			db.keyspace.blocked[key] = waiters
+		}
+	}
+}
+
+func popCommand(head bool) string {
+	if head {
+		return "LPOP"
+	}
+	return "RPOP"
+}
+
+func listSide(head bool) string {
+	if head {
+		return "LEFT"
+	}
+	return "RIGHT"
+}
@@ -0,0 +1,134 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_popListOrWait_servesRightAwayIfListHasElements(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.PushList(resp.Value{}, "misu", []string{"cute", "void"}, false)
+	waiter := NewListWaiter([]string{"tira", "misu"}, true)
+
+	// when
+	element, ok := db.PopListOrWait(waiter, true)
+
+	// then
+	assert.True(t, ok)
+	assert.Equal(t, ServedElement{Key: "misu", Value: "cute"}, element)
+	assert.Equal(t, commandValue("LPOP", "misu"), disk.saved[len(disk.saved)-1])
+	assert.Empty(t, db.keyspace.blocked)
+}
+
+func Test_popListOrWait_servesWaitersInOrder(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	first := NewListWaiter([]string{"tira"}, true)
+	second := NewListWaiter([]string{"misu", "tira"}, false)
+	db.PopListOrWait(first, true)
+	db.PopListOrWait(second, true)
+
+	// when
+	length, _ := db.PushList(resp.Value{}, "tira", []string{"cute"}, false)
+
+	// then
+	assert.Equal(t, 1, length)
+	assert.Equal(t, ServedElement{Key: "tira", Value: "cute"}, <-first.Served())
+	assert.Empty(t, second.Served())
+	assert.Equal(t, "none", db.GetType("tira"))
+
+	// when
+	db.PushList(resp.Value{}, "tira", []string{"void", "scary"}, false)
+
+	// then
+	assert.Equal(t, ServedElement{Key: "tira", Value: "scary"}, <-second.Served())
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"void"}, values)
+	assert.Empty(t, db.keyspace.blocked)
+}
+
+func Test_popListOrWait_withoutWaitDoesNotQueue(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	waiter := NewListWaiter([]string{"tira"}, true)
+
+	// when
+	_, ok := db.PopListOrWait(waiter, false)
+
+	// then
+	assert.False(t, ok)
+	assert.Empty(t, db.keyspace.blocked)
+}
+
+func Test_popListOrWait_wrongType(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+
+	// when
+	element, ok := db.PopListOrWait(NewListWaiter([]string{"tira"}, true), true)
+
+	// then
+	assert.True(t, ok)
+	assert.Equal(t, ErrWrongType, element.Err)
+}
+
+func Test_cancelListWait(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	cancelled := NewListWaiter([]string{"tira"}, true)
+	waiting := NewListWaiter([]string{"tira"}, true)
+	db.PopListOrWait(cancelled, true)
+	db.PopListOrWait(waiting, true)
+
+	// when
+	_, served := db.CancelListWait(cancelled)
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, true)
+
+	// then
+	assert.False(t, served)
+	assert.Empty(t, cancelled.Served())
+	assert.Equal(t, ServedElement{Key: "tira", Value: "misu"}, <-waiting.Served())
+}
+
+func Test_cancelListWait_returnsElementServedInTheMeantime(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	waiter := NewListWaiter([]string{"tira"}, true)
+	db.PopListOrWait(waiter, true)
+	db.PushList(resp.Value{}, "tira", []string{"misu"}, true)
+
+	// when
+	element, served := db.CancelListWait(waiter)
+
+	// then
+	assert.True(t, served)
+	assert.Equal(t, ServedElement{Key: "tira", Value: "misu"}, element)
+}
+
+func Test_listMoveWaiter_movesServedElement(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	mover := NewListMoveWaiter("tira", "misu", false, true)
+	popper := NewListWaiter([]string{"misu"}, true)
+	db.PopListOrWait(mover, true)
+	db.PopListOrWait(popper, true)
+
+	// when
+	db.PushList(resp.Value{}, "tira", []string{"cute"}, true)
+
+	// then
+	assert.Equal(t, ServedElement{Key: "tira", Value: "cute"}, <-mover.Served())
+	// the moved element wakes the clients waiting on the destination
+	assert.Equal(t, ServedElement{Key: "misu", Value: "cute"}, <-popper.Served())
+	assert.Equal(t, []resp.Value{
+		{},
+		commandValue("LMOVE", "tira", "misu", "RIGHT", "LEFT"),
+		commandValue("LPOP", "misu"),
+	}, disk.saved)
+}
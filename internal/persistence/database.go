@@ -3,132 +3,335 @@ package persistence
 import (
 	"errors"
 	"gocache/internal/core/resp"
-	"sync"
+	"math"
+	"strconv"
+	"time"
 )
 
-type stringStorage struct {
-	store map[string]StringEntity
-	mutex sync.RWMutex
+var ErrWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+var (
+	ErrNotAnInteger      = errors.New("ERR value is not an integer or out of range")
+	ErrIncrementOverflow = errors.New("ERR increment or decrement would overflow")
+	ErrNotAFloat         = errors.New("ERR value is not a valid float")
+	ErrFloatOverflow     = errors.New("ERR increment would produce NaN or Infinity")
+)
+
+type DatabaseImpl struct {
+	keyspace keyspace
+
+	// every change is saved to all of them, e.g. the aof logs it and the snapshot counts it for its save rules
+	diskPersistences []DiskPersistence
+
+	// while PersistAsBlock runs, persisted commands are collected here instead of being saved right away
+	block *[]resp.Value
 }
-type hashStorage struct {
-	store map[string]map[string]string
-	mutex sync.RWMutex
+
+func NewDatabase(diskPersistences ...DiskPersistence) *DatabaseImpl {
+	db := &DatabaseImpl{
+		keyspace: newKeyspace(),
+	}
+	db.EnablePersistence(diskPersistences...)
+
+	return db
 }
 
-type DatabaseImpl struct {
-	stringStorage stringStorage
-	hashStorage   hashStorage
+func (db *DatabaseImpl) EnablePersistence(diskPersistences ...DiskPersistence) {
+	db.diskPersistences = nil
+	for _, diskPersistence := range diskPersistences {
+		if diskPersistence != nil {
+			db.diskPersistences = append(db.diskPersistences, diskPersistence)
+		}
+	}
+}
+
+// / Returns the first enabled disk persistence of type T, e.g. the one that can be rewritten
+func persistenceOf[T any](db *DatabaseImpl) (T, bool) {
+	for _, diskPersistence := range db.diskPersistences {
+		if typed, ok := diskPersistence.(T); ok {
+			return typed, true
+		}
+	}
 
-	// could also be a list, to enable multiple forms of disk persistence (aof, snapshots etc)
-	diskPersistence DiskPersistence
+	var none T
+	return none, false
 }
 
-func NewDatabase(diskPersistence DiskPersistence) *DatabaseImpl {
-	return &DatabaseImpl{
-		stringStorage: stringStorage{
-			store: map[string]StringEntity{},
-		},
-		hashStorage: hashStorage{
-			store: map[string]map[string]string{},
-		},
+// / Returns the entry at key or nil if there is none. Expired entries are treated as if they did not exist.
+// / Expects the caller to hold the keyspace lock
+func (db *DatabaseImpl) lookup(key string, typ entryType) (*entry, error) {
+	e, ok := db.keyspace.store[key]
+	if !ok || e.isExpired(time.Now().UTC()) {
+		return nil, nil
+	}
 
-		diskPersistence: diskPersistence,
+	if e.typ != typ {
+		return nil, ErrWrongType
 	}
+
+	return e, nil
 }
-func (db *DatabaseImpl) EnablePersistence(diskPersistence DiskPersistence) {
-	db.diskPersistence = diskPersistence
+
+// / Expects the caller to hold the keyspace lock
+func (db *DatabaseImpl) persist(requestValue resp.Value) error {
+	if db.block != nil {
+		*db.block = append(*db.block, requestValue)
+		return nil
+	}
+
+	for _, diskPersistence := range db.diskPersistences {
+		if err := diskPersistence.Save(requestValue); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
+// / Strings with an expiration are persisted as SET with an absolute PXAT, so replaying the AOF later does not reset their time to live
 func (db *DatabaseImpl) SaveString(requestValue resp.Value, key string, value StringEntity) error {
-	db.stringStorage.mutex.Lock()
-	defer db.stringStorage.mutex.Unlock()
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
 
-	if db.diskPersistence != nil {
-		if err := db.diskPersistence.Save(requestValue); err != nil {
-			return err
+	if value.Expiration != nil {
+		requestValue = commandValue("SET", key, value.Value, "PXAT", unixMilli(value.Expiration.ExpiresAt))
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return err
+	}
+
+	// an already expired string (e.g. while replaying an old AOF) only deletes what was stored before
+	if value.IsExpired() {
+		if _, ok := db.keyspace.store[key]; ok {
+			db.keyspace.delete(key)
+			db.keyspace.notify(GenericEvents, "del", key)
 		}
+		return nil
 	}
 
-	db.stringStorage.store[key] = value
+	// like in redis, a string overrides whatever was stored at the key before
+	db.keyspace.set(key, &entry{
+		typ:        typeString,
+		value:      value.Value,
+		expiration: value.Expiration,
+	})
+	db.keyspace.notify(StringEvents, requestEvent(requestValue, "set"), key)
 
 	return nil
 }
 
 func (db *DatabaseImpl) GetString(key string) (StringEntity, error) {
-	db.stringStorage.mutex.RLock()
-	value, ok := db.stringStorage.store[key]
-	db.stringStorage.mutex.RUnlock()
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
 
-	if !ok {
+	e, err := db.lookup(key, typeString)
+	if err != nil {
+		return StringEntity{}, err
+	}
+	if e == nil {
 		return StringEntity{}, errors.New("No value with key: " + key)
 	}
 
-	return value, nil
+	return StringEntity{Value: e.value.(string), Expiration: e.expiration}, nil
 }
 
-func (db *DatabaseImpl) GetRandomString() (string, StringEntity, bool) {
-	db.stringStorage.mutex.RLock()
-	defer db.stringStorage.mutex.RUnlock()
+// / Adds increment to the integer stored at key and returns the result. A missing key counts as 0 and an expiration is kept
+func (db *DatabaseImpl) IncrementString(requestValue resp.Value, key string, increment int64) (int64, error) {
+	db.deleteIfExpired(key)
 
-	for k, v := range db.stringStorage.store {
-		return k, v, true
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeString)
+	if err != nil {
+		return 0, err
+	}
+
+	current := int64(0)
+	if e != nil {
+		parsed, ok := ParseInteger(e.value.(string))
+		if !ok {
+			return 0, ErrNotAnInteger
+		}
+		current = parsed
+	}
+	if (increment > 0 && current > math.MaxInt64-increment) || (increment < 0 && current < math.MinInt64-increment) {
+		return 0, ErrIncrementOverflow
 	}
-	return "", StringEntity{}, false
+	result := current + increment
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+	db.setNumber(key, e, strconv.FormatInt(result, 10), requestEvent(requestValue, "incrby"))
+
+	return result, nil
 }
 
-func (db *DatabaseImpl) DeleteAllStrings(requestValue resp.Value, keys []string) (int, error) {
-	db.stringStorage.mutex.Lock()
-	defer db.stringStorage.mutex.Unlock()
+// / Adds increment to the float stored at key and returns the result formatted like redis, e.g. 10.5 or 5000.
+// / The result is persisted as a SET instead of the request, so replaying it can not round differently
+func (db *DatabaseImpl) IncrementStringByFloat(key string, increment float64) (string, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeString)
+	if err != nil {
+		return "", err
+	}
 
-	if db.diskPersistence != nil {
-		if err := db.diskPersistence.Save(requestValue); err != nil {
-			return 0, err
+	current := float64(0)
+	if e != nil {
+		parsed, err := strconv.ParseFloat(e.value.(string), 64)
+		if err != nil || math.IsNaN(parsed) {
+			return "", ErrNotAFloat
 		}
+		current = parsed
 	}
+	result := current + increment
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return "", ErrFloatOverflow
+	}
+	formatted := strconv.FormatFloat(result, 'f', -1, 64)
+
+	request := commandValue("SET", key, formatted)
+	if e != nil && e.expiration != nil {
+		request = commandValue("SET", key, formatted, "PXAT", unixMilli(e.expiration.ExpiresAt))
+	}
+	if err := db.persist(request); err != nil {
+		return "", err
+	}
+	db.setNumber(key, e, formatted, "incrbyfloat")
+
+	return formatted, nil
+}
+
+// / Stores the result of an increment. Expects the caller to hold the keyspace lock
+func (db *DatabaseImpl) setNumber(key string, e *entry, value string, event string) {
+	if e == nil {
+		db.keyspace.set(key, &entry{typ: typeString, value: value})
+	} else {
+		e.value = value
+		db.keyspace.touch(key)
+	}
+	db.keyspace.notify(StringEvents, event, key)
+}
+
+// / Parses integers as strictly as redis does. Only the canonical form is accepted, e.g. no + sign, leading zeros or spaces
+func ParseInteger(raw string) (int64, bool) {
+	parsed, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || strconv.FormatInt(parsed, 10) != raw {
+		return 0, false
+	}
+	return parsed, true
+}
 
+// / Deletes the keys regardless of their type. Returns the amount of keys that existed
+func (db *DatabaseImpl) DeleteKeys(requestValue resp.Value, keys []string) (int, error) {
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
 	amountDeleted := 0
 	for _, key := range keys {
-		if _, ok := db.stringStorage.store[key]; ok {
-			delete(db.stringStorage.store, key)
-			amountDeleted += 1
+		if e, ok := db.keyspace.store[key]; ok {
+			db.keyspace.delete(key)
+			if !e.isExpired(now) {
+				amountDeleted += 1
+				db.keyspace.notify(GenericEvents, "del", key)
+			}
 		}
 	}
 
 	return amountDeleted, nil
 }
 
-func (db *DatabaseImpl) SaveHash(requestValue resp.Value, hash string, key string, value string) error {
-	db.hashStorage.mutex.Lock()
-	defer db.hashStorage.mutex.Unlock()
+// / Returns how many of the keys exist. Keys that are mentioned multiple times are counted multiple times
+func (db *DatabaseImpl) CountExistingKeys(keys []string) int {
+	db.deleteIfExpired(keys...)
 
-	if db.diskPersistence != nil {
-		if err := db.diskPersistence.Save(requestValue); err != nil {
-			return err
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	now := time.Now().UTC()
+	amount := 0
+	for _, key := range keys {
+		if e, ok := db.keyspace.store[key]; ok && !e.isExpired(now) {
+			amount++
 		}
 	}
 
-	if _, ok := db.hashStorage.store[hash]; !ok {
-		db.hashStorage.store[hash] = map[string]string{}
+	return amount
+}
+
+// / Returns the type of the value stored at key as redis names it, or none if the key does not exist
+func (db *DatabaseImpl) GetType(key string) string {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, ok := db.keyspace.store[key]
+	if !ok || e.isExpired(time.Now().UTC()) {
+		return "none"
 	}
-	db.hashStorage.store[hash][key] = value
+
+	return string(e.typ)
+}
+
+func (db *DatabaseImpl) SaveHash(requestValue resp.Value, hash string, key string, value string) error {
+	db.deleteIfExpired(hash)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(hash, typeHash)
+	if err != nil {
+		return err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return err
+	}
+
+	if e == nil {
+		e = &entry{typ: typeHash, value: map[string]string{}}
+		db.keyspace.set(hash, e)
+	}
+	e.value.(map[string]string)[key] = value
+	db.keyspace.touch(hash)
+	db.keyspace.notify(HashEvents, "hset", hash)
 
 	return nil
 }
 
 func (db *DatabaseImpl) DeleteAllHashKeys(requestValue resp.Value, hash string, keys []string) (int, error) {
-	db.hashStorage.mutex.Lock()
-	defer db.hashStorage.mutex.Unlock()
+	db.deleteIfExpired(hash)
 
-	if db.diskPersistence != nil {
-		if err := db.diskPersistence.Save(requestValue); err != nil {
-			return 0, err
-		}
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(hash, typeHash)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
 	}
 
-	hashMap, ok := db.hashStorage.store[hash]
-	if !ok {
+	if e == nil {
 		return 0, nil
 	}
+	hashMap := e.value.(map[string]string)
 
 	amountDeleted := 0
 	for _, key := range keys {
@@ -138,25 +341,48 @@ func (db *DatabaseImpl) DeleteAllHashKeys(requestValue resp.Value, hash string,
 		}
 	}
 
+	if amountDeleted > 0 {
+		db.keyspace.notify(HashEvents, "hdel", hash)
+	}
 	if len(hashMap) == 0 {
-		delete(db.hashStorage.store, hash)
+		db.keyspace.delete(hash)
+		db.keyspace.notify(GenericEvents, "del", hash)
+	} else if amountDeleted > 0 {
+		db.keyspace.touch(hash)
 	}
 
 	return amountDeleted, nil
 }
 
+// / Returns a copy of the hash
 func (db *DatabaseImpl) GetHash(hash string) (map[string]string, error) {
-	db.hashStorage.mutex.RLock()
-	value, ok := db.hashStorage.store[hash]
-	db.hashStorage.mutex.RUnlock()
+	db.deleteIfExpired(hash)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
 
-	if !ok {
+	e, err := db.lookup(hash, typeHash)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
 		return nil, errors.New("Did not find any value with hash " + hash)
 	}
 
+	hashMap := e.value.(map[string]string)
+	value := make(map[string]string, len(hashMap))
+	for k, v := range hashMap {
+		value[k] = v
+	}
+
 	return value, nil
 }
 
 func (db *DatabaseImpl) Close() error {
-	return db.diskPersistence.Close()
+	errs := []error{}
+	for _, diskPersistence := range db.diskPersistences {
+		errs = append(errs, diskPersistence.Close())
+	}
+
+	return errors.Join(errs...)
 }
@@ -0,0 +1,145 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_wrongTypeIsNotPersisted(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+	disk.saved = nil
+
+	// when
+	_, err := db.PushList(commandValue("LPUSH", "tira", "cute"), "tira", []string{"cute"}, true)
+
+	// then
+	assert.ErrorIs(t, err, ErrWrongType)
+	assert.Empty(t, disk.saved)
+	assert.Equal(t, "string", db.GetType("tira"))
+}
+
+func Test_deleteKeys_anyType(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+	db.AddToSet(resp.Value{}, "misu", []string{"cute"})
+
+	// when
+	amountDeleted, err := db.DeleteKeys(resp.Value{}, []string{"tira", "misu", "void"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, amountDeleted)
+	assert.Equal(t, "none", db.GetType("tira"))
+	assert.Equal(t, "none", db.GetType("misu"))
+}
+
+func Test_incrementString_persistsRequestAndKeepsExpiration(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("5", time.Minute))
+	disk.saved = nil
+	request := commandValue("INCRBY", "tira", "3")
+
+	// when
+	result, err := db.IncrementString(request, "tira", 3)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), result)
+	assert.Equal(t, []resp.Value{request}, disk.saved)
+	value, _ := db.GetString("tira")
+	assert.Equal(t, "8", value.Value)
+	assert.NotNil(t, value.Expiration)
+}
+
+func Test_incrementString_errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		stored    string
+		increment int64
+		expected  error
+	}{
+		{"no integer", "misu", 1, ErrNotAnInteger},
+		{"leading plus", "+5", 1, ErrNotAnInteger},
+		{"leading space", " 5", 1, ErrNotAnInteger},
+		{"overflow", strconv.FormatInt(math.MaxInt64, 10), 1, ErrIncrementOverflow},
+		{"underflow", strconv.FormatInt(math.MinInt64+1, 10), -2, ErrIncrementOverflow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			disk := &recordingDisk{}
+			db := NewDatabase(disk)
+			db.SaveString(resp.Value{}, "tira", NewString(test.stored, 0))
+			disk.saved = nil
+
+			// when
+			_, err := db.IncrementString(commandValue("INCRBY", "tira"), "tira", test.increment)
+
+			// then
+			assert.ErrorIs(t, err, test.expected)
+			assert.Empty(t, disk.saved)
+			value, _ := db.GetString("tira")
+			assert.Equal(t, test.stored, value.Value)
+		})
+	}
+}
+
+func Test_incrementStringByFloat_persistsSetOfResult(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("10.50", 0))
+	disk.saved = nil
+
+	// when
+	result, err := db.IncrementStringByFloat("tira", 0.1)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "10.6", result)
+	assert.Equal(t, []resp.Value{commandValue("SET", "tira", "10.6")}, disk.saved)
+}
+
+func Test_incrementStringByFloat_keepsExpirationInPersistedSet(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("5.0e3", time.Minute))
+	value, _ := db.GetString("tira")
+	disk.saved = nil
+
+	// when
+	result, err := db.IncrementStringByFloat("tira", 200)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "5200", result)
+	assert.Equal(t, []resp.Value{commandValue("SET", "tira", "5200", "PXAT", unixMilli(value.Expiration.ExpiresAt))}, disk.saved)
+}
+
+func Test_incrementStringByFloat_errors(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+	db.SaveString(resp.Value{}, "cute", NewString("1", 0))
+
+	// when
+	_, notAFloat := db.IncrementStringByFloat("tira", 1)
+	_, infinite := db.IncrementStringByFloat("cute", math.Inf(1))
+
+	// then
+	assert.ErrorIs(t, notAFloat, ErrNotAFloat)
+	assert.ErrorIs(t, infinite, ErrFloatOverflow)
+}
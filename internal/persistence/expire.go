@@ -0,0 +1,177 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"log"
+	"strconv"
+	"time"
+)
+
+// / Options of EXPIRE and its variants. OnlyWithout (NX), OnlyWith (XX), OnlyGreater (GT) and OnlyLess (LT).
+// / Like in redis, a key without an expiration counts as having an infinite one for GT and LT
+type ExpirationOptions struct {
+	OnlyWithout bool
+	OnlyWith    bool
+	OnlyGreater bool
+	OnlyLess    bool
+}
+
+func (o ExpirationOptions) allows(current *Expirationable, expiresAt time.Time) bool {
+	if current != nil && o.OnlyWithout || current == nil && o.OnlyWith {
+		return false
+	}
+	if o.OnlyGreater && (current == nil || !expiresAt.After(current.ExpiresAt)) {
+		return false
+	}
+	if o.OnlyLess && current != nil && !expiresAt.Before(current.ExpiresAt) {
+		return false
+	}
+
+	return true
+}
+
+// / Sets the absolute expiration of key, regardless of its type. Returns false if the key does not exist or the options prevented the update.
+// / An expiration in the past deletes the key right away.
+// / The AOF receives a PEXPIREAT (or a DEL) instead of the request, so replaying it later does not reset the time to live
+func (db *DatabaseImpl) SetExpiration(requestValue resp.Value, key string, expiresAt time.Time, options ExpirationOptions) (bool, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	now := time.Now().UTC()
+	e, ok := db.keyspace.store[key]
+	if !ok || e.isExpired(now) {
+		return false, nil
+	}
+
+	if !options.allows(e.expiration, expiresAt) {
+		return false, nil
+	}
+
+	if !expiresAt.After(now) {
+		if err := db.persist(commandValue("DEL", key)); err != nil {
+			return false, err
+		}
+
+		db.keyspace.delete(key)
+		db.keyspace.notify(GenericEvents, "del", key)
+		return true, nil
+	}
+
+	if err := db.persist(commandValue("PEXPIREAT", key, unixMilli(expiresAt))); err != nil {
+		return false, err
+	}
+
+	db.keyspace.setExpiration(key, e, &Expirationable{ExpiresAt: expiresAt})
+	db.keyspace.notify(GenericEvents, "expire", key)
+
+	return true, nil
+}
+
+// / Returns the expiration of key, which is nil if the key never expires. Returns false if the key does not exist
+func (db *DatabaseImpl) GetExpiration(key string) (*Expirationable, bool) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, ok := db.keyspace.store[key]
+	if !ok || e.isExpired(time.Now().UTC()) {
+		return nil, false
+	}
+
+	return e.expiration, true
+}
+
+// / Removes the expiration of key. Returns false if the key does not exist or has no expiration
+func (db *DatabaseImpl) RemoveExpiration(requestValue resp.Value, key string) (bool, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, ok := db.keyspace.store[key]
+	if !ok || e.isExpired(time.Now().UTC()) || e.expiration == nil {
+		return false, nil
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return false, err
+	}
+
+	db.keyspace.setExpiration(key, e, nil)
+	db.keyspace.notify(GenericEvents, "persist", key)
+
+	return true, nil
+}
+
+// / Checks up to sampleSize random keys that have an expiration and deletes the expired ones, persisting a DEL for each.
+// / Returns how many keys were sampled and how many of them were expired
+func (db *DatabaseImpl) ExpireSampledKeys(sampleSize int) (int, int, error) {
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	now := time.Now().UTC()
+	sample := db.keyspace.volatile.sample(sampleSize)
+
+	amountExpired := 0
+	for _, key := range sample {
+		if !db.keyspace.store[key].isExpired(now) {
+			continue
+		}
+
+		if err := db.persist(commandValue("DEL", key)); err != nil {
+			return len(sample), amountExpired, err
+		}
+
+		db.keyspace.delete(key)
+		db.keyspace.notify(ExpiredEvents, "expired", key)
+		amountExpired++
+	}
+
+	return len(sample), amountExpired, nil
+}
+
+// / Lazy expiration: deletes the keys that are expired and writes a DEL for each to the AOF, so replaying it ends in the same state.
+// / Called at the start of every access, before the caller takes the keyspace lock. The write lock is only taken if a key is expired
+func (db *DatabaseImpl) deleteIfExpired(keys ...string) {
+	now := time.Now().UTC()
+
+	db.keyspace.mutex.RLock()
+	expired := []string{}
+	for _, key := range keys {
+		if e, ok := db.keyspace.store[key]; ok && e.isExpired(now) {
+			expired = append(expired, key)
+		}
+	}
+	db.keyspace.mutex.RUnlock()
+
+	if len(expired) == 0 {
+		return
+	}
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	for _, key := range expired {
+		// the key could have been deleted or replaced in between releasing the read and taking the write lock
+		e, ok := db.keyspace.store[key]
+		if !ok || !e.isExpired(now) {
+			continue
+		}
+
+		// if the DEL can not be persisted, the key stays in memory. lookup still treats it as missing
+		if err := db.persist(commandValue("DEL", key)); err != nil {
+			log.Println("Could not persist deletion of expired key " + key + ": " + err.Error())
+			return
+		}
+
+		db.keyspace.delete(key)
+		db.keyspace.notify(ExpiredEvents, "expired", key)
+	}
+}
+
+func unixMilli(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
@@ -0,0 +1,165 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_setExpiration_skippedUpdateIsNotPersisted(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+	disk.saved = nil
+
+	// when
+	applied, err := db.SetExpiration(commandValue("EXPIRE", "tira", "10", "XX"), "tira", time.Now().Add(time.Minute), ExpirationOptions{OnlyWith: true})
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, applied)
+	assert.Empty(t, disk.saved)
+}
+
+func Test_setExpiration_keepsValue(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	db.PushList(resp.Value{}, "tira", []string{"misu", "cute"}, false)
+	expiresAt := time.Now().Add(time.Minute)
+
+	// when
+	applied, err := db.SetExpiration(resp.Value{}, "tira", expiresAt, ExpirationOptions{})
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, applied)
+
+	expiration, exists := db.GetExpiration("tira")
+	assert.True(t, exists)
+	assert.Equal(t, expiresAt, expiration.ExpiresAt)
+
+	values, _ := db.GetListRange("tira", 0, -1)
+	assert.Equal(t, []string{"misu", "cute"}, values)
+}
+
+func Test_saveString_persistsAbsoluteExpiration(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	value := NewString("misu", time.Minute)
+
+	expected := commandValue("SET", "tira", "misu", "PXAT", unixMilli(value.Expiration.ExpiresAt))
+
+	// when
+	err := db.SaveString(commandValue("SET", "tira", "misu", "EX", "60"), "tira", value)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []resp.Value{expected}, disk.saved)
+}
+
+func Test_setExpiration_persistsAbsoluteExpiration(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveHash(resp.Value{}, "tira", "misu", "cute")
+	disk.saved = nil
+	expiresAt := time.Now().Add(time.Minute)
+
+	expected := commandValue("PEXPIREAT", "tira", unixMilli(expiresAt))
+
+	// when
+	_, err := db.SetExpiration(commandValue("EXPIRE", "tira", "60"), "tira", expiresAt, ExpirationOptions{})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []resp.Value{expected}, disk.saved)
+}
+
+func Test_setExpiration_pastPersistsDel(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveHash(resp.Value{}, "tira", "misu", "cute")
+	disk.saved = nil
+
+	// when
+	_, err := db.SetExpiration(commandValue("EXPIRE", "tira", "-1"), "tira", time.Now().Add(-time.Second), ExpirationOptions{})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []resp.Value{commandValue("DEL", "tira")}, disk.saved)
+}
+
+func Test_deleteIfExpired_onRead(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", time.Millisecond))
+	disk.saved = nil
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	_, err := db.GetString("tira")
+
+	// then
+	assert.Error(t, err)
+	assert.Equal(t, []resp.Value{commandValue("DEL", "tira")}, disk.saved)
+	assert.NotContains(t, db.keyspace.store, "tira")
+	assert.Empty(t, db.keyspace.volatile.keys)
+}
+
+func Test_deleteIfExpired_beforeWrite(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", time.Millisecond))
+	disk.saved = nil
+	time.Sleep(2 * time.Millisecond)
+	request := commandValue("LPUSH", "tira", "cute")
+
+	// when
+	_, err := db.PushList(request, "tira", []string{"cute"}, true)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []resp.Value{commandValue("DEL", "tira"), request}, disk.saved)
+	assert.Equal(t, "list", db.GetType("tira"))
+}
+
+func Test_deleteIfExpired_keepsLiveKeys(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveHash(resp.Value{}, "tira", "misu", "cute")
+	db.SetExpiration(resp.Value{}, "tira", time.Now().Add(time.Hour), ExpirationOptions{})
+	disk.saved = nil
+
+	// when
+	hash, err := db.GetHash("tira")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"misu": "cute"}, hash)
+	assert.Empty(t, disk.saved)
+}
+
+func Test_deleteIfExpired_multipleKeys(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", time.Millisecond))
+	db.SaveString(resp.Value{}, "misu", NewString("tira", 0))
+	disk.saved = nil
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	amount := db.CountExistingKeys([]string{"tira", "misu"})
+
+	// then
+	assert.Equal(t, 1, amount)
+	assert.Equal(t, []resp.Value{commandValue("DEL", "tira")}, disk.saved)
+}
@@ -0,0 +1,128 @@
+package persistence
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+// / Every key maps to exactly one entry, no matter its type. This way a key can never hold a string and a hash at the same time.
+// / Entries must be changed through set, delete and setExpiration, so the ttl index stays in sync with the store.
+// / Values that are changed in place have to be marked with touch
+type keyspace struct {
+	store map[string]*entry
+	mutex sync.RWMutex
+
+	// only contains keys with an expiration, so the active expiration does not have to sample keys that never expire
+	volatile ttlIndex
+
+	watches map[string]map[*Watch]struct{}
+
+	// clients blocked on a list key, in the order they blocked
+	blocked map[string][]*ListWaiter
+
+	// nil until keyspace notifications are enabled
+	publisher Publisher
+	events    KeyspaceEvents
+}
+
+func newKeyspace() keyspace {
+	return keyspace{
+		store: map[string]*entry{},
+		volatile: ttlIndex{
+			positions: map[string]int{},
+		},
+		watches: map[string]map[*Watch]struct{}{},
+		blocked: map[string][]*ListWaiter{},
+	}
+}
+
+func (k *keyspace) set(key string, e *entry) {
+	_, existed := k.store[key]
+	k.store[key] = e
+	if !existed {
+		k.notify(NewKeyEvents, "new", key)
+	}
+	k.syncIndex(key, e)
+	k.touch(key)
+}
+
+func (k *keyspace) delete(key string) {
+	delete(k.store, key)
+	k.volatile.remove(key)
+	k.touch(key)
+}
+
+func (k *keyspace) setExpiration(key string, e *entry, expiration *Expirationable) {
+	e.expiration = expiration
+	k.syncIndex(key, e)
+	k.touch(key)
+}
+
+// / Marks every watch of key as dirty
+func (k *keyspace) touch(key string) {
+	for watch := range k.watches[key] {
+		watch.dirty.Store(true)
+	}
+}
+
+func (k *keyspace) syncIndex(key string, e *entry) {
+	if e.expiration == nil {
+		k.volatile.remove(key)
+	} else {
+		k.volatile.add(key)
+	}
+}
+
+// / A set of keys that supports O(1) insertion, removal and random sampling
+type ttlIndex struct {
+	keys      []string
+	positions map[string]int
+}
+
+func (t *ttlIndex) len() int {
+	return len(t.keys)
+}
+
+func (t *ttlIndex) add(key string) {
+	if _, ok := t.positions[key]; ok {
+		return
+	}
+
+	t.positions[key] = len(t.keys)
+	t.keys = append(t.keys, key)
+}
+
+// / Moves the last key into the gap, so the keys stay contiguous
+func (t *ttlIndex) remove(key string) {
+	position, ok := t.positions[key]
+	if !ok {
+		return
+	}
+
+	last := t.keys[len(t.keys)-1]
+	t.keys[position] = last
+	t.positions[last] = position
+
+	t.keys = t.keys[:len(t.keys)-1]
+	delete(t.positions, key)
+}
+
+// / Returns up to count distinct random keys. If the index holds at most count keys, all of them are returned
+func (t *ttlIndex) sample(count int) []string {
+	if len(t.keys) <= count {
+		return append([]string{}, t.keys...)
+	}
+
+	seen := make(map[int]struct{}, count)
+	sample := make([]string, 0, count)
+	for len(sample) < count {
+		i := rand.IntN(len(t.keys))
+		if _, ok := seen[i]; ok {
+			continue
+		}
+		seen[i] = struct{}{}
+		sample = append(sample, t.keys[i])
+	}
+
+	return sample
+}
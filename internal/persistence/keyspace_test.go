@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ttlIndex_onlyContainsKeysWithExpiration(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+
+	// when
+	db.SaveString(resp.Value{}, "tira", NewString("misu", time.Hour))
+	db.SaveString(resp.Value{}, "misu", NewString("tira", 0))
+	db.SaveHash(resp.Value{}, "cute", "void", "scary")
+	db.SetExpiration(resp.Value{}, "cute", time.Now().Add(time.Hour), ExpirationOptions{})
+
+	// then
+	assert.ElementsMatch(t, []string{"tira", "cute"}, db.keyspace.volatile.keys)
+}
+
+func Test_ttlIndex_followsRemovals(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", time.Hour))
+	db.SaveString(resp.Value{}, "misu", NewString("tira", time.Hour))
+	db.AddToSet(resp.Value{}, "cute", []string{"void"})
+	db.SetExpiration(resp.Value{}, "cute", time.Now().Add(time.Hour), ExpirationOptions{})
+	db.PushList(resp.Value{}, "void", []string{"scary"}, false)
+	db.SetExpiration(resp.Value{}, "void", time.Now().Add(time.Hour), ExpirationOptions{})
+
+	// when
+	db.DeleteKeys(resp.Value{}, []string{"tira"})
+	db.RemoveExpiration(resp.Value{}, "misu")
+	db.RemoveFromSet(resp.Value{}, "cute", []string{"void"})
+	db.SaveString(resp.Value{}, "void", NewString("scary", 0))
+
+	// then
+	assert.Empty(t, db.keyspace.volatile.keys)
+	assert.Empty(t, db.keyspace.volatile.positions)
+}
+
+func Test_ttlIndex_sampleReturnsDistinctKeys(t *testing.T) {
+	// given
+	index := ttlIndex{positions: map[string]int{}}
+	for _, key := range []string{"tira", "misu", "cute", "void", "scary"} {
+		index.add(key)
+	}
+
+	// when
+	sample := index.sample(3)
+
+	// then
+	assert.Len(t, sample, 3)
+	assert.Subset(t, index.keys, sample)
+
+	seen := map[string]struct{}{}
+	for _, key := range sample {
+		seen[key] = struct{}{}
+	}
+	assert.Len(t, seen, 3)
+}
+
+func Test_expireSampledKeys_persistsDel(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", time.Millisecond))
+	disk.saved = nil
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	sampled, expired, err := db.ExpireSampledKeys(20)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sampled)
+	assert.Equal(t, 1, expired)
+	assert.Equal(t, []resp.Value{commandValue("DEL", "tira")}, disk.saved)
+}
@@ -0,0 +1,429 @@
+package persistence
+
+import (
+	"errors"
+	"gocache/internal/core/resp"
+)
+
+// / A double ended queue backed by a ring buffer, so pushes and pops on both ends are O(1)
+type listEntity struct {
+	values []string
+	head   int
+	size   int
+}
+
+func newListEntity() *listEntity {
+	return &listEntity{values: make([]string, 4)}
+}
+
+func (l *listEntity) len() int {
+	return l.size
+}
+
+func (l *listEntity) get(index int) string {
+	return l.values[(l.head+index)%len(l.values)]
+}
+
+func (l *listEntity) set(index int, value string) {
+	l.values[(l.head+index)%len(l.values)] = value
+}
+
+func (l *listEntity) pushHead(value string) {
+	l.grow()
+	l.head = (l.head - 1 + len(l.values)) % len(l.values)
+	l.values[l.head] = value
+	l.size++
+}
+
+func (l *listEntity) pushTail(value string) {
+	l.grow()
+	l.values[(l.head+l.size)%len(l.values)] = value
+	l.size++
+}
+
+func (l *listEntity) popHead() string {
+	value := l.values[l.head]
+	l.values[l.head] = ""
+	l.head = (l.head + 1) % len(l.values)
+	l.size--
+	return value
+}
+
+func (l *listEntity) popTail() string {
+	index := (l.head + l.size - 1) % len(l.values)
+	value := l.values[index]
+	l.values[index] = ""
+	l.size--
+	return value
+}
+
+// / Returns a copy of the elements between start and stop (both inclusive). Expects already normalized indices
+func (l *listEntity) slice(start int, stop int) []string {
+	result := make([]string, 0, stop-start+1)
+	for i := start; i <= stop; i++ {
+		result = append(result, l.get(i))
+	}
+	return result
+}
+
+func (l *listEntity) replace(values []string) {
+	capacity := max(4, len(values))
+	l.values = make([]string, capacity)
+	copy(l.values, values)
+	l.head = 0
+	l.size = len(values)
+}
+
+func (l *listEntity) grow() {
+	if l.size < len(l.values) {
+		return
+	}
+
+	values := make([]string, len(l.values)*2)
+	for i := range l.size {
+		values[i] = l.get(i)
+	}
+	l.values = values
+	l.head = 0
+}
+
+// / Translates redis style indices (negative values count from the end) into a valid inclusive range.
+// / Returns false if the range is empty
+func normalizeRange(start int, stop int, length int) (int, int, bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+
+	return start, stop, true
+}
+
+func (db *DatabaseImpl) PushList(requestValue resp.Value, key string, values []string, head bool) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeList)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+
+	if e == nil {
+		e = &entry{typ: typeList, value: newListEntity()}
+		db.keyspace.set(key, e)
+	}
+	list := e.value.(*listEntity)
+
+	for _, value := range values {
+		if head {
+			list.pushHead(value)
+		} else {
+			list.pushTail(value)
+		}
+	}
+	db.keyspace.touch(key)
+	if head {
+		db.keyspace.notify(ListEvents, "lpush", key)
+	} else {
+		db.keyspace.notify(ListEvents, "rpush", key)
+	}
+
+	// the reply is the length before blocked clients take their elements, like in redis
+	length := list.len()
+	db.serveBlocked(key)
+
+	return length, nil
+}
+
+func (db *DatabaseImpl) PopList(requestValue resp.Value, key string, count int, head bool) ([]string, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeList)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, errors.New("Did not find any value with key " + key)
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return nil, err
+	}
+
+	list := e.value.(*listEntity)
+	count = min(count, list.len())
+	popped := make([]string, 0, count)
+	for range count {
+		if head {
+			popped = append(popped, list.popHead())
+		} else {
+			popped = append(popped, list.popTail())
+		}
+	}
+
+	if len(popped) > 0 && head {
+		db.keyspace.notify(ListEvents, "lpop", key)
+	} else if len(popped) > 0 {
+		db.keyspace.notify(ListEvents, "rpop", key)
+	}
+	if list.len() == 0 {
+		db.keyspace.delete(key)
+		db.keyspace.notify(GenericEvents, "del", key)
+	} else if len(popped) > 0 {
+		db.keyspace.touch(key)
+	}
+
+	return popped, nil
+}
+
+func (db *DatabaseImpl) GetListRange(key string, start int, stop int) ([]string, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, err := db.lookup(key, typeList)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, errors.New("Did not find any value with key " + key)
+	}
+	list := e.value.(*listEntity)
+
+	start, stop, ok := normalizeRange(start, stop, list.len())
+	if !ok {
+		return []string{}, nil
+	}
+
+	return list.slice(start, stop), nil
+}
+
+func (db *DatabaseImpl) GetListLength(key string) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, err := db.lookup(key, typeList)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	return e.value.(*listEntity).len(), nil
+}
+
+func (db *DatabaseImpl) SetListElement(requestValue resp.Value, key string, index int, value string) error {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeList)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return errors.New("ERR no such key")
+	}
+	list := e.value.(*listEntity)
+
+	if index < 0 {
+		index += list.len()
+	}
+	if index < 0 || index >= list.len() {
+		return errors.New("ERR index out of range")
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return err
+	}
+
+	list.set(index, value)
+	db.keyspace.touch(key)
+	db.keyspace.notify(ListEvents, "lset", key)
+
+	return nil
+}
+
+// / Pops an element from source and pushes it to destination. Source and destination can be the same list, which rotates it
+func (db *DatabaseImpl) MoveList(requestValue resp.Value, source string, destination string, fromHead bool, toHead bool) (string, error) {
+	db.deleteIfExpired(source, destination)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(source, typeList)
+	if err != nil {
+		return "", err
+	}
+	if e == nil {
+		return "", errors.New("Did not find any value with key " + source)
+	}
+	if _, err := db.lookup(destination, typeList); err != nil {
+		return "", err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return "", err
+	}
+
+	return db.moveListElement(e, source, destination, fromHead, toHead), nil
+}
+
+// / Expects the caller to hold the keyspace write lock, to have persisted the move and checked that destination is no other type
+func (db *DatabaseImpl) moveListElement(e *entry, source string, destination string, fromHead bool, toHead bool) string {
+	value := db.popListElement(e, source, fromHead)
+
+	target, _ := db.lookup(destination, typeList)
+	if target == nil {
+		target = &entry{typ: typeList, value: newListEntity()}
+		db.keyspace.set(destination, target)
+	}
+	list := target.value.(*listEntity)
+
+	if toHead {
+		list.pushHead(value)
+		db.keyspace.notify(ListEvents, "lpush", destination)
+	} else {
+		list.pushTail(value)
+		db.keyspace.notify(ListEvents, "rpush", destination)
+	}
+	db.keyspace.touch(destination)
+	db.serveBlocked(destination)
+
+	return value
+}
+
+// / Pops a single element and deletes the list once it is empty. Expects the caller to hold the keyspace write lock and to have persisted the pop
+func (db *DatabaseImpl) popListElement(e *entry, key string, head bool) string {
+	list := e.value.(*listEntity)
+
+	var value string
+	if head {
+		value = list.popHead()
+		db.keyspace.notify(ListEvents, "lpop", key)
+	} else {
+		value = list.popTail()
+		db.keyspace.notify(ListEvents, "rpop", key)
+	}
+
+	if list.len() == 0 {
+		db.keyspace.delete(key)
+		db.keyspace.notify(GenericEvents, "del", key)
+	} else {
+		db.keyspace.touch(key)
+	}
+
+	return value
+}
+
+// / Removes the first count occurrences of value. A negative count removes from the tail, 0 removes all occurrences
+func (db *DatabaseImpl) RemoveListElements(requestValue resp.Value, key string, count int, value string) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeList)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+
+	list := e.value.(*listEntity)
+	values := list.slice(0, list.len()-1)
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+
+	removed := make([]bool, len(values))
+	amountRemoved := 0
+	for i := range values {
+		index := i
+		if count < 0 {
+			index = len(values) - 1 - i
+		}
+		if limit != 0 && amountRemoved == limit {
+			break
+		}
+		if values[index] == value {
+			removed[index] = true
+			amountRemoved++
+		}
+	}
+
+	remaining := make([]string, 0, len(values)-amountRemoved)
+	for i, v := range values {
+		if !removed[i] {
+			remaining = append(remaining, v)
+		}
+	}
+
+	if amountRemoved > 0 {
+		db.keyspace.notify(ListEvents, "lrem", key)
+	}
+	if len(remaining) == 0 {
+		db.keyspace.delete(key)
+		db.keyspace.notify(GenericEvents, "del", key)
+	} else if amountRemoved > 0 {
+		list.replace(remaining)
+		db.keyspace.touch(key)
+	}
+
+	return amountRemoved, nil
+}
+
+func (db *DatabaseImpl) TrimList(requestValue resp.Value, key string, start int, stop int) error {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeList)
+	if err != nil || e == nil {
+		return err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return err
+	}
+
+	list := e.value.(*listEntity)
+	start, stop, ok := normalizeRange(start, stop, list.len())
+	db.keyspace.notify(ListEvents, "ltrim", key)
+	if !ok {
+		db.keyspace.delete(key)
+		db.keyspace.notify(GenericEvents, "del", key)
+		return nil
+	}
+
+	list.replace(list.slice(start, stop))
+	db.keyspace.touch(key)
+
+	return nil
+}
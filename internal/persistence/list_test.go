@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_list_pushesOnBothEndsAcrossGrowth(t *testing.T) {
+	// given
+	list := newListEntity()
+
+	// when
+	for _, v := range []string{"c", "d", "e", "f", "g"} {
+		list.pushTail(v)
+	}
+	list.pushHead("b")
+	list.pushHead("a")
+
+	// then
+	assert.Equal(t, 7, list.len())
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, list.slice(0, list.len()-1))
+}
+
+func Test_list_popsFromBothEnds(t *testing.T) {
+	// given
+	list := newListEntity()
+	for _, v := range []string{"a", "b", "c"} {
+		list.pushTail(v)
+	}
+
+	// when
+	head := list.popHead()
+	tail := list.popTail()
+
+	// then
+	assert.Equal(t, "a", head)
+	assert.Equal(t, "c", tail)
+	assert.Equal(t, []string{"b"}, list.slice(0, list.len()-1))
+}
+
+func Test_normalizeRange(t *testing.T) {
+	// given
+	length := 5
+
+	// when
+	start, stop, ok := normalizeRange(-3, -1, length)
+
+	// then
+	assert.True(t, ok)
+	assert.Equal(t, 2, start)
+	assert.Equal(t, 4, stop)
+}
+
+func Test_normalizeRange_empty(t *testing.T) {
+	// given
+	length := 5
+
+	// when
+	_, _, ok := normalizeRange(3, 1, length)
+
+	// then
+	assert.False(t, ok)
+}
@@ -36,6 +36,14 @@ func NewString(value string, expireDuration time.Duration) StringEntity {
 	return entity
 }
 
+// / Same as NewString, but expires at an absolute point in time, which may already have passed
+func NewStringExpiringAt(value string, expiresAt time.Time) StringEntity {
+	return StringEntity{
+		Value:      value,
+		Expiration: &Expirationable{ExpiresAt: expiresAt},
+	}
+}
+
 func (s *StringEntity) SetValue(value string) {
 	s.Value = value
 }
@@ -51,3 +59,30 @@ func (s *StringEntity) IsExpired() bool {
 
 	return s.Expiration.isExpired(time.Now().UTC())
 }
+
+type entryType string
+
+// / The values are named the same way the TYPE command returns them
+const (
+	typeString    entryType = "string"
+	typeHash      entryType = "hash"
+	typeList      entryType = "list"
+	typeSet       entryType = "set"
+	typeSortedSet entryType = "zset"
+)
+
+// / The value depends on the type:
+// / string: string, hash: map[string]string, list: *listEntity, set: *setEntity, zset: *sortedSetEntity
+type entry struct {
+	typ        entryType
+	value      any
+	expiration *Expirationable
+}
+
+func (e *entry) isExpired(now time.Time) bool {
+	if e.expiration == nil {
+		return false
+	}
+
+	return e.expiration.isExpired(now)
+}
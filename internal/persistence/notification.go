@@ -0,0 +1,106 @@
+package persistence
+
+import (
+	"errors"
+	"gocache/internal/core/resp"
+	"strings"
+)
+
+// / Classes of keyspace events, configured like notify-keyspace-events in redis.
+// / K and E select the channels the events are published to, the other classes select which events are published
+type KeyspaceEvents uint16
+
+const (
+	KeyspaceChannel KeyspaceEvents = 1 << iota // K: __keyspace@0__:<key> receives the event
+	KeyeventChannel                            // E: __keyevent@0__:<event> receives the key
+	GenericEvents                              // g: DEL, EXPIRE, PERSIST, ...
+	StringEvents                               // $
+	ListEvents                                 // l
+	SetEvents                                  // s
+	HashEvents                                 // h
+	SortedSetEvents                            // z
+	ExpiredEvents                              // x: a key expired, either on access or by the active expiration
+	EvictedEvents                              // e: gocache never evicts, accepted for compatibility
+	NewKeyEvents                               // n: a key was created. Not part of A, like in redis
+
+	// A
+	AllEvents = GenericEvents | StringEvents | ListEvents | SetEvents | HashEvents | SortedSetEvents | ExpiredEvents | EvictedEvents
+)
+
+var keyspaceEventFlags = map[rune]KeyspaceEvents{
+	'K': KeyspaceChannel,
+	'E': KeyeventChannel,
+	'g': GenericEvents,
+	'$': StringEvents,
+	'l': ListEvents,
+	's': SetEvents,
+	'h': HashEvents,
+	'z': SortedSetEvents,
+	'x': ExpiredEvents,
+	'e': EvictedEvents,
+	'n': NewKeyEvents,
+	'A': AllEvents,
+}
+
+var errInvalidKeyspaceEvents = errors.New("Invalid event class character. Use 'Ag$lshzxeKEn'")
+
+// / Parses flags like "KEA" or "Ex". An empty string disables notifications
+func ParseKeyspaceEvents(flags string) (KeyspaceEvents, error) {
+	events := KeyspaceEvents(0)
+	for _, flag := range flags {
+		class, ok := keyspaceEventFlags[flag]
+		if !ok {
+			return 0, errInvalidKeyspaceEvents
+		}
+		events |= class
+	}
+
+	return events, nil
+}
+
+// / Receives the notifications, e.g. the pub/sub broker. Must never block, since it is called while the keyspace is locked
+type Publisher interface {
+	Publish(channel string, message string) int
+}
+
+// / Starts publishing the selected events. Nothing is published unless K or E is selected
+func (db *DatabaseImpl) EnableKeyspaceNotifications(publisher Publisher, events KeyspaceEvents) {
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	db.keyspace.publisher = publisher
+	db.keyspace.events = events
+}
+
+// / Publishes event for key, if its class is enabled. Expects the caller to hold the keyspace write lock, so the notifications arrive in the order of the changes
+func (k *keyspace) notify(class KeyspaceEvents, event string, key string) {
+	if k.publisher == nil || k.events&class == 0 {
+		return
+	}
+
+	if k.events&KeyspaceChannel != 0 {
+		k.publisher.Publish("__keyspace@0__:"+key, event)
+	}
+	if k.events&KeyeventChannel != 0 {
+		k.publisher.Publish("__keyevent@0__:"+event, key)
+	}
+}
+
+// / Like in redis, a few commands share the event of a related command
+var eventAliases = map[string]string{
+	"incr": "incrby",
+	"decr": "decrby",
+}
+
+// / Names the event after the command of the request. Requests the database built itself, or none at all, fall back to event
+func requestEvent(requestValue resp.Value, event string) string {
+	if len(requestValue.Array) == 0 || requestValue.Array[0].Typ != resp.BULK.Typ {
+		return event
+	}
+
+	name := strings.ToLower(string(requestValue.Array[0].Bulk))
+	if alias, ok := eventAliases[name]; ok {
+		return alias
+	}
+	return name
+}
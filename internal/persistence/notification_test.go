@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseKeyspaceEvents(t *testing.T) {
+	// when
+	all, allErr := ParseKeyspaceEvents("KEA")
+	expired, expiredErr := ParseKeyspaceEvents("Ex")
+	none, noneErr := ParseKeyspaceEvents("")
+	_, invalidErr := ParseKeyspaceEvents("Kq")
+
+	// then
+	assert.NoError(t, allErr)
+	assert.Equal(t, KeyspaceChannel|KeyeventChannel|AllEvents, all)
+	assert.NoError(t, expiredErr)
+	assert.Equal(t, KeyeventChannel|ExpiredEvents, expired)
+	assert.NoError(t, noneErr)
+	assert.Equal(t, KeyspaceEvents(0), none)
+	assert.Equal(t, errInvalidKeyspaceEvents, invalidErr)
+}
+
+func Test_notifications_publishToKeyspaceAndKeyeventChannels(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	publisher := &recordingPublisher{}
+	db.EnableKeyspaceNotifications(publisher, KeyspaceChannel|KeyeventChannel|AllEvents)
+
+	// when
+	db.SaveString(commandValue("SET", "tira", "misu"), "tira", NewString("misu", 0))
+	db.DeleteKeys(resp.Value{}, []string{"tira", "missing"})
+
+	// then
+	assert.Equal(t, []string{
+		"__keyspace@0__:tira set", "__keyevent@0__:set tira",
+		"__keyspace@0__:tira del", "__keyevent@0__:del tira",
+	}, publisher.published)
+}
+
+func Test_notifications_onlyPublishEnabledClasses(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	publisher := &recordingPublisher{}
+	db.EnableKeyspaceNotifications(publisher, KeyeventChannel|ListEvents|NewKeyEvents)
+
+	// when
+	db.SaveHash(resp.Value{}, "tira", "misu", "cute")
+	db.PushList(resp.Value{}, "void", []string{"scary"}, true)
+	db.PopList(resp.Value{}, "void", 1, false)
+
+	// then
+	assert.Equal(t, []string{
+		"__keyevent@0__:new tira",
+		"__keyevent@0__:new void",
+		"__keyevent@0__:lpush void",
+		"__keyevent@0__:rpop void",
+	}, publisher.published)
+}
+
+func Test_notifications_emptiedKeyIsDeleted(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+	publisher := &recordingPublisher{}
+	db.EnableKeyspaceNotifications(publisher, KeyspaceChannel|AllEvents)
+
+	// when
+	db.RemoveFromSet(resp.Value{}, "tira", []string{"misu"})
+
+	// then
+	assert.Equal(t, []string{"__keyspace@0__:tira srem", "__keyspace@0__:tira del"}, publisher.published)
+}
+
+func Test_notifications_expiredKeys(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", time.Millisecond))
+	db.SaveString(resp.Value{}, "cute", NewString("void", time.Millisecond))
+	publisher := &recordingPublisher{}
+	db.EnableKeyspaceNotifications(publisher, KeyeventChannel|ExpiredEvents)
+	time.Sleep(2 * time.Millisecond)
+
+	// when
+	db.GetString("tira")
+	db.ExpireSampledKeys(20)
+
+	// then
+	assert.Equal(t, []string{"__keyevent@0__:expired tira", "__keyevent@0__:expired cute"}, publisher.published)
+}
+
+func Test_notifications_disabledWithoutChannel(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	publisher := &recordingPublisher{}
+	db.EnableKeyspaceNotifications(publisher, AllEvents)
+
+	// when
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+
+	// then
+	assert.Empty(t, publisher.published)
+}
+
+type recordingPublisher struct {
+	published []string
+}
+
+func (p *recordingPublisher) Publish(channel string, message string) int {
+	p.published = append(p.published, channel+" "+message)
+	return 0
+}
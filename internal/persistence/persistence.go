@@ -2,26 +2,100 @@ package persistence
 
 import (
 	"gocache/internal/core/resp"
+	"time"
 )
 
 type Database interface {
+	DeleteKeys(request resp.Value, keys []string) (int, error)
+	CountExistingKeys(keys []string) int
+	GetType(key string) string
+
+	SetExpiration(request resp.Value, key string, expiresAt time.Time, options ExpirationOptions) (bool, error)
+	GetExpiration(key string) (*Expirationable, bool)
+	RemoveExpiration(request resp.Value, key string) (bool, error)
+	ExpireSampledKeys(sampleSize int) (int, int, error)
+
 	SaveString(request resp.Value, key string, value StringEntity) error
-	DeleteAllStrings(request resp.Value, keys []string) (int, error)
 	GetString(key string) (StringEntity, error)
-	// expiration
-	GetRandomString() (string, StringEntity, bool)
+	IncrementString(request resp.Value, key string, increment int64) (int64, error)
+	IncrementStringByFloat(key string, increment float64) (string, error)
 
 	SaveHash(request resp.Value, hash string, key string, value string) error
 	DeleteAllHashKeys(request resp.Value, hash string, keys []string) (int, error)
 	GetHash(hash string) (map[string]string, error)
 
-	EnablePersistence(diskPersistence DiskPersistence)
+	PushList(request resp.Value, key string, values []string, head bool) (int, error)
+	PopList(request resp.Value, key string, count int, head bool) ([]string, error)
+	GetListRange(key string, start int, stop int) ([]string, error)
+	GetListLength(key string) (int, error)
+	SetListElement(request resp.Value, key string, index int, value string) error
+	RemoveListElements(request resp.Value, key string, count int, value string) (int, error)
+	TrimList(request resp.Value, key string, start int, stop int) error
+	MoveList(request resp.Value, source string, destination string, fromHead bool, toHead bool) (string, error)
+	PopListOrWait(waiter *ListWaiter, wait bool) (ServedElement, bool)
+	CancelListWait(waiter *ListWaiter) (ServedElement, bool)
+
+	AddToSet(request resp.Value, key string, members []string) (int, error)
+	RemoveFromSet(request resp.Value, key string, members []string) (int, error)
+	StoreSet(request resp.Value, destination string, sources []string, operation func(sets [][]string) []string) (int, error)
+	GetSetMembers(key string) ([]string, error)
+	IsSetMember(key string, members []string) ([]bool, error)
+	GetSetLength(key string) (int, error)
+	PopSet(key string, count int) ([]string, error)
+	GetRandomSetMembers(key string, count int) ([]string, error)
+
+	AddToSortedSet(request resp.Value, key string, members []ScoredMember, options SortedSetAddOptions) (int, error)
+	IncrementSortedSetScore(request resp.Value, key string, member string, increment float64, options SortedSetAddOptions) (float64, bool, error)
+	RemoveFromSortedSet(request resp.Value, key string, members []string) (int, error)
+	PopSortedSet(request resp.Value, key string, count int, highest bool) ([]ScoredMember, error)
+	GetSortedSetScore(key string, member string) (float64, error)
+	GetSortedSetRank(key string, member string, reverse bool) (int, float64, error)
+	GetSortedSetLength(key string) (int, error)
+	CountSortedSetByScore(key string, min ScoreBound, max ScoreBound) (int, error)
+	GetSortedSetRange(key string, start int, stop int, reverse bool) ([]ScoredMember, error)
+	GetSortedSetRangeByScore(key string, min ScoreBound, max ScoreBound, reverse bool, offset int, count int) ([]ScoredMember, error)
+	GetSortedSetRangeByLex(key string, min LexBound, max LexBound, reverse bool, offset int, count int) ([]ScoredMember, error)
+
+	EnablePersistence(diskPersistences ...DiskPersistence)
+	EnableKeyspaceNotifications(publisher Publisher, events KeyspaceEvents)
+	RewriteAof() (<-chan error, error)
+	AofNeedsRewrite() bool
+	SaveSnapshot() (<-chan error, error)
+	LastSnapshot() time.Time
+	SnapshotNeedsSave() bool
+
+	WatchKeys(watch *Watch, keys []string)
+	UnwatchKeys(watch *Watch)
+	PersistAsBlock(run func()) error
 
 	Close() error
 }
 
 type DiskPersistence interface {
 	Save(resp.Value) error
-	ReadPersistedCommands() ([]resp.Value, error)
+	// calls replay for every persisted command while reading them, so they never have to be in memory at once. Stops at the first error replay returns.
+	// size is how many bytes the command took in the file
+	ReplayPersistedCommands(replay func(command resp.Value, size int64) error) error
 	Close() error
 }
+
+// / A DiskPersistence that can be compacted by rewriting it from the current state, like the AOF
+type RewritablePersistence interface {
+	DiskPersistence
+	NeedsRewrite() bool
+	Position() AofPosition
+	StartRewrite() error
+	FinishRewrite(state []resp.Value) error
+}
+
+// / Builds a request the same way a client would send it. Used to persist commands the database decided on by itself
+func commandValue(name string, key string, args ...string) resp.Value {
+	array := make([]resp.Value, 0, len(args)+2)
+	array = append(array, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(name)})
+	array = append(array, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(key)})
+	for _, arg := range args {
+		array = append(array, resp.Value{Typ: resp.BULK.Typ, Bulk: []byte(arg)})
+	}
+
+	return resp.Value{Typ: resp.ARRAY.Typ, Array: array}
+}
@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"errors"
+	"gocache/internal/core/resp"
+	"time"
+)
+
+// / Like redis, collections are split into commands of at most 64 elements, so a huge collection does not end up in one huge command
+const rewriteItemsPerCommand = 64
+
+// / Starts rewriting the AOF from the current state. The state is collected right away, the file is written in the background.
+// / The returned channel receives the result once the rewrite is done
+func (db *DatabaseImpl) RewriteAof() (<-chan error, error) {
+	rewritable, ok := persistenceOf[RewritablePersistence](db)
+	if !ok {
+		return nil, errors.New("ERR the configured persistence can not be rewritten")
+	}
+
+	// writes persist while holding the write lock, so holding the read lock guarantees
+	// every command is either part of the state or arrives in the rewrite buffer
+	db.keyspace.mutex.RLock()
+	if err := rewritable.StartRewrite(); err != nil {
+		db.keyspace.mutex.RUnlock()
+		return nil, err
+	}
+	state := db.stateCommands(time.Now().UTC())
+	db.keyspace.mutex.RUnlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- rewritable.FinishRewrite(state)
+	}()
+
+	return done, nil
+}
+
+func (db *DatabaseImpl) AofNeedsRewrite() bool {
+	rewritable, ok := persistenceOf[RewritablePersistence](db)
+	return ok && rewritable.NeedsRewrite()
+}
+
+// / Returns the minimal commands that recreate the current state. Expects the caller to hold the keyspace lock
+func (db *DatabaseImpl) stateCommands(now time.Time) []resp.Value {
+	commands := []resp.Value{}
+
+	for key, e := range db.keyspace.store {
+		if e.isExpired(now) {
+			continue
+		}
+
+		commands = append(commands, entryCommands(key, e)...)
+	}
+
+	return commands
+}
+
+// / Returns the commands that recreate a single entry including its expiration
+func entryCommands(key string, e *entry) []resp.Value {
+	return newSnapshotEntry(key, e).commands()
+}
+
+func appendBatched(commands []resp.Value, name string, key string, items []string) []resp.Value {
+	for start := 0; start < len(items); start += rewriteItemsPerCommand {
+		end := min(start+rewriteItemsPerCommand, len(items))
+		commands = append(commands, commandValue(name, key, items[start:end]...))
+	}
+	return commands
+}
@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_stateCommands(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	expiresAt := time.Now().Add(time.Hour)
+	db.SaveString(resp.Value{}, "string", NewString("misu", 0))
+	db.SaveString(resp.Value{}, "expiring", NewStringExpiringAt("misu", expiresAt))
+	db.SaveHash(resp.Value{}, "hash", "misu", "cute")
+	db.PushList(resp.Value{}, "list", []string{"misu", "cute"}, false)
+	db.AddToSet(resp.Value{}, "set", []string{"misu"})
+	db.SetExpiration(resp.Value{}, "set", expiresAt, ExpirationOptions{})
+	db.AddToSortedSet(resp.Value{}, "zset", []ScoredMember{{Member: "misu", Score: 1.5}}, SortedSetAddOptions{})
+
+	expected := []resp.Value{
+		commandValue("SET", "string", "misu"),
+		commandValue("SET", "expiring", "misu", "PXAT", unixMilli(expiresAt)),
+		commandValue("HSET", "hash", "misu", "cute"),
+		commandValue("RPUSH", "list", "misu", "cute"),
+		commandValue("SADD", "set", "misu"),
+		commandValue("PEXPIREAT", "set", unixMilli(expiresAt)),
+		commandValue("ZADD", "zset", "1.5", "misu"),
+	}
+
+	// when
+	result := db.stateCommands(time.Now())
+
+	// then
+	assert.ElementsMatch(t, expected, result)
+}
+
+func Test_stateCommands_skipsExpiredKeys(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", time.Millisecond))
+
+	// when
+	result := db.stateCommands(time.Now().Add(time.Second))
+
+	// then
+	assert.Empty(t, result)
+}
+
+func Test_stateCommands_splitsBigCollections(t *testing.T) {
+	// given
+	db := NewDatabase(nil)
+	values := []string{}
+	for i := range rewriteItemsPerCommand + 1 {
+		values = append(values, strconv.Itoa(i))
+	}
+	db.PushList(resp.Value{}, "tira", values, false)
+
+	// when
+	result := db.stateCommands(time.Now())
+
+	// then
+	assert.Len(t, result, 2)
+	assert.Len(t, result[0].Array, rewriteItemsPerCommand+2)
+	assert.Equal(t, commandValue("RPUSH", "tira", strconv.Itoa(rewriteItemsPerCommand)), result[1])
+}
+
+func Test_rewriteAof_needsRewritablePersistence(t *testing.T) {
+	// given
+	db := NewDatabase(&recordingDisk{})
+
+	// when
+	_, err := db.RewriteAof()
+
+	// then
+	assert.Error(t, err)
+}
@@ -0,0 +1,326 @@
+package persistence
+
+import (
+	"errors"
+	"gocache/internal/core/resp"
+	"math/rand/v2"
+)
+
+// / The members are kept contiguous so random members can be picked in O(1), the positions map a member to its index
+type setEntity struct {
+	members   []string
+	positions map[string]int
+}
+
+func newSetEntity(capacity int) *setEntity {
+	return &setEntity{members: make([]string, 0, capacity), positions: make(map[string]int, capacity)}
+}
+
+func (s *setEntity) len() int {
+	return len(s.members)
+}
+
+func (s *setEntity) contains(member string) bool {
+	_, ok := s.positions[member]
+	return ok
+}
+
+// / Returns whether the member was added
+func (s *setEntity) add(member string) bool {
+	if s.contains(member) {
+		return false
+	}
+
+	s.positions[member] = len(s.members)
+	s.members = append(s.members, member)
+	return true
+}
+
+// / Moves the last member into the gap, so the members stay contiguous. Returns whether the member was removed
+func (s *setEntity) remove(member string) bool {
+	position, ok := s.positions[member]
+	if !ok {
+		return false
+	}
+
+	last := s.members[len(s.members)-1]
+	s.members[position] = last
+	s.positions[last] = position
+
+	s.members[len(s.members)-1] = ""
+	s.members = s.members[:len(s.members)-1]
+	delete(s.positions, member)
+	return true
+}
+
+func (s *setEntity) random() string {
+	return s.members[rand.IntN(len(s.members))]
+}
+
+// / Returns up to count distinct random members without modifying the set
+func (s *setEntity) sample(count int) []string {
+	if count >= len(s.members) {
+		return s.values()
+	}
+
+	// drawing until count distinct members are found gets slow once most of the set is needed, a partial shuffle of a copy does not
+	if 3*count > len(s.members) {
+		members := s.values()
+		for i := range count {
+			j := i + rand.IntN(len(members)-i)
+			members[i], members[j] = members[j], members[i]
+		}
+		return members[:count]
+	}
+
+	seen := make(map[int]struct{}, count)
+	sample := make([]string, 0, count)
+	for len(sample) < count {
+		i := rand.IntN(len(s.members))
+		if _, ok := seen[i]; ok {
+			continue
+		}
+		seen[i] = struct{}{}
+		sample = append(sample, s.members[i])
+	}
+
+	return sample
+}
+
+// / Returns a copy of the members
+func (s *setEntity) values() []string {
+	return append([]string{}, s.members...)
+}
+
+func (db *DatabaseImpl) AddToSet(requestValue resp.Value, key string, members []string) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeSet)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+
+	if e == nil {
+		e = &entry{typ: typeSet, value: newSetEntity(len(members))}
+		db.keyspace.set(key, e)
+	}
+	set := e.value.(*setEntity)
+
+	amountAdded := 0
+	for _, member := range members {
+		if set.add(member) {
+			amountAdded++
+		}
+	}
+	if amountAdded > 0 {
+		db.keyspace.touch(key)
+		db.keyspace.notify(SetEvents, "sadd", key)
+	}
+
+	return amountAdded, nil
+}
+
+func (db *DatabaseImpl) RemoveFromSet(requestValue resp.Value, key string, members []string) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeSet)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+
+	if e == nil {
+		return 0, nil
+	}
+
+	return db.removeFromSet(key, e.value.(*setEntity), members, "srem"), nil
+}
+
+// / Removes the members and publishes event, if any member was removed
+func (db *DatabaseImpl) removeFromSet(key string, set *setEntity, members []string, event string) int {
+	amountRemoved := 0
+	for _, member := range members {
+		if set.remove(member) {
+			amountRemoved++
+		}
+	}
+
+	if amountRemoved > 0 {
+		db.keyspace.notify(SetEvents, event, key)
+	}
+	if set.len() == 0 {
+		db.keyspace.delete(key)
+		db.keyspace.notify(GenericEvents, "del", key)
+	} else if amountRemoved > 0 {
+		db.keyspace.touch(key)
+	}
+
+	return amountRemoved
+}
+
+// / Replaces whatever is stored at destination with the result of the operation on the sets stored at the sources. Missing sources are empty sets.
+// / Used by the *STORE commands. The sources are read and the result is stored under one lock, so replaying the request leads to the same set
+func (db *DatabaseImpl) StoreSet(requestValue resp.Value, destination string, sources []string, operation func(sets [][]string) []string) (int, error) {
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	sets := make([][]string, len(sources))
+	for i, source := range sources {
+		e, err := db.lookup(source, typeSet)
+		if err != nil {
+			return 0, err
+		}
+		if e == nil {
+			sets[i] = []string{}
+			continue
+		}
+		sets[i] = e.value.(*setEntity).values()
+	}
+	members := operation(sets)
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+
+	if len(members) == 0 {
+		if _, ok := db.keyspace.store[destination]; ok {
+			db.keyspace.delete(destination)
+			db.keyspace.notify(GenericEvents, "del", destination)
+		}
+		return 0, nil
+	}
+
+	set := newSetEntity(len(members))
+	for _, member := range members {
+		set.add(member)
+	}
+	db.keyspace.set(destination, &entry{typ: typeSet, value: set})
+	db.keyspace.notify(SetEvents, requestEvent(requestValue, "sstore"), destination)
+
+	return set.len(), nil
+}
+
+func (db *DatabaseImpl) GetSetMembers(key string) ([]string, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, err := db.lookup(key, typeSet)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, errors.New("Did not find any value with key " + key)
+	}
+
+	return e.value.(*setEntity).values(), nil
+}
+
+func (db *DatabaseImpl) IsSetMember(key string, members []string) ([]bool, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, err := db.lookup(key, typeSet)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]bool, len(members))
+	if e == nil {
+		return result, nil
+	}
+
+	set := e.value.(*setEntity)
+	for i, member := range members {
+		result[i] = set.contains(member)
+	}
+
+	return result, nil
+}
+
+func (db *DatabaseImpl) GetSetLength(key string) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, err := db.lookup(key, typeSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	return e.value.(*setEntity).len(), nil
+}
+
+// / Removes up to count random members. Since the result is random, the AOF receives an SREM of the popped members instead of the request
+func (db *DatabaseImpl) PopSet(key string, count int) ([]string, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeSet)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, errors.New("Did not find any value with key " + key)
+	}
+	set := e.value.(*setEntity)
+
+	popped := set.sample(count)
+
+	if len(popped) > 0 {
+		if err := db.persist(commandValue("SREM", key, popped...)); err != nil {
+			return nil, err
+		}
+	}
+
+	db.removeFromSet(key, set, popped, "spop")
+
+	return popped, nil
+}
+
+// / Returns count random members. A negative count allows the same member to be returned multiple times
+func (db *DatabaseImpl) GetRandomSetMembers(key string, count int) ([]string, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, err := db.lookup(key, typeSet)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, errors.New("Did not find any value with key " + key)
+	}
+
+	set := e.value.(*setEntity)
+
+	if count < 0 {
+		result := make([]string, -count)
+		for i := range result {
+			result[i] = set.random()
+		}
+		return result, nil
+	}
+
+	return set.sample(count), nil
+}
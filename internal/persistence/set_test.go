@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"errors"
+	"gocache/internal/core/resp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_set_popPersistsRemovalOfPoppedMembers(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+	disk.saved = nil
+
+	expected := commandValue("SREM", "tira", "misu")
+
+	// when
+	popped, err := db.PopSet("tira", 1)
+
+	// then
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	assert.Equal(t, []string{"misu"}, popped)
+	assert.Equal(t, []resp.Value{expected}, disk.saved)
+}
+
+func Test_set_storeReadsSourcesAndPersistsRequest(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.AddToSet(resp.Value{}, "tira", []string{"misu"})
+	disk.saved = nil
+	request := commandValue("SUNIONSTORE", "result", "tira", "void")
+
+	var operands [][]string
+	union := func(sets [][]string) []string {
+		operands = sets
+		return append(append([]string{}, sets[0]...), sets[1]...)
+	}
+
+	// when
+	size, err := db.StoreSet(request, "result", []string{"tira", "void"}, union)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, size)
+	assert.Equal(t, [][]string{{"misu"}, {}}, operands)
+	assert.Equal(t, []resp.Value{request}, disk.saved)
+	members, _ := db.GetSetMembers("result")
+	assert.Equal(t, []string{"misu"}, members)
+}
+
+func Test_set_storeFailsOnWrongTypeWithoutPersisting(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+	disk.saved = nil
+
+	// when
+	_, err := db.StoreSet(commandValue("SINTERSTORE", "result", "tira"), "result", []string{"tira"}, func(sets [][]string) []string { return sets[0] })
+
+	// then
+	assert.ErrorIs(t, err, ErrWrongType)
+	assert.Empty(t, disk.saved)
+	assert.Equal(t, 0, db.CountExistingKeys([]string{"result"}))
+}
+
+type recordingDisk struct {
+	saved []resp.Value
+}
+
+func (d *recordingDisk) Save(value resp.Value) error {
+	d.saved = append(d.saved, value)
+	return nil
+}
+
+func (d *recordingDisk) ReplayPersistedCommands(func(resp.Value, int64) error) error {
+	return errors.New("Should never run this unmocked method ReplayPersistedCommands()")
+}
+
+func (d *recordingDisk) Close() error {
+	return nil
+}
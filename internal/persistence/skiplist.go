@@ -0,0 +1,228 @@
+package persistence
+
+import (
+	"math/rand/v2"
+)
+
+// / Same parameters redis uses. With p = 1/4 every level holds roughly a quarter of the nodes of the level below
+const (
+	skiplistMaxLevel    = 32
+	skiplistProbability = 0.25
+)
+
+// / An ordered index over (score, member) pairs. Every level stores the span to the next node, which
+// / makes it possible to calculate the rank of a node and to look up a node by its rank in O(log N)
+type skiplist struct {
+	header *skiplistNode
+	tail   *skiplistNode
+	length int
+	level  int
+}
+
+type skiplistNode struct {
+	member   string
+	score    float64
+	backward *skiplistNode
+	levels   []skiplistLevel
+}
+
+type skiplistLevel struct {
+	forward *skiplistNode
+	span    int
+}
+
+func newSkiplist() *skiplist {
+	return &skiplist{
+		header: newSkiplistNode(skiplistMaxLevel, 0, ""),
+		level:  1,
+	}
+}
+
+func newSkiplistNode(level int, score float64, member string) *skiplistNode {
+	return &skiplistNode{
+		member: member,
+		score:  score,
+		levels: make([]skiplistLevel, level),
+	}
+}
+
+func randomSkiplistLevel() int {
+	level := 1
+	for level < skiplistMaxLevel && rand.Float64() < skiplistProbability {
+		level++
+	}
+	return level
+}
+
+// / Whether the node is ordered before the given score and member
+func (n *skiplistNode) before(score float64, member string) bool {
+	return n.score < score || (n.score == score && n.member < member)
+}
+
+// / Inserts a new node. Expects the member to not be part of the skiplist yet
+func (sl *skiplist) insert(score float64, member string) {
+	update := make([]*skiplistNode, skiplistMaxLevel)
+	rank := make([]int, skiplistMaxLevel)
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		if i != sl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].forward != nil && x.levels[i].forward.before(score, member) {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+
+	level := randomSkiplistLevel()
+	if level > sl.level {
+		for i := sl.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = sl.header
+			update[i].levels[i].span = sl.length
+		}
+		sl.level = level
+	}
+
+	x = newSkiplistNode(level, score, member)
+	for i := range level {
+		x.levels[i].forward = update[i].levels[i].forward
+		update[i].levels[i].forward = x
+
+		x.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = (rank[0] - rank[i]) + 1
+	}
+
+	// the new node is skipped by the higher levels
+	for i := level; i < sl.level; i++ {
+		update[i].levels[i].span++
+	}
+
+	if update[0] != sl.header {
+		x.backward = update[0]
+	}
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x
+	} else {
+		sl.tail = x
+	}
+
+	sl.length++
+}
+
+func (sl *skiplist) delete(score float64, member string) bool {
+	update := make([]*skiplistNode, skiplistMaxLevel)
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && x.levels[i].forward.before(score, member) {
+			x = x.levels[i].forward
+		}
+		update[i] = x
+	}
+
+	x = x.levels[0].forward
+	if x == nil || x.score != score || x.member != member {
+		return false
+	}
+
+	for i := range sl.level {
+		if update[i].levels[i].forward == x {
+			update[i].levels[i].span += x.levels[i].span - 1
+			update[i].levels[i].forward = x.levels[i].forward
+		} else {
+			update[i].levels[i].span--
+		}
+	}
+
+	if x.levels[0].forward != nil {
+		x.levels[0].forward.backward = x.backward
+	} else {
+		sl.tail = x.backward
+	}
+
+	for sl.level > 1 && sl.header.levels[sl.level-1].forward == nil {
+		sl.level--
+	}
+	sl.length--
+
+	return true
+}
+
+// / Returns the 0 based rank of the node or -1 if it is not part of the skiplist
+func (sl *skiplist) rank(score float64, member string) int {
+	rank := 0
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && (x.levels[i].forward.before(score, member) || (x.levels[i].forward.score == score && x.levels[i].forward.member == member)) {
+			rank += x.levels[i].span
+			x = x.levels[i].forward
+		}
+
+		if x != sl.header && x.member == member {
+			return rank - 1
+		}
+	}
+
+	return -1
+}
+
+// / Returns the node at the 0 based rank or nil if the rank is out of range
+func (sl *skiplist) byRank(rank int) *skiplistNode {
+	if rank < 0 || rank >= sl.length {
+		return nil
+	}
+
+	target := rank + 1
+	traversed := 0
+
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && traversed+x.levels[i].span <= target {
+			traversed += x.levels[i].span
+			x = x.levels[i].forward
+		}
+
+		if traversed == target {
+			return x
+		}
+	}
+
+	return nil
+}
+
+// / Returns the first node that is inside the range. Both functions need to be monotonic over the ordering of the skiplist
+func (sl *skiplist) firstInRange(aboveMin func(*skiplistNode) bool, belowMax func(*skiplistNode) bool) *skiplistNode {
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && !aboveMin(x.levels[i].forward) {
+			x = x.levels[i].forward
+		}
+	}
+
+	x = x.levels[0].forward
+	if x == nil || !belowMax(x) {
+		return nil
+	}
+
+	return x
+}
+
+// / Returns the last node that is inside the range. Both functions need to be monotonic over the ordering of the skiplist
+func (sl *skiplist) lastInRange(aboveMin func(*skiplistNode) bool, belowMax func(*skiplistNode) bool) *skiplistNode {
+	x := sl.header
+	for i := sl.level - 1; i >= 0; i-- {
+		for x.levels[i].forward != nil && belowMax(x.levels[i].forward) {
+			x = x.levels[i].forward
+		}
+	}
+
+	if x == sl.header || !aboveMin(x) {
+		return nil
+	}
+
+	return x
+}
@@ -0,0 +1,86 @@
+package persistence
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_skiplist_ordersByScoreThenMember(t *testing.T) {
+	// given
+	sl := newSkiplist()
+
+	// when
+	sl.insert(2, "cute")
+	sl.insert(1, "misu")
+	sl.insert(2, "bold")
+	sl.insert(0, "tira")
+
+	// then
+	members := []string{}
+	for node := sl.byRank(0); node != nil; node = node.levels[0].forward {
+		members = append(members, node.member)
+	}
+	assert.Equal(t, []string{"tira", "misu", "bold", "cute"}, members)
+	assert.Equal(t, "cute", sl.tail.member)
+}
+
+func Test_skiplist_rankAndByRankAfterDeletes(t *testing.T) {
+	// given
+	sl := newSkiplist()
+	for i := range 1000 {
+		sl.insert(float64(i), strconv.Itoa(i))
+	}
+
+	// when
+	for i := 0; i < 1000; i += 2 {
+		sl.delete(float64(i), strconv.Itoa(i))
+	}
+
+	// then
+	assert.Equal(t, 500, sl.length)
+	for rank := range 500 {
+		expected := 2*rank + 1
+
+		node := sl.byRank(rank)
+		assert.Equal(t, strconv.Itoa(expected), node.member)
+		assert.Equal(t, rank, sl.rank(float64(expected), strconv.Itoa(expected)))
+	}
+	assert.Equal(t, -1, sl.rank(0, "0"))
+	assert.Nil(t, sl.byRank(500))
+}
+
+func Test_skiplist_scoreRange(t *testing.T) {
+	// given
+	sl := newSkiplist()
+	for i := range 10 {
+		sl.insert(float64(i), strconv.Itoa(i))
+	}
+	min := ScoreBound{Value: 3, Exclusive: true}
+	max := ScoreBound{Value: 7}
+
+	// when
+	first := sl.firstInRange(min.aboveMin, max.belowMax)
+	last := sl.lastInRange(min.aboveMin, max.belowMax)
+
+	// then
+	assert.Equal(t, "4", first.member)
+	assert.Equal(t, "7", last.member)
+}
+
+func Test_skiplist_emptyScoreRange(t *testing.T) {
+	// given
+	sl := newSkiplist()
+	sl.insert(1, "misu")
+	min := ScoreBound{Value: 2}
+	max := ScoreBound{Value: 3}
+
+	// when
+	first := sl.firstInRange(min.aboveMin, max.belowMax)
+	last := sl.lastInRange(min.aboveMin, max.belowMax)
+
+	// then
+	assert.Nil(t, first)
+	assert.Nil(t, last)
+}
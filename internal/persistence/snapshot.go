@@ -0,0 +1,572 @@
+package persistence
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gocache/internal/core/resp"
+	"hash/crc64"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrSaveInProgress = errors.New("ERR Background save already in progress")
+
+const snapshotMagic = "GOCACHE-SNAPSHOT"
+const snapshotVersion = 1
+
+// / Like the save config in redis: a snapshot is taken once Seconds passed since the last one and at least Changes changes happened
+type SaveRule struct {
+	Seconds int
+	Changes int
+}
+
+func DefaultSaveRules() []SaveRule {
+	return []SaveRule{{Seconds: 3600, Changes: 1}, {Seconds: 300, Changes: 100}, {Seconds: 60, Changes: 10000}}
+}
+
+// / Parses rules in the format of the redis save config, e.g. "3600 1 300 100". An empty string disables periodic snapshots
+func ParseSaveRules(raw string) ([]SaveRule, error) {
+	fields := strings.Fields(raw)
+	if len(fields)%2 != 0 {
+		return nil, errors.New("save rules need to be pairs of <seconds> <changes>")
+	}
+
+	rules := make([]SaveRule, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		seconds, err := strconv.Atoi(fields[i])
+		if err != nil || seconds <= 0 {
+			return nil, errors.New("invalid seconds in save rule: " + fields[i])
+		}
+		changes, err := strconv.Atoi(fields[i+1])
+		if err != nil || changes < 0 {
+			return nil, errors.New("invalid changes in save rule: " + fields[i+1])
+		}
+		rules = append(rules, SaveRule{Seconds: seconds, Changes: changes})
+	}
+
+	return rules, nil
+}
+
+// / A point in time copy of the whole keyspace in a compact binary format. As DiskPersistence it only counts changes for its save rules,
+// / the snapshot itself is written by the database with SaveSnapshot
+type Snapshot struct {
+	path  string
+	rules []SaveRule
+	mutex sync.Mutex
+
+	// changes since the last successful snapshot
+	changes  int
+	lastSave time.Time
+	saving   bool
+}
+
+func NewSnapshot(path string, rules []SaveRule) *Snapshot {
+	lastSave := time.Now().UTC()
+	if info, err := os.Stat(path); err == nil {
+		lastSave = info.ModTime().UTC()
+	}
+
+	return &Snapshot{
+		path:     path,
+		rules:    rules,
+		lastSave: lastSave,
+	}
+}
+
+func (s *Snapshot) Save(resp.Value) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.changes++
+	return nil
+}
+
+// / The snapshot is verified before anything is replayed. Afterwards the commands are created one entry at a time while the file is read.
+// / The first command of an entry counts the bytes of the whole entry
+func (s *Snapshot) ReplayPersistedCommands(replay func(resp.Value, int64) error) error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	replayErr := error(nil)
+	_, err = readSnapshot(file, info.Size(), func(entry snapshotEntry, size int64) error {
+		for i, command := range entry.commands() {
+			if i > 0 {
+				size = 0
+			}
+			if replayErr = replay(command, size); replayErr != nil {
+				return replayErr
+			}
+		}
+		return nil
+	})
+	if replayErr != nil {
+		return replayErr
+	}
+	if err != nil {
+		return fmt.Errorf("snapshot %s is corrupt: %w", s.path, err)
+	}
+
+	return nil
+}
+
+func (s *Snapshot) Close() error {
+	return nil
+}
+
+func (s *Snapshot) LastSave() time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.lastSave
+}
+
+// / Returns true if any save rule is fulfilled
+func (s *Snapshot) NeedsSave() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.saving || s.changes == 0 {
+		return false
+	}
+
+	elapsed := time.Since(s.lastSave)
+	for _, rule := range s.rules {
+		if elapsed >= time.Duration(rule.Seconds)*time.Second && s.changes >= rule.Changes {
+			return true
+		}
+	}
+
+	return false
+}
+
+// / Marks a save as running. Returns the amount of changes the snapshot will contain
+func (s *Snapshot) startSave() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.saving {
+		return 0, ErrSaveInProgress
+	}
+
+	s.saving = true
+	return s.changes, nil
+}
+
+func (s *Snapshot) finishSave(changes int, err error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.saving = false
+	if err != nil {
+		return err
+	}
+
+	// changes made while writing are not part of this snapshot
+	s.changes -= changes
+	s.lastSave = time.Now().UTC()
+
+	return nil
+}
+
+// / Writes the entries into a temporary file first and renames it afterwards, so a crash never leaves a half written snapshot behind
+func (s *Snapshot) write(entries []snapshotEntry, position AofPosition) error {
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if err := encodeSnapshot(temp, entries, position); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+
+	return os.Rename(temp.Name(), s.path)
+}
+
+// / Returns the AOF position the snapshot was taken at, by only reading its header. Returns false if there is no snapshot yet
+func (s *Snapshot) Position() (AofPosition, bool, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return AofPosition{}, false, nil
+	}
+	if err != nil {
+		return AofPosition{}, false, err
+	}
+	defer file.Close()
+
+	position, err := readSnapshotHeader(bufio.NewReader(file))
+	if err != nil {
+		return AofPosition{}, false, fmt.Errorf("snapshot %s is corrupt: %w", s.path, err)
+	}
+
+	return position, true, nil
+}
+
+// / Takes a snapshot of the current state. The state is copied right away, the file is written in the background.
+// / The returned channel receives the result once the snapshot is written
+func (db *DatabaseImpl) SaveSnapshot() (<-chan error, error) {
+	snapshot, ok := persistenceOf[*Snapshot](db)
+	if !ok {
+		return nil, errors.New("ERR snapshots are not enabled")
+	}
+
+	// writes persist while holding the write lock, so the read lock keeps the state and the aof position in sync
+	db.keyspace.mutex.RLock()
+	changes, err := snapshot.startSave()
+	if err != nil {
+		db.keyspace.mutex.RUnlock()
+		return nil, err
+	}
+
+	position := AofPosition{}
+	if aof, ok := persistenceOf[RewritablePersistence](db); ok {
+		position = aof.Position()
+	}
+
+	now := time.Now().UTC()
+	entries := make([]snapshotEntry, 0, len(db.keyspace.store))
+	for key, e := range db.keyspace.store {
+		if !e.isExpired(now) {
+			entries = append(entries, newSnapshotEntry(key, e))
+		}
+	}
+	db.keyspace.mutex.RUnlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- snapshot.finishSave(changes, snapshot.write(entries, position))
+	}()
+
+	return done, nil
+}
+
+// / Returns the time of the last successful snapshot. Without snapshots, this is the zero time
+func (db *DatabaseImpl) LastSnapshot() time.Time {
+	snapshot, ok := persistenceOf[*Snapshot](db)
+	if !ok {
+		return time.Time{}
+	}
+
+	return snapshot.LastSave()
+}
+
+func (db *DatabaseImpl) SnapshotNeedsSave() bool {
+	snapshot, ok := persistenceOf[*Snapshot](db)
+	return ok && snapshot.NeedsSave()
+}
+
+// / A copy of an entry that does not share any memory with the keyspace, so it can be written while the keyspace keeps changing.
+// / values holds the value of a string, the field value pairs of a hash or the members of the other types. scores belong to the members of a sorted set
+type snapshotEntry struct {
+	key       string
+	typ       entryType
+	expiresAt int64
+	values    []string
+	scores    []float64
+}
+
+// / Expects the caller to hold the keyspace lock
+func newSnapshotEntry(key string, e *entry) snapshotEntry {
+	snapshot := snapshotEntry{key: key, typ: e.typ}
+	if e.expiration != nil {
+		snapshot.expiresAt = e.expiration.ExpiresAt.UnixMilli()
+	}
+
+	switch e.typ {
+	case typeString:
+		snapshot.values = []string{e.value.(string)}
+	case typeHash:
+		for field, value := range e.value.(map[string]string) {
+			snapshot.values = append(snapshot.values, field, value)
+		}
+	case typeList:
+		list := e.value.(*listEntity)
+		snapshot.values = list.slice(0, list.len()-1)
+	case typeSet:
+		snapshot.values = e.value.(*setEntity).values()
+	case typeSortedSet:
+		for member, score := range e.value.(*sortedSetEntity).scores {
+			snapshot.values = append(snapshot.values, member)
+			snapshot.scores = append(snapshot.scores, score)
+		}
+	}
+
+	return snapshot
+}
+
+// / Returns the commands that recreate the entry including its expiration
+func (s snapshotEntry) commands() []resp.Value {
+	commands := []resp.Value{}
+	expiresAt := strconv.FormatInt(s.expiresAt, 10)
+
+	switch s.typ {
+	case typeString:
+		if s.expiresAt != 0 {
+			return append(commands, commandValue("SET", s.key, s.values[0], "PXAT", expiresAt))
+		}
+		return append(commands, commandValue("SET", s.key, s.values[0]))
+	case typeHash:
+		for i := 0; i < len(s.values); i += 2 {
+			commands = append(commands, commandValue("HSET", s.key, s.values[i], s.values[i+1]))
+		}
+	case typeList:
+		commands = appendBatched(commands, "RPUSH", s.key, s.values)
+	case typeSet:
+		commands = appendBatched(commands, "SADD", s.key, s.values)
+	case typeSortedSet:
+		args := make([]string, 0, 2*len(s.values))
+		for i, member := range s.values {
+			args = append(args, strconv.FormatFloat(s.scores[i], 'g', -1, 64), member)
+		}
+		// every member takes two arguments, its score and itself
+		for start := 0; start < len(args); start += 2 * rewriteItemsPerCommand {
+			end := min(start+2*rewriteItemsPerCommand, len(args))
+			commands = append(commands, commandValue("ZADD", s.key, args[start:end]...))
+		}
+	}
+
+	if s.expiresAt != 0 {
+		commands = append(commands, commandValue("PEXPIREAT", s.key, expiresAt))
+	}
+
+	return commands
+}
+
+// / Every entry type is stored as a single byte in the snapshot
+var snapshotTypes = []entryType{typeString, typeHash, typeList, typeSet, typeSortedSet}
+
+// / Layout: magic, version, aof offset, aof checksum, entries, a 0 byte, crc64 of everything before.
+// / An entry is: type (1 based index in snapshotTypes), key, expiration in unix milliseconds (0 = none), amount of values, values.
+// / Strings are prefixed with their length, numbers are uvarints, checksums and scores are 8 bytes big endian
+func encodeSnapshot(w io.Writer, entries []snapshotEntry, position AofPosition) error {
+	checksum := crc64.New(crcTable)
+	writer := bufio.NewWriter(io.MultiWriter(w, checksum))
+	buffer := make([]byte, binary.MaxVarintLen64)
+
+	writeUvarint := func(value uint64) {
+		n := binary.PutUvarint(buffer, value)
+		writer.Write(buffer[:n])
+	}
+	writeString := func(value string) {
+		writeUvarint(uint64(len(value)))
+		writer.WriteString(value)
+	}
+	writeUint64 := func(value uint64) {
+		binary.BigEndian.PutUint64(buffer, value)
+		writer.Write(buffer[:8])
+	}
+
+	writer.WriteString(snapshotMagic)
+	writer.WriteByte(snapshotVersion)
+	writeUvarint(uint64(position.Offset))
+	writeUint64(position.Checksum)
+
+	for _, entry := range entries {
+		writer.WriteByte(byte(snapshotTypeIndex(entry.typ)))
+		writeString(entry.key)
+		writeUvarint(uint64(entry.expiresAt))
+		writeUvarint(uint64(len(entry.values)))
+		for i, value := range entry.values {
+			writeString(value)
+			if entry.typ == typeSortedSet {
+				writeUint64(math.Float64bits(entry.scores[i]))
+			}
+		}
+	}
+	writer.WriteByte(0)
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint64(buffer, checksum.Sum64())
+	_, err := w.Write(buffer[:8])
+	return err
+}
+
+// / Verifies the trailing checksum before decoding anything, so a corrupt snapshot is never partially loaded.
+// / Both the verification and the decoding stream the input, only a single entry is kept in memory at a time
+func readSnapshot(input io.ReadSeeker, size int64, apply func(entry snapshotEntry, size int64) error) (AofPosition, error) {
+	if size < int64(len(snapshotMagic)+1+8) {
+		return AofPosition{}, io.ErrUnexpectedEOF
+	}
+
+	checksum := crc64.New(crcTable)
+	if _, err := io.CopyN(checksum, input, size-8); err != nil {
+		return AofPosition{}, err
+	}
+	trailer := make([]byte, 8)
+	if _, err := io.ReadFull(input, trailer); err != nil {
+		return AofPosition{}, err
+	}
+	if checksum.Sum64() != binary.BigEndian.Uint64(trailer) {
+		return AofPosition{}, errors.New("checksum mismatch")
+	}
+
+	if _, err := input.Seek(0, io.SeekStart); err != nil {
+		return AofPosition{}, err
+	}
+	return decodeSnapshot(input, size-8, apply)
+}
+
+// / Decodes the length bytes of the input without the trailing checksum and hands every entry with its encoded size to apply as soon as it is decoded
+func decodeSnapshot(input io.Reader, length int64, apply func(entry snapshotEntry, size int64) error) (AofPosition, error) {
+	limited := &io.LimitedReader{R: input, N: length}
+	reader := bufio.NewReader(limited)
+	remaining := func() uint64 {
+		return uint64(limited.N) + uint64(reader.Buffered())
+	}
+
+	readUint64 := func() (uint64, error) {
+		buffer := make([]byte, 8)
+		if _, err := io.ReadFull(reader, buffer); err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(buffer), nil
+	}
+	readString := func() (string, error) {
+		length, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return "", err
+		}
+		if length > remaining() {
+			return "", io.ErrUnexpectedEOF
+		}
+		buffer := make([]byte, length)
+		if _, err := io.ReadFull(reader, buffer); err != nil {
+			return "", err
+		}
+		return string(buffer), nil
+	}
+
+	position, err := readSnapshotHeader(reader)
+	if err != nil {
+		return position, err
+	}
+
+	for {
+		start := remaining()
+		typeIndex, err := reader.ReadByte()
+		if err != nil {
+			return position, err
+		}
+		if typeIndex == 0 {
+			break
+		}
+		if int(typeIndex) > len(snapshotTypes) {
+			return position, fmt.Errorf("unknown entry type %d", typeIndex)
+		}
+
+		entry := snapshotEntry{typ: snapshotTypes[typeIndex-1]}
+		if entry.key, err = readString(); err != nil {
+			return position, err
+		}
+		expiresAt, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return position, err
+		}
+		entry.expiresAt = int64(expiresAt)
+
+		amount, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return position, err
+		}
+		if amount > remaining() {
+			return position, io.ErrUnexpectedEOF
+		}
+		for range amount {
+			value, err := readString()
+			if err != nil {
+				return position, err
+			}
+			entry.values = append(entry.values, value)
+
+			if entry.typ == typeSortedSet {
+				bits, err := readUint64()
+				if err != nil {
+					return position, err
+				}
+				entry.scores = append(entry.scores, math.Float64frombits(bits))
+			}
+		}
+
+		if err := apply(entry, int64(start-remaining())); err != nil {
+			return position, err
+		}
+	}
+
+	if remaining() != 0 {
+		return position, errors.New("unexpected data after the end of the snapshot")
+	}
+
+	return position, nil
+}
+
+type snapshotReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func readSnapshotHeader(reader snapshotReader) (AofPosition, error) {
+	position := AofPosition{}
+
+	magic := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(reader, magic); err != nil {
+		return position, err
+	}
+	if string(magic[:len(snapshotMagic)]) != snapshotMagic || magic[len(snapshotMagic)] != snapshotVersion {
+		return position, errors.New("unknown snapshot format")
+	}
+
+	offset, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return position, err
+	}
+	position.Offset = int64(offset)
+
+	checksum := make([]byte, 8)
+	if _, err := io.ReadFull(reader, checksum); err != nil {
+		return position, err
+	}
+	position.Checksum = binary.BigEndian.Uint64(checksum)
+
+	return position, nil
+}
+
+func snapshotTypeIndex(typ entryType) int {
+	for i, t := range snapshotTypes {
+		if t == typ {
+			return i + 1
+		}
+	}
+	return 0
+}
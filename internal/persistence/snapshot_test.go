@@ -0,0 +1,170 @@
+package persistence
+
+import (
+	"bytes"
+	"gocache/internal/core/resp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_snapshot_encodeDecodeRoundTrip(t *testing.T) {
+	// given
+	entries := []snapshotEntry{
+		{key: "string", typ: typeString, values: []string{"misu"}},
+		{key: "hash", typ: typeHash, expiresAt: 1700000000000, values: []string{"misu", "cute"}},
+		{key: "list", typ: typeList, values: []string{"misu", "", "cute"}},
+		{key: "set", typ: typeSet, values: []string{"misu"}},
+		{key: "zset", typ: typeSortedSet, values: []string{"misu", "void"}, scores: []float64{1.5, -3}},
+	}
+	position := AofPosition{Offset: 42, Checksum: 1234}
+	buffer := bytes.Buffer{}
+
+	// when
+	err := encodeSnapshot(&buffer, entries, position)
+	decoded := []snapshotEntry{}
+	decodedPosition, decodeErr := readSnapshot(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), func(entry snapshotEntry, _ int64) error {
+		decoded = append(decoded, entry)
+		return nil
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, decodeErr)
+	assert.Equal(t, position, decodedPosition)
+	assert.Equal(t, entries, decoded)
+}
+
+func Test_snapshot_decodeDetectsCorruption(t *testing.T) {
+	// given
+	buffer := bytes.Buffer{}
+	encodeSnapshot(&buffer, []snapshotEntry{{key: "tira", typ: typeString, values: []string{"misu"}}}, AofPosition{})
+	data := buffer.Bytes()
+	data[len(data)-10] ^= 0xff
+
+	// when
+	applied := 0
+	_, err := readSnapshot(bytes.NewReader(data), int64(len(data)), func(snapshotEntry, int64) error {
+		applied++
+		return nil
+	})
+
+	// then
+	assert.Error(t, err)
+	assert.Zero(t, applied)
+}
+
+func Test_snapshot_saveAndLoad(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "database.snapshot")
+	snapshot := NewSnapshot(path, nil)
+	db := NewDatabase(snapshot)
+	expiresAt := time.Now().Add(time.Hour)
+	db.SaveString(resp.Value{}, "tira", NewStringExpiringAt("misu", expiresAt))
+	db.AddToSortedSet(resp.Value{}, "cute", []ScoredMember{{Member: "misu", Score: 2}}, SortedSetAddOptions{})
+
+	// when
+	done, err := db.SaveSnapshot()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	saveErr := <-done
+	_, ok, positionErr := snapshot.Position()
+	commands, loadErr := readAll(snapshot)
+
+	// then
+	assert.NoError(t, saveErr)
+	assert.NoError(t, positionErr)
+	assert.NoError(t, loadErr)
+	assert.True(t, ok)
+	assert.ElementsMatch(t, []resp.Value{
+		commandValue("SET", "tira", "misu", "PXAT", unixMilli(expiresAt)),
+		commandValue("ZADD", "cute", "2", "misu"),
+	}, commands)
+
+	// the temporary file was renamed
+	files, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, files, 1)
+}
+
+func Test_snapshot_loadWithoutFile(t *testing.T) {
+	// given
+	snapshot := NewSnapshot(filepath.Join(t.TempDir(), "database.snapshot"), nil)
+
+	// when
+	_, ok, err := snapshot.Position()
+	commands, replayErr := readAll(snapshot)
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, replayErr)
+	assert.False(t, ok)
+	assert.Empty(t, commands)
+}
+
+func Test_snapshot_saveResetsChanges(t *testing.T) {
+	// given
+	snapshot := NewSnapshot(filepath.Join(t.TempDir(), "database.snapshot"), []SaveRule{{Seconds: 1, Changes: 1}})
+	snapshot.lastSave = time.Now().Add(-time.Minute)
+	db := NewDatabase(snapshot)
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+	needsSave := db.SnapshotNeedsSave()
+
+	// when
+	done, _ := db.SaveSnapshot()
+	<-done
+
+	// then
+	assert.True(t, needsSave)
+	assert.False(t, db.SnapshotNeedsSave())
+	assert.WithinDuration(t, time.Now(), db.LastSnapshot(), time.Second)
+}
+
+func Test_snapshot_needsSave(t *testing.T) {
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		changes  int
+		expected bool
+	}{
+		{name: "no changes", elapsed: time.Hour, changes: 0, expected: false},
+		{name: "too early", elapsed: time.Second, changes: 100, expected: false},
+		{name: "too few changes", elapsed: 2 * time.Minute, changes: 5, expected: false},
+		{name: "rule fulfilled", elapsed: 2 * time.Minute, changes: 10, expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			snapshot := NewSnapshot("", []SaveRule{{Seconds: 60, Changes: 10}})
+			snapshot.lastSave = time.Now().Add(-test.elapsed)
+			snapshot.changes = test.changes
+
+			// when
+			result := snapshot.NeedsSave()
+
+			// then
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func Test_parseSaveRules(t *testing.T) {
+	// when
+	rules, err := ParseSaveRules("3600 1 300 100")
+	empty, emptyErr := ParseSaveRules("")
+	_, oddErr := ParseSaveRules("3600")
+	_, invalidErr := ParseSaveRules("3600 misu")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []SaveRule{{Seconds: 3600, Changes: 1}, {Seconds: 300, Changes: 100}}, rules)
+	assert.NoError(t, emptyErr)
+	assert.Empty(t, empty)
+	assert.Error(t, oddErr)
+	assert.Error(t, invalidErr)
+}
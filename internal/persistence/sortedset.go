@@ -0,0 +1,481 @@
+package persistence
+
+import (
+	"errors"
+	"gocache/internal/core/resp"
+	"math"
+)
+
+// / The map allows O(1) score lookups by member, the skiplist keeps the members ordered by score
+type sortedSetEntity struct {
+	scores map[string]float64
+	index  *skiplist
+}
+
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// / Options of ZADD. OnlyNew (NX), OnlyExisting (XX), OnlyGreater (GT), OnlyLess (LT) and CountChanged (CH)
+type SortedSetAddOptions struct {
+	OnlyNew      bool
+	OnlyExisting bool
+	OnlyGreater  bool
+	OnlyLess     bool
+	CountChanged bool
+}
+
+type ScoreBound struct {
+	Value     float64
+	Exclusive bool
+}
+
+// / Infinity is -1 for the lowest possible member ("-"), 1 for the highest possible member ("+") and 0 otherwise
+type LexBound struct {
+	Value     string
+	Exclusive bool
+	Infinity  int
+}
+
+func newSortedSetEntity() *sortedSetEntity {
+	return &sortedSetEntity{
+		scores: map[string]float64{},
+		index:  newSkiplist(),
+	}
+}
+
+func (s *sortedSetEntity) len() int {
+	return len(s.scores)
+}
+
+func (s *sortedSetEntity) set(member string, score float64) {
+	if oldScore, ok := s.scores[member]; ok {
+		s.index.delete(oldScore, member)
+	}
+	s.scores[member] = score
+	s.index.insert(score, member)
+}
+
+func (s *sortedSetEntity) remove(member string) bool {
+	score, ok := s.scores[member]
+	if !ok {
+		return false
+	}
+
+	delete(s.scores, member)
+	s.index.delete(score, member)
+	return true
+}
+
+// / Returns whether the options allowed the update and whether the score changed
+func (s *sortedSetEntity) update(member string, score float64, options SortedSetAddOptions) (bool, bool) {
+	oldScore, exists := s.scores[member]
+
+	if exists && options.OnlyNew || !exists && options.OnlyExisting {
+		return false, false
+	}
+	if exists && options.OnlyGreater && score <= oldScore {
+		return false, false
+	}
+	if exists && options.OnlyLess && score >= oldScore {
+		return false, false
+	}
+
+	changed := !exists || oldScore != score
+	if changed {
+		s.set(member, score)
+	}
+
+	return true, changed
+}
+
+func (b ScoreBound) aboveMin(node *skiplistNode) bool {
+	if b.Exclusive {
+		return node.score > b.Value
+	}
+	return node.score >= b.Value
+}
+
+func (b ScoreBound) belowMax(node *skiplistNode) bool {
+	if b.Exclusive {
+		return node.score < b.Value
+	}
+	return node.score <= b.Value
+}
+
+func (b LexBound) aboveMin(node *skiplistNode) bool {
+	switch {
+	case b.Infinity < 0:
+		return true
+	case b.Infinity > 0:
+		return false
+	case b.Exclusive:
+		return node.member > b.Value
+	default:
+		return node.member >= b.Value
+	}
+}
+
+func (b LexBound) belowMax(node *skiplistNode) bool {
+	switch {
+	case b.Infinity < 0:
+		return false
+	case b.Infinity > 0:
+		return true
+	case b.Exclusive:
+		return node.member < b.Value
+	default:
+		return node.member <= b.Value
+	}
+}
+
+// / Adds or updates the members. Returns the amount of added members, or with CountChanged the amount of added and updated members
+func (db *DatabaseImpl) AddToSortedSet(requestValue resp.Value, key string, members []ScoredMember, options SortedSetAddOptions) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeSortedSet)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+
+	if e == nil {
+		if options.OnlyExisting {
+			return 0, nil
+		}
+		e = &entry{typ: typeSortedSet, value: newSortedSetEntity()}
+		db.keyspace.set(key, e)
+	}
+	sortedSet := e.value.(*sortedSetEntity)
+
+	amount := 0
+	modified := false
+	for _, m := range members {
+		_, existed := sortedSet.scores[m.Member]
+		applied, changed := sortedSet.update(m.Member, m.Score, options)
+
+		if applied && !existed || applied && changed && options.CountChanged {
+			amount++
+		}
+		modified = modified || changed
+	}
+	if modified {
+		db.keyspace.touch(key)
+		db.keyspace.notify(SortedSetEvents, "zadd", key)
+	}
+
+	return amount, nil
+}
+
+// / Increments the score of member by increment. Returns false if the options prevented the update
+func (db *DatabaseImpl) IncrementSortedSetScore(requestValue resp.Value, key string, member string, increment float64, options SortedSetAddOptions) (float64, bool, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeSortedSet)
+	if err != nil {
+		return 0, false, err
+	}
+
+	score := increment
+	if e != nil {
+		score += e.value.(*sortedSetEntity).scores[member]
+	}
+	if math.IsNaN(score) {
+		return 0, false, errors.New("ERR resulting score is not a number (NaN)")
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, false, err
+	}
+
+	if e == nil {
+		if options.OnlyExisting {
+			return 0, false, nil
+		}
+		e = &entry{typ: typeSortedSet, value: newSortedSetEntity()}
+		db.keyspace.set(key, e)
+	}
+	sortedSet := e.value.(*sortedSetEntity)
+
+	applied, _ := sortedSet.update(member, score, options)
+	if sortedSet.len() == 0 {
+		db.keyspace.delete(key)
+	} else if applied {
+		db.keyspace.touch(key)
+		db.keyspace.notify(SortedSetEvents, "zincr", key)
+	}
+
+	return score, applied, nil
+}
+
+func (db *DatabaseImpl) RemoveFromSortedSet(requestValue resp.Value, key string, members []string) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	e, err := db.lookup(key, typeSortedSet)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return 0, err
+	}
+
+	if e == nil {
+		return 0, nil
+	}
+	sortedSet := e.value.(*sortedSetEntity)
+
+	amountRemoved := 0
+	for _, member := range members {
+		if sortedSet.remove(member) {
+			amountRemoved++
+		}
+	}
+
+	if amountRemoved > 0 {
+		db.keyspace.notify(SortedSetEvents, "zrem", key)
+	}
+	if sortedSet.len() == 0 {
+		db.keyspace.delete(key)
+		db.keyspace.notify(GenericEvents, "del", key)
+	} else if amountRemoved > 0 {
+		db.keyspace.touch(key)
+	}
+
+	return amountRemoved, nil
+}
+
+// / Removes and returns up to count members with the lowest scores, or the highest scores if highest is true
+func (db *DatabaseImpl) PopSortedSet(requestValue resp.Value, key string, count int, highest bool) ([]ScoredMember, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	sortedSet, err := db.lookupSortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.persist(requestValue); err != nil {
+		return nil, err
+	}
+
+	popped := make([]ScoredMember, 0, min(count, sortedSet.len()))
+	for range min(count, sortedSet.len()) {
+		node := sortedSet.index.byRank(0)
+		if highest {
+			node = sortedSet.index.tail
+		}
+
+		popped = append(popped, ScoredMember{Member: node.member, Score: node.score})
+		sortedSet.remove(node.member)
+	}
+
+	if len(popped) > 0 && highest {
+		db.keyspace.notify(SortedSetEvents, "zpopmax", key)
+	} else if len(popped) > 0 {
+		db.keyspace.notify(SortedSetEvents, "zpopmin", key)
+	}
+	if sortedSet.len() == 0 {
+		db.keyspace.delete(key)
+		db.keyspace.notify(GenericEvents, "del", key)
+	} else if len(popped) > 0 {
+		db.keyspace.touch(key)
+	}
+
+	return popped, nil
+}
+
+func (db *DatabaseImpl) GetSortedSetScore(key string, member string) (float64, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	sortedSet, err := db.lookupSortedSet(key)
+	if err != nil {
+		return 0, err
+	}
+
+	score, ok := sortedSet.scores[member]
+	if !ok {
+		return 0, errors.New("Did not find member " + member + " in " + key)
+	}
+
+	return score, nil
+}
+
+// / Returns the 0 based rank of member. With reverse the rank is calculated from the highest score
+func (db *DatabaseImpl) GetSortedSetRank(key string, member string, reverse bool) (int, float64, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	sortedSet, err := db.lookupSortedSet(key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	score, ok := sortedSet.scores[member]
+	if !ok {
+		return 0, 0, errors.New("Did not find member " + member + " in " + key)
+	}
+
+	rank := sortedSet.index.rank(score, member)
+	if reverse {
+		rank = sortedSet.len() - 1 - rank
+	}
+
+	return rank, score, nil
+}
+
+func (db *DatabaseImpl) GetSortedSetLength(key string) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, err := db.lookup(key, typeSortedSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+
+	return e.value.(*sortedSetEntity).len(), nil
+}
+
+func (db *DatabaseImpl) CountSortedSetByScore(key string, min ScoreBound, max ScoreBound) (int, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	e, err := db.lookup(key, typeSortedSet)
+	if err != nil || e == nil {
+		return 0, err
+	}
+	sortedSet := e.value.(*sortedSetEntity)
+
+	first := sortedSet.index.firstInRange(min.aboveMin, max.belowMax)
+	if first == nil {
+		return 0, nil
+	}
+	last := sortedSet.index.lastInRange(min.aboveMin, max.belowMax)
+
+	return sortedSet.index.rank(last.score, last.member) - sortedSet.index.rank(first.score, first.member) + 1, nil
+}
+
+// / Returns the members between the 0 based ranks start and stop (both inclusive). Negative ranks count from the end
+func (db *DatabaseImpl) GetSortedSetRange(key string, start int, stop int, reverse bool) ([]ScoredMember, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	sortedSet, err := db.lookupSortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	start, stop, ok := normalizeRange(start, stop, sortedSet.len())
+	if !ok {
+		return []ScoredMember{}, nil
+	}
+
+	result := make([]ScoredMember, 0, stop-start+1)
+	if reverse {
+		node := sortedSet.index.byRank(sortedSet.len() - 1 - start)
+		for range stop - start + 1 {
+			result = append(result, ScoredMember{Member: node.member, Score: node.score})
+			node = node.backward
+		}
+	} else {
+		node := sortedSet.index.byRank(start)
+		for range stop - start + 1 {
+			result = append(result, ScoredMember{Member: node.member, Score: node.score})
+			node = node.levels[0].forward
+		}
+	}
+
+	return result, nil
+}
+
+// / Returns the members with a score between min and max. Skips offset members and returns at most count members. A negative count returns all
+func (db *DatabaseImpl) GetSortedSetRangeByScore(key string, min ScoreBound, max ScoreBound, reverse bool, offset int, count int) ([]ScoredMember, error) {
+	return db.sortedSetRangeBy(key, min.aboveMin, max.belowMax, reverse, offset, count)
+}
+
+// / Returns the members between min and max ordered lexicographically. Expects all members to have the same score
+func (db *DatabaseImpl) GetSortedSetRangeByLex(key string, min LexBound, max LexBound, reverse bool, offset int, count int) ([]ScoredMember, error) {
+	return db.sortedSetRangeBy(key, min.aboveMin, max.belowMax, reverse, offset, count)
+}
+
+func (db *DatabaseImpl) sortedSetRangeBy(key string, aboveMin func(*skiplistNode) bool, belowMax func(*skiplistNode) bool, reverse bool, offset int, count int) ([]ScoredMember, error) {
+	db.deleteIfExpired(key)
+
+	db.keyspace.mutex.RLock()
+	defer db.keyspace.mutex.RUnlock()
+
+	sortedSet, err := db.lookupSortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var node *skiplistNode
+	if reverse {
+		node = sortedSet.index.lastInRange(aboveMin, belowMax)
+	} else {
+		node = sortedSet.index.firstInRange(aboveMin, belowMax)
+	}
+
+	for ; node != nil && offset > 0; offset-- {
+		node = nextNode(node, reverse)
+	}
+
+	result := []ScoredMember{}
+	for node != nil && count != 0 {
+		if reverse && !aboveMin(node) || !reverse && !belowMax(node) {
+			break
+		}
+
+		result = append(result, ScoredMember{Member: node.member, Score: node.score})
+		node = nextNode(node, reverse)
+		count--
+	}
+
+	return result, nil
+}
+
+func nextNode(node *skiplistNode, reverse bool) *skiplistNode {
+	if reverse {
+		return node.backward
+	}
+	return node.levels[0].forward
+}
+
+// / Returns the sorted set at key or an error if there is none. Expects the caller to hold the keyspace lock
+func (db *DatabaseImpl) lookupSortedSet(key string) (*sortedSetEntity, error) {
+	e, err := db.lookup(key, typeSortedSet)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, errors.New("Did not find any value with key " + key)
+	}
+
+	return e.value.(*sortedSetEntity), nil
+}
@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"strings"
+	"sync/atomic"
+)
+
+// / Like WATCH in redis: a watch becomes dirty as soon as one of its keys is modified, so a transaction can be aborted if its keys changed in the meantime
+type Watch struct {
+	keys  []string
+	dirty atomic.Bool
+}
+
+func NewWatch() *Watch {
+	return &Watch{}
+}
+
+func (w *Watch) Dirty() bool {
+	return w.dirty.Load()
+}
+
+func (db *DatabaseImpl) WatchKeys(watch *Watch, keys []string) {
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	for _, key := range keys {
+		watches, ok := db.keyspace.watches[key]
+		if !ok {
+			watches = map[*Watch]struct{}{}
+			db.keyspace.watches[key] = watches
+		}
+		if _, ok := watches[watch]; ok {
+			continue
+		}
+
+		watches[watch] = struct{}{}
+		watch.keys = append(watch.keys, key)
+	}
+}
+
+// / Stops watching every key of the watch and resets it, so it can be reused
+func (db *DatabaseImpl) UnwatchKeys(watch *Watch) {
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	for _, key := range watch.keys {
+		delete(db.keyspace.watches[key], watch)
+		if len(db.keyspace.watches[key]) == 0 {
+			delete(db.keyspace.watches, key)
+		}
+	}
+
+	watch.keys = nil
+	watch.dirty.Store(false)
+}
+
+// / Runs run and saves every command it persists as one MULTI/EXEC block afterwards, so a transaction is replayed completely or not at all.
+// / The caller has to make sure no other client runs commands in the meantime
+func (db *DatabaseImpl) PersistAsBlock(run func()) error {
+	db.keyspace.mutex.Lock()
+	db.block = &[]resp.Value{}
+	db.keyspace.mutex.Unlock()
+
+	run()
+
+	db.keyspace.mutex.Lock()
+	defer db.keyspace.mutex.Unlock()
+
+	block := *db.block
+	db.block = nil
+	if len(block) == 0 {
+		return nil
+	}
+
+	commands := append([]resp.Value{blockMarker("MULTI")}, block...)
+	commands = append(commands, blockMarker("EXEC"))
+	for _, command := range commands {
+		if err := db.persist(command); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func blockMarker(name string) resp.Value {
+	return resp.Value{Typ: resp.ARRAY.Typ, Array: []resp.Value{{Typ: resp.BULK.Typ, Bulk: []byte(name)}}}
+}
+
+func isBlockMarker(value resp.Value, name string) bool {
+	return len(value.Array) == 1 && strings.EqualFold(string(value.Array[0].Bulk), name)
+}
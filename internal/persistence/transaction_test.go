@@ -0,0 +1,106 @@
+package persistence
+
+import (
+	"gocache/internal/core/resp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_watch_becomesDirtyOnModification(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(db *DatabaseImpl)
+	}{
+		{name: "set", modify: func(db *DatabaseImpl) { db.SaveString(resp.Value{}, "tira", NewString("cute", 0)) }},
+		{name: "delete", modify: func(db *DatabaseImpl) { db.DeleteKeys(resp.Value{}, []string{"tira"}) }},
+		{name: "push", modify: func(db *DatabaseImpl) { db.PushList(resp.Value{}, "list", []string{"void"}, true) }},
+		{name: "hash field", modify: func(db *DatabaseImpl) { db.SaveHash(resp.Value{}, "hash", "misu", "void") }},
+		{name: "set member", modify: func(db *DatabaseImpl) { db.AddToSet(resp.Value{}, "set", []string{"void"}) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			db := NewDatabase()
+			db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+			db.PushList(resp.Value{}, "list", []string{"misu"}, true)
+			db.SaveHash(resp.Value{}, "hash", "misu", "cute")
+			db.AddToSet(resp.Value{}, "set", []string{"misu"})
+			watch := NewWatch()
+			db.WatchKeys(watch, []string{"tira", "list", "hash", "set"})
+
+			// when
+			test.modify(db)
+
+			// then
+			assert.True(t, watch.Dirty())
+		})
+	}
+}
+
+func Test_watch_staysCleanWithoutModification(t *testing.T) {
+	// given
+	db := NewDatabase()
+	db.AddToSet(resp.Value{}, "set", []string{"misu"})
+	watch := NewWatch()
+	db.WatchKeys(watch, []string{"set"})
+
+	// when
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+	db.AddToSet(resp.Value{}, "set", []string{"misu"})
+	db.GetSetMembers("set")
+
+	// then
+	assert.False(t, watch.Dirty())
+}
+
+func Test_unwatch_resetsWatch(t *testing.T) {
+	// given
+	db := NewDatabase()
+	watch := NewWatch()
+	db.WatchKeys(watch, []string{"tira"})
+	db.SaveString(resp.Value{}, "tira", NewString("misu", 0))
+
+	// when
+	db.UnwatchKeys(watch)
+	db.SaveString(resp.Value{}, "tira", NewString("cute", 0))
+
+	// then
+	assert.False(t, watch.Dirty())
+	assert.Empty(t, db.keyspace.watches)
+}
+
+func Test_persistAsBlock_savesBlock(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+	first := commandValue("SET", "tira", "misu")
+	second := commandValue("DEL", "tira")
+
+	// when
+	err := db.PersistAsBlock(func() {
+		db.SaveString(first, "tira", NewString("misu", 0))
+		assert.Empty(t, disk.saved)
+		db.DeleteKeys(second, []string{"tira"})
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []resp.Value{blockMarker("MULTI"), first, second, blockMarker("EXEC")}, disk.saved)
+}
+
+func Test_persistAsBlock_withoutChanges_savesNothing(t *testing.T) {
+	// given
+	disk := &recordingDisk{}
+	db := NewDatabase(disk)
+
+	// when
+	err := db.PersistAsBlock(func() {
+		db.GetString("tira")
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Empty(t, disk.saved)
+}